@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"golang.org/x/oauth2"
 )
@@ -44,6 +45,12 @@ type Client struct {
 	// (via the client ID and client secret's Basic Auth).
 	// Use WithAccessToken() to get such a client.
 	AccessToken string
+	// tokenSource provides the user's access token instead of AccessToken when it's not nil.
+	// Use WithTokenSource() to get such a client.
+	tokenSource oauth2.TokenSource
+	// dpopSigner adds DPoP proofs to the token requests and to the API requests made with
+	// the user's access token when it's not nil. Use WithDPoP() to get such a client.
+	dpopSigner *DPoPSigner
 }
 
 // HttpClient interface has the method required to use a type as custom http client.
@@ -112,6 +119,7 @@ func (c *Client) WithAccessToken(accessToken string) *Client {
 		AuthConfig:    c.AuthConfig,
 		CustomHeaders: customHeaders,
 		AccessToken:   accessToken,
+		dpopSigner:    c.dpopSigner,
 	}
 }
 
@@ -125,7 +133,13 @@ type OAuthOption func(*oauthOptions)
 
 // oauthOptions holds configuration options for OAuth operations.
 type oauthOptions struct {
-	httpClient *http.Client
+	httpClient   *http.Client
+	pollInterval time.Duration
+	resources    []string
+	scopes       []string
+	state        string
+	nonce        string
+	codeVerifier string
 }
 
 // WithHTTPClient sets a custom http client for oauth operations.
@@ -135,6 +149,42 @@ func WithHTTPClient(httpClient *http.Client) OAuthOption {
 	}
 }
 
+// WithScopes sets the scopes requested by the sign-in URL of GetSigninUrlWithOptions(), the
+// default is "read". The password, client credentials and device grants request them too, the
+// other token requests return an error, as their scopes are the ones granted before.
+func WithScopes(scopes ...string) OAuthOption {
+	return func(opts *oauthOptions) {
+		opts.scopes = append(opts.scopes, scopes...)
+	}
+}
+
+// WithState sets the "state" of the sign-in URL of GetSigninUrlWithOptions(), the default is
+// the application name. It should be a random value checked by the callback against CSRF.
+// The token requests return an error with it.
+func WithState(state string) OAuthOption {
+	return func(opts *oauthOptions) {
+		opts.state = state
+	}
+}
+
+// WithNonce sets the "nonce" of the sign-in URL of GetSigninUrlWithOptions(), which is then
+// found in the ID token, see VerifyIDToken(). The token requests return an error with it.
+func WithNonce(nonce string) OAuthOption {
+	return func(opts *oauthOptions) {
+		opts.nonce = nonce
+	}
+}
+
+// WithPKCE uses the PKCE code verifier of RFC 7636, like the one of oauth2.GenerateVerifier():
+// the sign-in URL of GetSigninUrlWithOptions() carries its S256 challenge, and
+// GetOAuthToken() sends it along with the code, so that a stolen code can't be used. The
+// other token requests return an error with it.
+func WithPKCE(codeVerifier string) OAuthOption {
+	return func(opts *oauthOptions) {
+		opts.codeVerifier = codeVerifier
+	}
+}
+
 // getOAuthConfig returns the OAuth config, tokenAction is the action of the token API,
 // like "access_token" or "refresh_token"
 func (c *Client) getOAuthConfig(tokenAction string) oauth2.Config {
@@ -151,18 +201,91 @@ func (c *Client) getOAuthConfig(tokenAction string) oauth2.Config {
 	}
 }
 
-func getOAuthContext(opts ...OAuthOption) context.Context {
+func getOAuthOptions(opts ...OAuthOption) *oauthOptions {
 	options := &oauthOptions{}
 	for _, opt := range opts {
 		opt(options)
 	}
 
-	ctx := context.Background()
-	if options.httpClient != nil {
-		ctx = context.WithValue(ctx, oauth2.HTTPClient, options.httpClient)
+	return options
+}
+
+// checkOAuthOptions returns an error if an option of the sign-in URL that the token request of
+// funcName doesn't send is set, instead of silently ignoring it. The supported options are
+// named like "WithScopes()".
+func checkOAuthOptions(funcName string, options *oauthOptions, supported ...string) error {
+	for _, option := range []struct {
+		name string
+		set  bool
+	}{
+		{"WithScopes()", len(options.scopes) > 0},
+		{"WithState()", options.state != ""},
+		{"WithNonce()", options.nonce != ""},
+		{"WithPKCE()", options.codeVerifier != ""},
+	} {
+		if option.set && !containsString(supported, option.name) {
+			return fmt.Errorf("%s() error: %s is not supported by this request", funcName, option.name)
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) getOAuthContext(funcName string, supported []string, opts ...OAuthOption) (context.Context, *oauthOptions, error) {
+	options := getOAuthOptions(opts...)
+	err := checkOAuthOptions(funcName, options, supported...)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return ctx
+	ctx, err := c.withOAuthContext(context.Background(), options)
+	return ctx, options, err
+}
+
+// withOAuthContext returns a copy of ctx carrying the http client of the options, if any,
+// wrapped to add the resource indicators of the options and DPoP proofs when the client
+// has a DPoP signer
+func (c *Client) withOAuthContext(ctx context.Context, options *oauthOptions) (context.Context, error) {
+	err := checkResourceIndicators(options.resources)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := options.httpClient
+	if httpClient == nil && (len(options.resources) > 0 || c.dpopSigner != nil) {
+		httpClient, _ = ctx.Value(oauth2.HTTPClient).(*http.Client)
+	}
+	if len(options.resources) > 0 {
+		httpClient = wrapHttpClient(httpClient, func(base http.RoundTripper) http.RoundTripper {
+			return &resourceTransport{resources: options.resources, base: base}
+		})
+	}
+	if c.dpopSigner != nil {
+		httpClient = wrapHttpClient(httpClient, func(base http.RoundTripper) http.RoundTripper {
+			return &dpopTransport{signer: c.dpopSigner, base: base}
+		})
+	}
+
+	if httpClient != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+	}
+
+	return ctx, nil
+}
+
+// wrapHttpClient returns a copy of the httpClient whose transport is wrapped by wrap.
+func wrapHttpClient(httpClient *http.Client, wrap func(base http.RoundTripper) http.RoundTripper) *http.Client {
+	wrappedClient := &http.Client{}
+	if httpClient != nil {
+		*wrappedClient = *httpClient
+	}
+
+	base := wrappedClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	wrappedClient.Transport = wrap(base)
+	return wrappedClient
 }
 
 // checkOAuthToken converts the "error: xxx" access token returned by the Casdoor server into a real error
@@ -181,17 +304,38 @@ func checkOAuthToken(token *oauth2.Token, err error) (*oauth2.Token, error) {
 // GetOAuthToken gets the pivotal and necessary secret to interact with the Casdoor server
 func (c *Client) GetOAuthToken(code string, state string, opts ...OAuthOption) (*oauth2.Token, error) {
 	config := c.getOAuthConfig("access_token")
+	ctx, options, err := c.getOAuthContext("GetOAuthToken", []string{"WithPKCE()"}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var authCodeOpts []oauth2.AuthCodeOption
+	if options.codeVerifier != "" {
+		authCodeOpts = append(authCodeOpts, oauth2.VerifierOption(options.codeVerifier))
+	}
+
+	token, err := checkOAuthToken(config.Exchange(ctx, code, authCodeOpts...))
+	if err != nil {
+		return token, err
+	}
 
-	token, err := config.Exchange(getOAuthContext(opts...), code)
-	return checkOAuthToken(token, err)
+	return checkTokenResources(token, options.resources)
 }
 
 // RefreshOAuthToken refreshes the OAuth token
 func (c *Client) RefreshOAuthToken(refreshToken string, opts ...OAuthOption) (*oauth2.Token, error) {
 	config := c.getOAuthConfig("refresh_token")
+	ctx, options, err := c.getOAuthContext("RefreshOAuthToken", nil, opts...)
+	if err != nil {
+		return nil, err
+	}
 
-	token, err := config.TokenSource(getOAuthContext(opts...), &oauth2.Token{RefreshToken: refreshToken}).Token()
-	return checkOAuthToken(token, err)
+	token, err := checkOAuthToken(config.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token())
+	if err != nil {
+		return token, err
+	}
+
+	return checkTokenResources(token, options.resources)
 }
 
 // GetOAuthTokenByPassword gets the OAuth token via the "password" grant type, i.e., the
@@ -201,9 +345,18 @@ func (c *Client) RefreshOAuthToken(refreshToken string, opts ...OAuthOption) (*o
 // instead of "my-org/alice".
 func (c *Client) GetOAuthTokenByPassword(username string, password string, opts ...OAuthOption) (*oauth2.Token, error) {
 	config := c.getOAuthConfig("access_token")
+	ctx, options, err := c.getOAuthContext("GetOAuthTokenByPassword", []string{"WithScopes()"}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	config.Scopes = options.scopes
+	token, err := checkOAuthToken(config.PasswordCredentialsToken(ctx, username, password))
+	if err != nil {
+		return token, err
+	}
 
-	token, err := config.PasswordCredentialsToken(getOAuthContext(opts...), username, password)
-	return checkOAuthToken(token, err)
+	return checkTokenResources(token, options.resources)
 }
 
 // ImpersonateUser gets an OAuth token which acts as the given user, so that an admin can
@@ -35,3 +35,11 @@ func ImpersonateUser(username string, masterPassword string, opts ...OAuthOption
 func WithAccessToken(accessToken string) *Client {
 	return globalClient.WithAccessToken(accessToken)
 }
+
+func WithTokenSource(tokenSource oauth2.TokenSource) *Client {
+	return globalClient.WithTokenSource(tokenSource)
+}
+
+func NewRefreshingTokenSource(token *oauth2.Token, opts ...TokenSourceOption) *RefreshingTokenSource {
+	return globalClient.NewRefreshingTokenSource(token, opts...)
+}
@@ -0,0 +1,419 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+)
+
+type testTicket struct {
+	service string
+	proxies []string
+}
+
+// testCasdoor is a CAS server answering like Casdoor.
+type testCasdoor struct {
+	*httptest.Server
+	mu      sync.Mutex
+	tickets map[string]testTicket
+	pgts    map[string]string
+	count   int
+}
+
+func newTestCasdoor(t *testing.T) *testCasdoor {
+	casdoor := &testCasdoor{tickets: map[string]testTicket{}, pgts: map[string]string{}}
+	mux := http.NewServeMux()
+	prefix := "/cas/built-in/app-built-in"
+	mux.HandleFunc(prefix+"/login", func(w http.ResponseWriter, r *http.Request) {
+		service := r.URL.Query().Get("service")
+		ticket := casdoor.newTicket("ST", testTicket{service: service})
+		separator := "?"
+		if strings.Contains(service, "?") {
+			separator = "&"
+		}
+		http.Redirect(w, r, service+separator+"ticket="+ticket, http.StatusFound)
+	})
+	mux.HandleFunc(prefix+"/logout", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("logged out"))
+	})
+	for _, action := range []string{"serviceValidate", "proxyValidate", "p3/serviceValidate", "p3/proxyValidate"} {
+		action := action
+		mux.HandleFunc(prefix+"/"+action, func(w http.ResponseWriter, r *http.Request) {
+			casdoor.validate(t, w, r, strings.HasPrefix(action, "p3/"), strings.HasSuffix(action, "proxyValidate"))
+		})
+	}
+	mux.HandleFunc(prefix+"/proxy", func(w http.ResponseWriter, r *http.Request) {
+		casdoor.mu.Lock()
+		pgtUrl, ok := casdoor.pgts[r.URL.Query().Get("pgt")]
+		casdoor.mu.Unlock()
+		if !ok {
+			_, _ = fmt.Fprint(w, `<cas:serviceResponse xmlns:cas="http://www.yale.edu/tp/cas"><cas:proxyFailure code="INVALID_TICKET"><![CDATA[PGT not found]]></cas:proxyFailure></cas:serviceResponse>`)
+			return
+		}
+
+		ticket := casdoor.newTicket("PT", testTicket{service: r.URL.Query().Get("targetService"), proxies: []string{pgtUrl}})
+		_, _ = fmt.Fprintf(w, `<cas:serviceResponse xmlns:cas="http://www.yale.edu/tp/cas"><cas:proxySuccess><cas:proxyTicket>%s</cas:proxyTicket></cas:proxySuccess></cas:serviceResponse>`, ticket)
+	})
+
+	casdoor.Server = httptest.NewServer(mux)
+	return casdoor
+}
+
+func (casdoor *testCasdoor) newTicket(prefix string, ticket testTicket) string {
+	casdoor.mu.Lock()
+	defer casdoor.mu.Unlock()
+
+	casdoor.count++
+	id := fmt.Sprintf("%s-%d", prefix, casdoor.count)
+	casdoor.tickets[id] = ticket
+	return id
+}
+
+func (casdoor *testCasdoor) validate(t *testing.T, w http.ResponseWriter, r *http.Request, isV3 bool, isProxyValidate bool) {
+	query := r.URL.Query()
+	isJson := query.Get("format") == "JSON"
+
+	casdoor.mu.Lock()
+	ticket, ok := casdoor.tickets[query.Get("ticket")]
+	delete(casdoor.tickets, query.Get("ticket"))
+	casdoor.mu.Unlock()
+	if !ok || ticket.service != query.Get("service") || (!isProxyValidate && strings.HasPrefix(query.Get("ticket"), "PT-")) {
+		if isJson {
+			_, _ = fmt.Fprint(w, `{"serviceResponse":{"authenticationFailure":{"code":"INVALID_TICKET","description":"Ticket not recognized"}}}`)
+		} else {
+			_, _ = fmt.Fprint(w, `<cas:serviceResponse xmlns:cas="http://www.yale.edu/tp/cas"><cas:authenticationFailure code="INVALID_TICKET"><![CDATA[Ticket not recognized]]></cas:authenticationFailure></cas:serviceResponse>`)
+		}
+		return
+	}
+
+	pgtIou := ""
+	if pgtUrl := query.Get("pgtUrl"); pgtUrl != "" {
+		pgt := casdoor.newTicket("PGT", testTicket{})
+		pgtIou = "PGTIOU-" + pgt
+		casdoor.mu.Lock()
+		casdoor.pgts[pgt] = pgtUrl
+		casdoor.mu.Unlock()
+
+		resp, err := http.Get(pgtUrl + "?pgtId=" + pgt + "&pgtIou=" + pgtIou)
+		if err != nil {
+			t.Errorf("Failed to call the proxy callback: %v", err)
+			return
+		}
+		resp.Body.Close()
+	}
+
+	if isJson {
+		success := map[string]interface{}{"user": "alice"}
+		if pgtIou != "" {
+			success["proxyGrantingTicket"] = pgtIou
+		}
+		if len(ticket.proxies) > 0 {
+			success["proxies"] = ticket.proxies
+		}
+		if isV3 {
+			success["attributes"] = map[string]interface{}{
+				"authenticationDate": "2026-01-02T03:04:05Z",
+				"isFromNewLogin":     true,
+				"memberOf":           []string{"built-in/staff"},
+				"userAttributes": map[string]interface{}{
+					"attributes": []map[string]string{
+						{"name": "owner", "value": "built-in"},
+						{"name": "email", "value": "alice@example.com"},
+						{"name": "displayName", "value": "Alice"},
+					},
+				},
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"serviceResponse": map[string]interface{}{"authenticationSuccess": success}})
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(`<cas:serviceResponse xmlns:cas="http://www.yale.edu/tp/cas"><cas:authenticationSuccess><cas:user>alice</cas:user>`)
+	if pgtIou != "" {
+		fmt.Fprintf(&b, `<cas:proxyGrantingTicket>%s</cas:proxyGrantingTicket>`, pgtIou)
+	}
+	if len(ticket.proxies) > 0 {
+		b.WriteString(`<cas:proxies>`)
+		for _, proxy := range ticket.proxies {
+			fmt.Fprintf(&b, `<cas:proxy>%s</cas:proxy>`, proxy)
+		}
+		b.WriteString(`</cas:proxies>`)
+	}
+	if isV3 {
+		b.WriteString(`<cas:attributes><cas:authenticationDate>2026-01-02T03:04:05Z</cas:authenticationDate>` +
+			`<cas:isFromNewLogin>true</cas:isFromNewLogin><cas:memberOf>built-in/staff</cas:memberOf>` +
+			`<cas:userAttributes><cas:attribute name="owner" value="built-in"></cas:attribute>` +
+			`<cas:attribute name="email" value="alice@example.com"></cas:attribute>` +
+			`<cas:attribute name="displayName">Alice</cas:attribute></cas:userAttributes></cas:attributes>`)
+	}
+	b.WriteString(`</cas:authenticationSuccess></cas:serviceResponse>`)
+	_, _ = w.Write([]byte(b.String()))
+}
+
+// getTicket returns a service ticket issued for the service, as if the user signed in.
+func (casdoor *testCasdoor) getTicket(service string) string {
+	return casdoor.newTicket("ST", testTicket{service: service})
+}
+
+func newTestClient(casdoor *testCasdoor) *casdoorsdk.Client {
+	return casdoorsdk.NewClient(casdoor.URL, "client-id", "client-secret", "", "built-in", "app-built-in")
+}
+
+func TestValidateServiceTicket(t *testing.T) {
+	casdoor := newTestCasdoor(t)
+	defer casdoor.Close()
+
+	service := "https://app.example.com/private"
+	for _, test := range []struct {
+		name string
+		opts []Option
+	}{
+		{"XML", nil},
+		{"JSON", []Option{WithJsonFormat()}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			casClient := New(newTestClient(casdoor), test.opts...)
+			principal, err := casClient.ValidateServiceTicket(casdoor.getTicket(service), service)
+			if err != nil {
+				t.Fatalf("Failed to validate the ticket: %v", err)
+			}
+
+			if principal.User != "alice" || principal.Owner != "built-in" || principal.Email != "alice@example.com" || principal.DisplayName != "Alice" {
+				t.Errorf("Unexpected principal: %+v", principal)
+			}
+			if len(principal.Groups) != 1 || principal.Groups[0] != "built-in/staff" || !principal.IsFromNewLogin || principal.AuthenticationDate.Year() != 2026 {
+				t.Errorf("Unexpected attributes: %+v", principal)
+			}
+			user := principal.ToUser()
+			if user.Name != "alice" || user.Email != "alice@example.com" {
+				t.Errorf("Unexpected user: %+v", user)
+			}
+
+			// A ticket is only valid once, and for its service
+			_, err = casClient.ValidateServiceTicket(casdoor.getTicket(service), service+"/other")
+			var authErr *AuthenticationError
+			if !errors.As(err, &authErr) || authErr.Code != CodeInvalidTicket || authErr.Description != "Ticket not recognized" {
+				t.Errorf("Expected INVALID_TICKET, got %v", err)
+			}
+		})
+	}
+
+	// CAS 2.0 returns no attributes
+	casClient := New(newTestClient(casdoor), WithProtocolVersion(Version2))
+	principal, err := casClient.ValidateServiceTicket(casdoor.getTicket(service), service)
+	if err != nil {
+		t.Fatalf("Failed to validate the ticket with CAS 2.0: %v", err)
+	}
+	if principal.User != "alice" || principal.Email != "" {
+		t.Errorf("Unexpected CAS 2.0 principal: %+v", principal)
+	}
+}
+
+func TestProxyTickets(t *testing.T) {
+	casdoor := newTestCasdoor(t)
+	defer casdoor.Close()
+
+	mux := http.NewServeMux()
+	proxy := httptest.NewServer(mux)
+	defer proxy.Close()
+
+	pgtUrl := proxy.URL + "/pgt-callback"
+	proxyClient := New(newTestClient(casdoor), WithProxyCallback(pgtUrl))
+	mux.Handle("/pgt-callback", proxyClient.ProxyCallbackHandler())
+
+	// The proxy gets a PGT when validating the user's ticket
+	service := proxy.URL + "/private"
+	principal, err := proxyClient.ValidateServiceTicket(casdoor.getTicket(service), service)
+	if err != nil {
+		t.Fatalf("Failed to validate the ticket: %v", err)
+	}
+	if !strings.HasPrefix(principal.ProxyGrantingTicket, "PGT-") {
+		t.Fatalf("Expected a PGT, got %q", principal.ProxyGrantingTicket)
+	}
+
+	_, err = proxyClient.RequestProxyTicket("PGT-unknown", "https://backend.example.com")
+	var authErr *AuthenticationError
+	if !errors.As(err, &authErr) || authErr.Code != CodeInvalidTicket {
+		t.Errorf("Expected a proxyFailure for an unknown PGT, got %v", err)
+	}
+
+	// The backend validates the proxy ticket, which serviceValidate rejects
+	proxyTicket, err := proxyClient.RequestProxyTicket(principal.ProxyGrantingTicket, "https://backend.example.com")
+	if err != nil {
+		t.Fatalf("Failed to get a proxy ticket: %v", err)
+	}
+
+	backendClient := New(newTestClient(casdoor), WithJsonFormat())
+	_, err = backendClient.ValidateServiceTicket(proxyTicket, "https://backend.example.com")
+	if err == nil {
+		t.Errorf("Expected serviceValidate to reject a proxy ticket")
+	}
+
+	proxyTicket, err = proxyClient.RequestProxyTicket(principal.ProxyGrantingTicket, "https://backend.example.com")
+	if err != nil {
+		t.Fatalf("Failed to get a proxy ticket: %v", err)
+	}
+	principal, err = backendClient.ValidateProxyTicket(proxyTicket, "https://backend.example.com")
+	if err != nil {
+		t.Fatalf("Failed to validate the proxy ticket: %v", err)
+	}
+	if principal.User != "alice" || len(principal.Proxies) != 1 || principal.Proxies[0] != pgtUrl {
+		t.Errorf("Unexpected proxied principal: %+v", principal)
+	}
+}
+
+func TestRequireLogin(t *testing.T) {
+	casdoor := newTestCasdoor(t)
+	defer casdoor.Close()
+
+	mux := http.NewServeMux()
+	app := httptest.NewServer(mux)
+	defer app.Close()
+
+	store := NewMemoryStore()
+	store.Insecure = true
+	casClient := New(newTestClient(casdoor), WithServiceBaseUrl(app.URL), WithSessionStore(store))
+	mux.Handle("/logout", casClient.LogoutHandler())
+	mux.Handle("/", casClient.RequireLogin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := CurrentUser(r)
+		if !ok {
+			t.Errorf("Expected a signed-in user")
+			return
+		}
+		_, _ = fmt.Fprintf(w, "%s %s %s", r.URL.Path, r.URL.RawQuery, principal.Email)
+	})))
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatalf("Failed to create cookie jar: %v", err)
+	}
+	browser := &http.Client{Jar: jar}
+	get := func(u string) (*http.Response, string) {
+		resp, err := browser.Get(u)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", u, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	// The browser goes through Casdoor and comes back to the page without the ticket
+	resp, body := get(app.URL + "/private?b=2&a=1")
+	if body != "/private b=2&a=1 alice@example.com" {
+		t.Fatalf("Unexpected page: %s", body)
+	}
+	if resp.Request.URL.Query().Get("ticket") != "" {
+		t.Errorf("Expected the ticket to be removed from %s", resp.Request.URL)
+	}
+
+	// The session is kept
+	casdoor.mu.Lock()
+	count := casdoor.count
+	casdoor.mu.Unlock()
+	_, body = get(app.URL + "/other")
+	if body != "/other  alice@example.com" || casdoor.count != count {
+		t.Errorf("Expected the session to be kept, got %s", body)
+	}
+
+	// A forged ticket is rejected
+	resp, _ = get(app.URL + "/private?ticket=ST-forged")
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a forged ticket, got %d", resp.StatusCode)
+	}
+
+	// The browser isn't redirected to another site by a path the router didn't clean
+	ticket := casdoor.newTicket("ST", testTicket{service: app.URL + "//evil.com/x"})
+	recorder := httptest.NewRecorder()
+	casClient.RequireLogin(http.NotFoundHandler()).ServeHTTP(recorder, httptest.NewRequest("GET", "//evil.com/x?ticket="+ticket, nil))
+	if recorder.Code != http.StatusFound || recorder.Header().Get("Location") != "/" {
+		t.Errorf("Expected a redirect to /, got %d to %q", recorder.Code, recorder.Header().Get("Location"))
+	}
+
+	// The logout is a POST, so that other sites can't sign the user out
+	resp, _ = get(app.URL + "/logout")
+	if resp.StatusCode != http.StatusMethodNotAllowed || resp.Header.Get("Allow") != http.MethodPost {
+		t.Errorf("Expected 405 for a GET, got %d", resp.StatusCode)
+	}
+
+	// The logout ends the session at Casdoor too
+	resp, err = browser.PostForm(app.URL+"/logout", nil)
+	if err != nil {
+		t.Fatalf("Failed to post: %v", err)
+	}
+	logoutBody, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	body = string(logoutBody)
+	if resp.Request.URL.Path != "/cas/built-in/app-built-in/logout" || resp.Request.URL.Query().Get("service") != app.URL+"/" || body != "logged out" {
+		t.Errorf("Unexpected logout redirect: %s", resp.Request.URL)
+	}
+	resp, err = browser.Post(app.URL+"/private", "text/plain", nil)
+	if err != nil {
+		t.Fatalf("Failed to post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 after logout, got %d", resp.StatusCode)
+	}
+
+	// The service URL must be configured
+	unconfigured := httptest.NewServer(New(newTestClient(casdoor)).RequireLogin(http.NotFoundHandler()))
+	defer unconfigured.Close()
+	resp, _ = get(unconfigured.URL)
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected 500 without a service base URL, got %d", resp.StatusCode)
+	}
+}
+
+func TestGetLocalPath(t *testing.T) {
+	tests := map[string]string{
+		"/private?tab=1":         "/private?tab=1",
+		"":                       "/",
+		"https://evil.com":       "/",
+		"//evil.com/x":           "/",
+		"/\\evil.com":            "/",
+		"javascript:alert(1)//x": "/",
+	}
+	for requestUri, expected := range tests {
+		if actual := getLocalPath(requestUri); actual != expected {
+			t.Errorf("getLocalPath(%q) = %q, expected %q", requestUri, actual, expected)
+		}
+	}
+}
+
+func TestRemoveTicket(t *testing.T) {
+	for rawQuery, expected := range map[string]string{
+		"ticket=ST-1":              "",
+		"a=1&ticket=ST-1":          "a=1",
+		"ticket=ST-1&b=%2F&ticket": "b=%2F",
+		"tickets=1&a=1":            "tickets=1&a=1",
+	} {
+		if actual := removeTicket(rawQuery); actual != expected {
+			t.Errorf("removeTicket(%q) = %q, expected %q", rawQuery, actual, expected)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cas implements a CAS 2.0 and 3.0 client signing the users in with the CAS server
+// of a Casdoor application, for the applications that don't speak OpenID Connect:
+//
+//	casClient := cas.New(client, cas.WithServiceBaseUrl("https://app.example.com"))
+//	mux.Handle("/logout", casClient.LogoutHandler())
+//	mux.Handle("/", casClient.RequireLogin(appHandler))
+//
+// The handlers behind RequireLogin() get the user with CurrentUser(r). The service tickets
+// can also be validated directly with ValidateServiceTicket().
+package cas
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+)
+
+const (
+	// Version2 validates the tickets with "serviceValidate" and "proxyValidate" of CAS 2.0.
+	Version2 = 2
+	// Version3 validates the tickets with "p3/serviceValidate" and "p3/proxyValidate" of
+	// CAS 3.0, which also return the user's attributes.
+	Version3 = 3
+)
+
+// Option is a function type for configuring a Client.
+type Option func(*Client)
+
+// WithProtocolVersion sets the CAS version of the validations, Version2 or Version3, the
+// default is Version3.
+func WithProtocolVersion(version int) Option {
+	return func(c *Client) {
+		c.version = version
+	}
+}
+
+// WithJsonFormat asks Casdoor for JSON validation responses instead of XML.
+func WithJsonFormat() Option {
+	return func(c *Client) {
+		c.jsonFormat = true
+	}
+}
+
+// WithRenew makes the users enter their credentials again at each sign-in, instead of
+// being signed in with their existing Casdoor session, and only accepts such tickets.
+func WithRenew() Option {
+	return func(c *Client) {
+		c.renew = true
+	}
+}
+
+// WithProxyCallback requests a proxy-granting ticket (PGT) at each validation, sent by Casdoor
+// to the pgtUrl, the absolute HTTPS URL of the ProxyCallbackHandler(). The PGT is then
+// returned in Principal.ProxyGrantingTicket and gets proxy tickets with RequestProxyTicket().
+// The PGTs are kept in memory, so the callback must be served by the instance validating
+// the tickets.
+func WithProxyCallback(pgtUrl string) Option {
+	return func(c *Client) {
+		c.pgtUrl = pgtUrl
+	}
+}
+
+// WithServiceBaseUrl sets the absolute URL of the application, like "https://app.example.com",
+// on which RequireLogin() builds the service URLs of the requested pages. It is needed by
+// RequireLogin(), as the Host header of the requests can't be trusted.
+func WithServiceBaseUrl(serviceBaseUrl string) Option {
+	return func(c *Client) {
+		c.serviceBaseUrl = strings.TrimSuffix(serviceBaseUrl, "/")
+	}
+}
+
+// WithSessionStore sets where RequireLogin() keeps the signed-in users, the default is a
+// MemoryStore.
+func WithSessionStore(store SessionStore) Option {
+	return func(c *Client) {
+		c.store = store
+	}
+}
+
+// WithErrorHandler sets the function writing the error responses of the handlers, the default
+// writes the status text of the code.
+func WithErrorHandler(errorHandler func(w http.ResponseWriter, r *http.Request, statusCode int, err error)) Option {
+	return func(c *Client) {
+		c.errorHandler = errorHandler
+	}
+}
+
+// Client signs the users in with the CAS server of a Casdoor application, see New().
+type Client struct {
+	client         *casdoorsdk.Client
+	version        int
+	jsonFormat     bool
+	renew          bool
+	pgtUrl         string
+	serviceBaseUrl string
+	store          SessionStore
+	errorHandler   func(w http.ResponseWriter, r *http.Request, statusCode int, err error)
+	pgts           *pgtRegistry
+}
+
+// New returns a Client using the CAS server of the client's organization and application,
+// at "/cas/{organization}/{application}" of Casdoor.
+func New(client *casdoorsdk.Client, opts ...Option) *Client {
+	c := &Client{
+		client:  client,
+		version: Version3,
+		errorHandler: func(w http.ResponseWriter, r *http.Request, statusCode int, err error) {
+			http.Error(w, http.StatusText(statusCode), statusCode)
+		},
+		pgts: newPgtRegistry(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.store == nil {
+		c.store = NewMemoryStore()
+	}
+
+	return c
+}
+
+func (c *Client) getUrl(action string, query url.Values) string {
+	u := strings.TrimSuffix(c.client.Endpoint, "/") + "/cas/" + url.PathEscape(c.client.OrganizationName) + "/" + url.PathEscape(c.client.ApplicationName) + "/" + action
+	if len(query) == 0 {
+		return u
+	}
+
+	return u + "?" + query.Encode()
+}
+
+// GetLoginUrl returns the URL of Casdoor's CAS sign-in page, which redirects the browser to the
+// service with a service ticket in the "ticket" query parameter.
+func (c *Client) GetLoginUrl(service string) string {
+	query := url.Values{"service": {service}}
+	if c.renew {
+		query.Set("renew", "true")
+	}
+
+	return c.getUrl("login", query)
+}
+
+// GetLogoutUrl returns the URL of Casdoor's CAS logout page, which ends the user's Casdoor
+// session and then redirects the browser to the service, when it is not empty.
+func (c *Client) GetLogoutUrl(service string) string {
+	query := url.Values{}
+	if service != "" {
+		query.Set("service", service)
+	}
+
+	return c.getUrl("logout", query)
+}
+
+// ValidateServiceTicket validates a service ticket, issued for the service by Casdoor's sign-in
+// page, and returns the signed-in user. A rejected ticket returns an *AuthenticationError.
+func (c *Client) ValidateServiceTicket(ticket string, service string) (*Principal, error) {
+	return c.validate("serviceValidate", ticket, service)
+}
+
+// ValidateProxyTicket is like ValidateServiceTicket() but also accepts the proxy tickets, issued
+// to the services proxying the user with RequestProxyTicket(). The proxies that the ticket went
+// through are in Principal.Proxies, the most recent first, and should be checked by the caller.
+func (c *Client) ValidateProxyTicket(ticket string, service string) (*Principal, error) {
+	return c.validate("proxyValidate", ticket, service)
+}
+
+func (c *Client) validate(action string, ticket string, service string) (*Principal, error) {
+	if ticket == "" || service == "" {
+		return nil, errors.New("validate() error: the ticket and service should not be empty")
+	}
+
+	if c.version == Version3 {
+		action = "p3/" + action
+	}
+
+	query := url.Values{"ticket": {ticket}, "service": {service}}
+	if c.renew {
+		query.Set("renew", "true")
+	}
+	if c.pgtUrl != "" {
+		query.Set("pgtUrl", c.pgtUrl)
+	}
+	if c.jsonFormat {
+		query.Set("format", "JSON")
+	}
+
+	respBytes, err := c.client.DoGetBytesRaw(c.getUrl(action, query))
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := parseServiceResponse(respBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	principal, err := response.getPrincipal()
+	if err != nil {
+		return nil, err
+	}
+
+	if principal.ProxyGrantingTicket != "" {
+		// Casdoor returns an IOU, the PGT itself was sent to the proxy callback
+		principal.ProxyGrantingTicket = c.pgts.take(principal.ProxyGrantingTicket, time.Now())
+	}
+
+	return principal, nil
+}
+
+// RequestProxyTicket gets a proxy ticket for the targetService with a proxy-granting ticket, see
+// WithProxyCallback(). The targetService validates it with ValidateProxyTicket(). A rejected
+// request returns an *AuthenticationError.
+func (c *Client) RequestProxyTicket(pgt string, targetService string) (string, error) {
+	if pgt == "" || targetService == "" {
+		return "", errors.New("RequestProxyTicket() error: the pgt and targetService should not be empty")
+	}
+
+	query := url.Values{"pgt": {pgt}, "targetService": {targetService}}
+	if c.jsonFormat {
+		query.Set("format", "JSON")
+	}
+
+	respBytes, err := c.client.DoGetBytesRaw(c.getUrl("proxy", query))
+	if err != nil {
+		return "", err
+	}
+
+	response, err := parseServiceResponse(respBytes)
+	if err != nil {
+		return "", err
+	}
+
+	return response.getProxyTicket()
+}
@@ -0,0 +1,328 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cas
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// pgtRetention is how long a PGT sent to the proxy callback waits for its validation.
+const pgtRetention = 5 * time.Minute
+
+type principalContextKey struct{}
+
+// CurrentUser returns the user signed in by RequireLogin(). The second result is false when
+// the user is not signed in.
+func CurrentUser(r *http.Request) (*Principal, bool) {
+	principal, ok := r.Context().Value(principalContextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// RequireLogin redirects the browser to Casdoor's CAS sign-in page when the user is not signed
+// in, validates the service ticket when the browser comes back, and keeps the user in the
+// session store. The requests other than GET and HEAD get a 401 response instead. It needs
+// WithServiceBaseUrl().
+func (c *Client) RequireLogin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c.serviceBaseUrl == "" {
+			c.errorHandler(w, r, http.StatusInternalServerError, errors.New("RequireLogin() error: the service base URL is not set, see WithServiceBaseUrl()"))
+			return
+		}
+
+		isGet := r.Method == http.MethodGet || r.Method == http.MethodHead
+		ticket := r.URL.Query().Get("ticket")
+		if ticket != "" && isGet {
+			// Casdoor appended the ticket to the service URL of the sign-in, so that URL is
+			// rebuilt without it, keeping the other query parameters as they were
+			requestUri := r.URL.EscapedPath()
+			rawQuery := removeTicket(r.URL.RawQuery)
+			if rawQuery != "" {
+				requestUri += "?" + rawQuery
+			}
+
+			principal, err := c.ValidateServiceTicket(ticket, c.serviceBaseUrl+requestUri)
+			if err != nil {
+				c.errorHandler(w, r, http.StatusUnauthorized, err)
+				return
+			}
+
+			err = c.store.Save(w, r, principal)
+			if err != nil {
+				c.errorHandler(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			http.Redirect(w, r, getLocalPath(requestUri), http.StatusFound)
+			return
+		}
+
+		principal, err := c.store.Load(r)
+		if err != nil {
+			c.errorHandler(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if principal != nil {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalContextKey{}, principal)))
+			return
+		}
+
+		if !isGet {
+			c.errorHandler(w, r, http.StatusUnauthorized, errors.New("the user is not signed in"))
+			return
+		}
+
+		http.Redirect(w, r, c.GetLoginUrl(c.serviceBaseUrl+r.URL.RequestURI()), http.StatusFound)
+	})
+}
+
+// getLocalPath only accepts the local paths, so that a request to a path like "//evil.com",
+// which the routers that don't clean the paths let through, isn't redirected to another site.
+func getLocalPath(requestUri string) string {
+	if !strings.HasPrefix(requestUri, "/") || strings.HasPrefix(requestUri, "//") || strings.HasPrefix(requestUri, "/\\") {
+		return "/"
+	}
+
+	u, err := url.Parse(requestUri)
+	if err != nil || u.IsAbs() || u.Host != "" {
+		return "/"
+	}
+
+	return requestUri
+}
+
+// removeTicket removes the "ticket" parameters from a raw query.
+func removeTicket(rawQuery string) string {
+	var params []string
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param == "" || param == "ticket" || strings.HasPrefix(param, "ticket=") {
+			continue
+		}
+		params = append(params, param)
+	}
+
+	return strings.Join(params, "&")
+}
+
+// LogoutHandler returns the handler that ends the user's session and redirects the browser to
+// Casdoor's CAS logout page, which then redirects it back to the service base URL. It only
+// accepts POST, so that other sites can't sign the users out with a link or an image.
+func (c *Client) LogoutHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			c.errorHandler(w, r, http.StatusMethodNotAllowed, errors.New("the logout request should be a POST"))
+			return
+		}
+
+		err := c.store.Delete(w, r)
+		if err != nil {
+			c.errorHandler(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		service := ""
+		if c.serviceBaseUrl != "" {
+			service = c.serviceBaseUrl + "/"
+		}
+		http.Redirect(w, r, c.GetLogoutUrl(service), http.StatusFound)
+	})
+}
+
+// ProxyCallbackHandler returns the handler of the pgtUrl, see WithProxyCallback(), to which
+// Casdoor sends the PGTs before returning their IOUs in the validation responses.
+func (c *Client) ProxyCallbackHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pgtIou := r.FormValue("pgtIou")
+		pgt := r.FormValue("pgtId")
+		if pgtIou != "" && pgt != "" {
+			c.pgts.put(pgtIou, pgt, time.Now())
+		}
+
+		// the callback is also called without parameters, to check that it is reachable
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+type pendingPgt struct {
+	pgt         string
+	createdTime time.Time
+}
+
+// pgtRegistry keeps the PGTs sent to the proxy callback, by IOU, until their validation.
+type pgtRegistry struct {
+	mu   sync.Mutex
+	pgts map[string]pendingPgt
+}
+
+func newPgtRegistry() *pgtRegistry {
+	return &pgtRegistry{pgts: map[string]pendingPgt{}}
+}
+
+func (registry *pgtRegistry) put(pgtIou string, pgt string, now time.Time) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	for iou, pending := range registry.pgts {
+		if now.Sub(pending.createdTime) > pgtRetention {
+			delete(registry.pgts, iou)
+		}
+	}
+
+	// the first PGT of an IOU is kept, so it can't be replaced by another request
+	if _, ok := registry.pgts[pgtIou]; !ok {
+		registry.pgts[pgtIou] = pendingPgt{pgt: pgt, createdTime: now}
+	}
+}
+
+// take returns the PGT of the IOU, or "" if it wasn't received, and forgets it.
+func (registry *pgtRegistry) take(pgtIou string, now time.Time) string {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	pending, ok := registry.pgts[pgtIou]
+	if !ok {
+		return ""
+	}
+
+	delete(registry.pgts, pgtIou)
+	if now.Sub(pending.createdTime) > pgtRetention {
+		return ""
+	}
+
+	return pending.pgt
+}
+
+// SessionStore keeps the users signed in by RequireLogin(). Load() returns nil without error
+// when the browser has no session.
+type SessionStore interface {
+	Load(r *http.Request) (*Principal, error)
+	Save(w http.ResponseWriter, r *http.Request, principal *Principal) error
+	Delete(w http.ResponseWriter, r *http.Request) error
+}
+
+type memorySession struct {
+	principal   *Principal
+	createdTime time.Time
+}
+
+// MemoryStore is a SessionStore keeping the sessions in memory, identified by a random ID in
+// a cookie. The sessions are lost when the application restarts and aren't shared by its
+// instances, which otherwise need a SessionStore backed by a shared storage.
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]memorySession
+
+	// Name is the name of the cookie, the default is "casdoor_cas_session".
+	Name string
+	// Path is the path of the cookie, the default is "/".
+	Path string
+	// MaxAge is the lifetime of the sessions, the default is 8 hours.
+	MaxAge time.Duration
+	// Insecure lets the cookie be sent over plain HTTP, it should only be set for local development.
+	Insecure bool
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: map[string]memorySession{},
+		Name:     "casdoor_cas_session",
+		Path:     "/",
+		MaxAge:   8 * time.Hour,
+	}
+}
+
+func (s *MemoryStore) Load(r *http.Request) (*Principal, error) {
+	cookie, err := r.Cookie(s.Name)
+	if err != nil {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[cookie.Value]
+	if !ok {
+		return nil, nil
+	}
+	if time.Since(session.createdTime) > s.MaxAge {
+		delete(s.sessions, cookie.Value)
+		return nil, nil
+	}
+
+	return session.principal, nil
+}
+
+func (s *MemoryStore) Save(w http.ResponseWriter, r *http.Request, principal *Principal) error {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return err
+	}
+	id := base64.RawURLEncoding.EncodeToString(b)
+
+	s.mu.Lock()
+	now := time.Now()
+	for sessionId, session := range s.sessions {
+		if now.Sub(session.createdTime) > s.MaxAge {
+			delete(s.sessions, sessionId)
+		}
+	}
+	// a new ID is used at each sign-in, so that an ID set before can't be reused
+	if cookie, err := r.Cookie(s.Name); err == nil {
+		delete(s.sessions, cookie.Value)
+	}
+	s.sessions[id] = memorySession{principal: principal, createdTime: now}
+	s.mu.Unlock()
+
+	http.SetCookie(w, s.newCookie(id, int(s.MaxAge/time.Second)))
+	return nil
+}
+
+func (s *MemoryStore) Delete(w http.ResponseWriter, r *http.Request) error {
+	cookie, err := r.Cookie(s.Name)
+	if err != nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	delete(s.sessions, cookie.Value)
+	s.mu.Unlock()
+
+	http.SetCookie(w, s.newCookie("", -1))
+	return nil
+}
+
+func (s *MemoryStore) newCookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     s.Name,
+		Value:    value,
+		Path:     s.Path,
+		MaxAge:   maxAge,
+		Secure:   !s.Insecure,
+		HttpOnly: true,
+		// "Lax" lets the cookie be sent when Casdoor redirects the browser to the service
+		SameSite: http.SameSiteLaxMode,
+	}
+}
@@ -0,0 +1,378 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cas
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+)
+
+// The error codes of the CAS protocol, see AuthenticationError.
+const (
+	CodeInvalidRequest           = "INVALID_REQUEST"
+	CodeInvalidTicket            = "INVALID_TICKET"
+	CodeInvalidService           = "INVALID_SERVICE"
+	CodeInternalError            = "INTERNAL_ERROR"
+	CodeUnauthorizedServiceProxy = "UNAUTHORIZED_SERVICE_PROXY"
+	CodeInvalidProxyCallback     = "INVALID_PROXY_CALLBACK"
+)
+
+// AuthenticationError is the authenticationFailure or proxyFailure returned by Casdoor when a
+// ticket or a proxy ticket request is rejected.
+type AuthenticationError struct {
+	Code        string
+	Description string
+}
+
+func (e *AuthenticationError) Error() string {
+	return fmt.Sprintf("CAS %s: %s", e.Code, e.Description)
+}
+
+// Principal is the user signed in with a CAS ticket.
+type Principal struct {
+	// User is the name of the user, the "user" of the response.
+	User string `json:"user"`
+	// Owner, Id, DisplayName, Email, Phone and Avatar are the user's attributes of the same name
+	// and Groups is "memberOf", when Casdoor returns them with CAS 3.0.
+	Owner       string   `json:"owner,omitempty"`
+	Id          string   `json:"id,omitempty"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Email       string   `json:"email,omitempty"`
+	Phone       string   `json:"phone,omitempty"`
+	Avatar      string   `json:"avatar,omitempty"`
+	Groups      []string `json:"groups,omitempty"`
+	// Attributes are all the attributes of the response, by name.
+	Attributes map[string][]string `json:"attributes,omitempty"`
+
+	AuthenticationDate time.Time `json:"authenticationDate,omitempty"`
+	IsFromNewLogin     bool      `json:"isFromNewLogin,omitempty"`
+	// ProxyGrantingTicket is the PGT of the validation, see WithProxyCallback().
+	ProxyGrantingTicket string `json:"proxyGrantingTicket,omitempty"`
+	// Proxies are the services that a proxy ticket went through, the most recent first.
+	Proxies []string `json:"proxies,omitempty"`
+}
+
+// ToUser returns a casdoorsdk.User with the fields of the principal.
+func (p *Principal) ToUser() *casdoorsdk.User {
+	return &casdoorsdk.User{
+		Owner:       p.Owner,
+		Name:        p.User,
+		Id:          p.Id,
+		DisplayName: p.DisplayName,
+		Email:       p.Email,
+		Phone:       p.Phone,
+		Avatar:      p.Avatar,
+		Groups:      p.Groups,
+	}
+}
+
+// serviceResponse is a CAS serviceResponse, in XML or in JSON.
+type serviceResponse struct {
+	failure     *AuthenticationError
+	success     bool
+	user        string
+	pgtIou      string
+	proxies     []string
+	attributes  map[string][]string
+	proxyTicket string
+}
+
+func parseServiceResponse(data []byte) (*serviceResponse, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		return parseJsonResponse(data)
+	}
+
+	return parseXmlResponse(data)
+}
+
+func (r *serviceResponse) getPrincipal() (*Principal, error) {
+	if r.failure != nil {
+		return nil, r.failure
+	}
+	if !r.success {
+		return nil, errors.New("the CAS response has no authenticationSuccess")
+	}
+	if r.user == "" {
+		return nil, errors.New("the CAS response has no user")
+	}
+
+	principal := &Principal{
+		User:                r.user,
+		Owner:               getFirst(r.attributes, "owner"),
+		Id:                  getFirst(r.attributes, "id"),
+		DisplayName:         getFirst(r.attributes, "displayName"),
+		Email:               getFirst(r.attributes, "email"),
+		Phone:               getFirst(r.attributes, "phone"),
+		Avatar:              getFirst(r.attributes, "avatar"),
+		Groups:              r.attributes["memberOf"],
+		Attributes:          r.attributes,
+		IsFromNewLogin:      getFirst(r.attributes, "isFromNewLogin") == "true",
+		ProxyGrantingTicket: r.pgtIou,
+		Proxies:             r.proxies,
+	}
+
+	authenticationDate, err := time.Parse(time.RFC3339, getFirst(r.attributes, "authenticationDate"))
+	if err == nil {
+		principal.AuthenticationDate = authenticationDate
+	}
+
+	return principal, nil
+}
+
+func (r *serviceResponse) getProxyTicket() (string, error) {
+	if r.failure != nil {
+		return "", r.failure
+	}
+	if r.proxyTicket == "" {
+		return "", errors.New("the CAS response has no proxyTicket")
+	}
+
+	return r.proxyTicket, nil
+}
+
+// xmlNode is any XML element, as the CAS responses are matched by the local names of their
+// elements, whatever their namespace prefix.
+type xmlNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Text     string     `xml:",chardata"`
+	Children []xmlNode  `xml:",any"`
+}
+
+func (n *xmlNode) getChild(name string) *xmlNode {
+	for i := range n.Children {
+		if n.Children[i].XMLName.Local == name {
+			return &n.Children[i]
+		}
+	}
+
+	return nil
+}
+
+func (n *xmlNode) getText(name string) string {
+	child := n.getChild(name)
+	if child == nil {
+		return ""
+	}
+
+	return strings.TrimSpace(child.Text)
+}
+
+func (n *xmlNode) getAttr(name string) (string, bool) {
+	for _, attr := range n.Attrs {
+		if attr.Name.Local == name {
+			return attr.Value, true
+		}
+	}
+
+	return "", false
+}
+
+func parseXmlResponse(data []byte) (*serviceResponse, error) {
+	var root xmlNode
+	err := xml.Unmarshal(data, &root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CAS response: %w", err)
+	}
+	if root.XMLName.Local != "serviceResponse" {
+		return nil, fmt.Errorf("invalid CAS response: unexpected element %q", root.XMLName.Local)
+	}
+
+	response := &serviceResponse{}
+	for _, name := range []string{"authenticationFailure", "proxyFailure"} {
+		if failure := root.getChild(name); failure != nil {
+			code, _ := failure.getAttr("code")
+			response.failure = &AuthenticationError{Code: code, Description: strings.TrimSpace(failure.Text)}
+			return response, nil
+		}
+	}
+
+	if proxySuccess := root.getChild("proxySuccess"); proxySuccess != nil {
+		response.proxyTicket = proxySuccess.getText("proxyTicket")
+	}
+
+	success := root.getChild("authenticationSuccess")
+	if success == nil {
+		return response, nil
+	}
+
+	response.success = true
+	response.attributes = map[string][]string{}
+	for i := range success.Children {
+		child := &success.Children[i]
+		switch child.XMLName.Local {
+		case "user":
+			response.user = strings.TrimSpace(child.Text)
+		case "proxyGrantingTicket":
+			response.pgtIou = strings.TrimSpace(child.Text)
+		case "proxies":
+			for _, proxy := range child.Children {
+				response.proxies = append(response.proxies, strings.TrimSpace(proxy.Text))
+			}
+		case "attributes":
+			addXmlAttributes(response.attributes, child)
+		default:
+			// the attributes returned by CAS 2.0 extensions
+			addXmlAttributes(response.attributes, &xmlNode{Children: []xmlNode{*child}})
+		}
+	}
+
+	return response, nil
+}
+
+// addXmlAttributes adds the attributes under the node, which are either named by their
+// elements, like <cas:email>, or by the "name" of <cas:attribute> elements, and may be
+// grouped under an element like <cas:userAttributes>.
+func addXmlAttributes(attributes map[string][]string, node *xmlNode) {
+	for i := range node.Children {
+		child := &node.Children[i]
+		if name, ok := child.getAttr("name"); ok && child.XMLName.Local == "attribute" {
+			value, ok := child.getAttr("value")
+			if !ok {
+				value = strings.TrimSpace(child.Text)
+			}
+			attributes[name] = append(attributes[name], value)
+		} else if len(child.Children) > 0 {
+			addXmlAttributes(attributes, child)
+		} else {
+			attributes[child.XMLName.Local] = append(attributes[child.XMLName.Local], strings.TrimSpace(child.Text))
+		}
+	}
+}
+
+func parseJsonResponse(data []byte) (*serviceResponse, error) {
+	var root map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err := decoder.Decode(&root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CAS response: %w", err)
+	}
+
+	if wrapped, ok := root["serviceResponse"].(map[string]interface{}); ok {
+		root = wrapped
+	}
+
+	response := &serviceResponse{}
+	for _, name := range []string{"authenticationFailure", "proxyFailure"} {
+		if failure, ok := root[name].(map[string]interface{}); ok {
+			response.failure = &AuthenticationError{Code: getString(failure["code"]), Description: getString(failure["description"])}
+			return response, nil
+		}
+	}
+
+	if proxySuccess, ok := root["proxySuccess"].(map[string]interface{}); ok {
+		response.proxyTicket = getString(proxySuccess["proxyTicket"])
+	}
+
+	success, ok := root["authenticationSuccess"].(map[string]interface{})
+	if !ok {
+		return response, nil
+	}
+
+	response.success = true
+	response.attributes = map[string][]string{}
+	for key, value := range success {
+		switch key {
+		case "user":
+			response.user = getString(value)
+		case "proxyGrantingTicket":
+			response.pgtIou = getString(value)
+		case "proxies":
+			response.proxies = getStrings(value)
+		case "attributes", "extensions":
+			addJsonAttributes(response.attributes, "", value)
+		default:
+			addJsonAttributes(response.attributes, key, value)
+		}
+	}
+
+	return response, nil
+}
+
+// addJsonAttributes adds the attributes of the value, which are either named by their keys,
+// like {"email": ["alice@example.com"]}, or by the "name" of {"name": ..., "value": ...}
+// objects, and may be grouped under a key like "userAttributes".
+func addJsonAttributes(attributes map[string][]string, key string, value interface{}) {
+	switch value := value.(type) {
+	case nil:
+	case []interface{}:
+		for _, item := range value {
+			addJsonAttributes(attributes, key, item)
+		}
+	case map[string]interface{}:
+		if name, ok := value["name"].(string); ok && len(value) == 2 && value["value"] != nil {
+			addJsonAttributes(attributes, name, value["value"])
+			return
+		}
+
+		for k, v := range value {
+			addJsonAttributes(attributes, k, v)
+		}
+	default:
+		if key != "" {
+			attributes[key] = append(attributes[key], getString(value))
+		}
+	}
+}
+
+func getString(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// getStrings returns the strings in the value, like the proxies of CAS 3.0, a JSON array.
+func getStrings(value interface{}) []string {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		var res []string
+		for _, item := range value {
+			res = append(res, getStrings(item)...)
+		}
+		return res
+	case map[string]interface{}:
+		var res []string
+		for _, item := range value {
+			res = append(res, getStrings(item)...)
+		}
+		return res
+	default:
+		return []string{getString(value)}
+	}
+}
+
+func getFirst(attributes map[string][]string, name string) string {
+	if len(attributes[name]) == 0 {
+		return ""
+	}
+
+	return attributes[name][0]
+}
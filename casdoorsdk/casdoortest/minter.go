@@ -0,0 +1,472 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package casdoortest provides the helpers to unit-test the code that consumes the tokens of
+// Casdoor without a Casdoor server. A TokenMinter signs the tokens like Casdoor does, with a
+// local cert that the client under test is configured with:
+//
+//	minter, err := casdoortest.NewTokenMinter(casdoorsdk.CertAlgorithmRs256)
+//	client := casdoorsdk.NewClientWithConf(minter.AuthConfig("built-in", "app-built-in"))
+//
+//	token, err := minter.MintAccessToken(&casdoorsdk.User{Owner: "built-in", Name: "alice"}, nil)
+//	claims, err := client.ParseJwtToken(token)
+package casdoortest
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"hash"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// The token formats of Application.TokenFormat.
+const (
+	TokenFormatJwt         = "JWT"
+	TokenFormatJwtEmpty    = "JWT-Empty"
+	TokenFormatJwtStandard = "JWT-Standard"
+	TokenFormatJwtCustom   = "JWT-Custom"
+)
+
+// SupportedAlgorithms are the crypto algorithms of the certs that Casdoor signs the tokens with.
+var SupportedAlgorithms = []string{
+	casdoorsdk.CertAlgorithmRs256,
+	casdoorsdk.CertAlgorithmRs512,
+	casdoorsdk.CertAlgorithmEs256,
+	casdoorsdk.CertAlgorithmEs384,
+	casdoorsdk.CertAlgorithmEs512,
+}
+
+// Option is a function type for configuring a TokenMinter.
+type Option func(*TokenMinter)
+
+// WithIssuer sets the "iss" claim and the Endpoint of AuthConfig(), it defaults to
+// "http://localhost:8000".
+func WithIssuer(issuer string) Option {
+	return func(m *TokenMinter) {
+		m.issuer = issuer
+	}
+}
+
+// WithClientId sets the "aud" and "azp" claims and the ClientId of AuthConfig(), it defaults
+// to "client-id".
+func WithClientId(clientId string) Option {
+	return func(m *TokenMinter) {
+		m.clientId = clientId
+	}
+}
+
+// WithTokenFormat sets the claim layout of the tokens, one of the TokenFormat* constants, it
+// defaults to "JWT".
+func WithTokenFormat(tokenFormat string) Option {
+	return func(m *TokenMinter) {
+		m.tokenFormat = tokenFormat
+	}
+}
+
+// WithTokenFields sets the fields of the user in the "JWT-Custom" tokens, the names of the
+// fields of casdoorsdk.User like in Application.TokenFields, e.g. "Email" or "Roles".
+func WithTokenFields(fields ...string) Option {
+	return func(m *TokenMinter) {
+		m.tokenFields = fields
+	}
+}
+
+// WithScope sets the "scope" claim, it defaults to "openid profile email". The claims of the
+// "JWT-Standard" tokens depend on it.
+func WithScope(scope string) Option {
+	return func(m *TokenMinter) {
+		m.scope = scope
+	}
+}
+
+// WithExpireIn sets the lifetime of the access and ID tokens, and of the refresh tokens,
+// they default to 168 hours like Application.ExpireInHours and Application.RefreshExpireInHours.
+func WithExpireIn(expireIn time.Duration, refreshExpireIn time.Duration) Option {
+	return func(m *TokenMinter) {
+		m.expireIn = expireIn
+		m.refreshExpireIn = refreshExpireIn
+	}
+}
+
+// TokenMinter mints the access, refresh and ID tokens of Casdoor, signed with its Cert.
+type TokenMinter struct {
+	// Cert is the generated cert, whose name is the "kid" of the tokens.
+	Cert *casdoorsdk.Cert
+
+	method          jwt.SigningMethod
+	privateKey      interface{}
+	issuer          string
+	clientId        string
+	tokenFormat     string
+	tokenFields     []string
+	scope           string
+	expireIn        time.Duration
+	refreshExpireIn time.Duration
+}
+
+// NewTokenMinter generates a cert of the crypto algorithm, one of SupportedAlgorithms, and
+// returns a TokenMinter signing with it.
+func NewTokenMinter(cryptoAlgorithm string, opts ...Option) (*TokenMinter, error) {
+	m := &TokenMinter{
+		issuer:          "http://localhost:8000",
+		clientId:        "client-id",
+		tokenFormat:     TokenFormatJwt,
+		scope:           "openid profile email",
+		expireIn:        168 * time.Hour,
+		refreshExpireIn: 168 * time.Hour,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	switch m.tokenFormat {
+	case TokenFormatJwt, TokenFormatJwtEmpty, TokenFormatJwtStandard, TokenFormatJwtCustom:
+	default:
+		return nil, fmt.Errorf("NewTokenMinter() error: unsupported token format %q", m.tokenFormat)
+	}
+
+	m.method = jwt.GetSigningMethod(cryptoAlgorithm)
+	if m.method == nil || !isSupportedAlgorithm(cryptoAlgorithm) {
+		return nil, fmt.Errorf("NewTokenMinter() error: unsupported crypto algorithm %q", cryptoAlgorithm)
+	}
+
+	// the RSA keys are smaller than Casdoor's to keep the tests fast
+	cert, err := casdoorsdk.GenerateCert("cert-casdoortest", cryptoAlgorithm, 2048, 1)
+	if err != nil {
+		return nil, err
+	}
+	m.Cert = cert
+
+	if strings.HasPrefix(cryptoAlgorithm, "RS") {
+		m.privateKey, err = jwt.ParseRSAPrivateKeyFromPEM([]byte(cert.PrivateKey))
+	} else {
+		m.privateKey, err = jwt.ParseECPrivateKeyFromPEM([]byte(cert.PrivateKey))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func isSupportedAlgorithm(cryptoAlgorithm string) bool {
+	for _, algorithm := range SupportedAlgorithms {
+		if algorithm == cryptoAlgorithm {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Certificate returns the PEM of the cert, for AuthConfig.Certificate.
+func (m *TokenMinter) Certificate() string {
+	return m.Cert.Certificate
+}
+
+// AuthConfig returns the config of a client that accepts the minted tokens.
+func (m *TokenMinter) AuthConfig(organizationName string, applicationName string) *casdoorsdk.AuthConfig {
+	return &casdoorsdk.AuthConfig{
+		Endpoint:         m.issuer,
+		ClientId:         m.clientId,
+		ClientSecret:     "client-secret",
+		Certificate:      m.Cert.Certificate,
+		OrganizationName: organizationName,
+		ApplicationName:  applicationName,
+	}
+}
+
+// Jwks returns the JWK Set of the cert, like the one of Casdoor's "/.well-known/jwks" endpoint.
+func (m *TokenMinter) Jwks() (*casdoorsdk.JsonWebKeySet, error) {
+	block, _ := pem.Decode([]byte(m.Cert.Certificate))
+	if block == nil {
+		return nil, fmt.Errorf("Jwks() error: the cert %s has no PEM certificate", m.Cert.Name)
+	}
+
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := casdoorsdk.NewJsonWebKey(certificate.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	key.Kid = m.Cert.Name
+	key.Use = "sig"
+	key.Alg = m.method.Alg()
+	key.X5c = []string{base64.StdEncoding.EncodeToString(block.Bytes)}
+
+	return &casdoorsdk.JsonWebKeySet{Keys: []*casdoorsdk.JsonWebKey{key}}, nil
+}
+
+// JwksHandler serves the JWK Set of Jwks(), to be mounted at "/.well-known/jwks" of a test server.
+func (m *TokenMinter) JwksHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jwks, err := m.Jwks()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(jwks)
+	})
+}
+
+// MintAccessToken mints an access token of the user. The overrides replace the claims of the
+// token, or remove them when their value is nil.
+func (m *TokenMinter) MintAccessToken(user *casdoorsdk.User, overrides map[string]interface{}) (string, error) {
+	return m.mint(user, "access-token", "", m.expireIn, overrides)
+}
+
+// MintRefreshToken mints a refresh token of the user, see MintAccessToken().
+func (m *TokenMinter) MintRefreshToken(user *casdoorsdk.User, overrides map[string]interface{}) (string, error) {
+	return m.mint(user, "refresh-token", "", m.refreshExpireIn, overrides)
+}
+
+// MintIdToken mints an ID token of the user, with the nonce of the authorization request and
+// the "at_hash" of the access token when they are not empty, see MintAccessToken().
+func (m *TokenMinter) MintIdToken(user *casdoorsdk.User, nonce string, accessToken string, overrides map[string]interface{}) (string, error) {
+	if accessToken != "" {
+		atHash := m.getAtHash(accessToken)
+		merged := map[string]interface{}{"at_hash": atHash}
+		for name, value := range overrides {
+			merged[name] = value
+		}
+		overrides = merged
+	}
+
+	return m.mint(user, "access-token", nonce, m.expireIn, overrides)
+}
+
+// getAtHash returns the "at_hash" of the access token: the base64url encoding of the left-most
+// half of its hash, of the size of the signing method's hash.
+func (m *TokenMinter) getAtHash(accessToken string) string {
+	var h hash.Hash
+	switch {
+	case strings.HasSuffix(m.method.Alg(), "256"):
+		h = sha256.New()
+	case strings.HasSuffix(m.method.Alg(), "384"):
+		h = sha512.New384()
+	default:
+		h = sha512.New()
+	}
+
+	h.Write([]byte(accessToken))
+	sum := h.Sum(nil)
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2])
+}
+
+// newTokenId returns a random ID for the "jti" of a token.
+func newTokenId() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func (m *TokenMinter) mint(user *casdoorsdk.User, tokenType string, nonce string, expireIn time.Duration, overrides map[string]interface{}) (string, error) {
+	now := time.Now()
+	registered := jwt.RegisteredClaims{
+		Issuer:    m.issuer,
+		Subject:   user.Id,
+		Audience:  []string{m.clientId},
+		ExpiresAt: jwt.NewNumericDate(now.Add(expireIn)),
+		NotBefore: jwt.NewNumericDate(now),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ID:        fmt.Sprintf("admin/%s", newTokenId()),
+	}
+
+	claims, err := m.getClaims(user, tokenType, nonce, registered)
+	if err != nil {
+		return "", err
+	}
+
+	for name, value := range overrides {
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+	}
+
+	token := jwt.NewWithClaims(m.method, claims)
+	token.Header["kid"] = m.Cert.Name
+	return token.SignedString(m.privateKey)
+}
+
+// getClaims returns the claims of the token in the layout of the minter's token format.
+func (m *TokenMinter) getClaims(user *casdoorsdk.User, tokenType string, nonce string, registered jwt.RegisteredClaims) (jwt.MapClaims, error) {
+	shortUser := casdoorsdk.ShortUser{
+		Owner:       user.Owner,
+		Name:        user.Name,
+		Id:          user.Id,
+		DisplayName: user.DisplayName,
+		Avatar:      user.Avatar,
+		Email:       user.Email,
+		Phone:       user.Phone,
+	}
+
+	var claims interface{}
+	switch m.tokenFormat {
+	case TokenFormatJwt:
+		// Casdoor removes the password of the user from the tokens
+		tokenUser := *user
+		tokenUser.Password = ""
+		tokenUser.PasswordSalt = ""
+		claims = &casdoorsdk.Claims{
+			User:             tokenUser,
+			TokenType:        tokenType,
+			Nonce:            nonce,
+			Scope:            m.scope,
+			Azp:              m.clientId,
+			RegisteredClaims: registered,
+		}
+	case TokenFormatJwtEmpty:
+		claims = &casdoorsdk.ShortClaims{
+			ShortUser:        shortUser,
+			TokenType:        tokenType,
+			Nonce:            nonce,
+			Scope:            m.scope,
+			Azp:              m.clientId,
+			RegisteredClaims: registered,
+		}
+	case TokenFormatJwtStandard:
+		return m.getStandardClaims(user, tokenType, nonce, registered)
+	case TokenFormatJwtCustom:
+		return m.getCustomClaims(user, tokenType, nonce, registered)
+	}
+
+	return toMapClaims(claims)
+}
+
+// getStandardClaims returns the claims of the "JWT-Standard" format, which has the standard
+// claims of OpenID Connect, like the userinfo of Casdoor: the user's name is
+// "preferred_username", their display name "name" and their avatar "picture". They are named
+// here rather than taken from casdoorsdk.StandardClaims, so that the tokens test its decoding.
+func (m *TokenMinter) getStandardClaims(user *casdoorsdk.User, tokenType string, nonce string, registered jwt.RegisteredClaims) (jwt.MapClaims, error) {
+	claims, err := toMapClaims(registered)
+	if err != nil {
+		return nil, err
+	}
+
+	claims["owner"] = user.Owner
+	claims["id"] = user.Id
+	standardClaims := map[string]string{
+		"preferred_username": user.Name,
+		"name":               user.DisplayName,
+		"picture":            user.Avatar,
+		"email":              user.Email,
+		"tokenType":          tokenType,
+		"nonce":              nonce,
+		"scope":              m.scope,
+		"azp":                m.clientId,
+	}
+	for name, value := range standardClaims {
+		// Casdoor omits the empty claims
+		if value != "" {
+			claims[name] = value
+		}
+	}
+	if user.EmailVerified {
+		claims["email_verified"] = true
+	}
+
+	for _, scope := range strings.Fields(m.scope) {
+		switch scope {
+		case "profile":
+			if user.Gender != "" {
+				claims["gender"] = user.Gender
+			}
+		case "phone":
+			if user.Phone != "" {
+				claims["phone_number"] = user.Phone
+				claims["phone_number_verified"] = true
+			}
+		case "address":
+			claims["address"] = map[string]interface{}{
+				"formatted":      "",
+				"street_address": strings.Join(user.Address, " "),
+				"locality":       "",
+				"region":         user.Region,
+				"postal_code":    "",
+				"country":        user.CountryCode,
+			}
+		}
+	}
+
+	return claims, nil
+}
+
+// getCustomClaims returns the claims of the "JWT-Custom" format: the registered claims, the
+// token's claims, of which "TokenType", and the token fields of the user.
+func (m *TokenMinter) getCustomClaims(user *casdoorsdk.User, tokenType string, nonce string, registered jwt.RegisteredClaims) (jwt.MapClaims, error) {
+	claims, err := toMapClaims(registered)
+	if err != nil {
+		return nil, err
+	}
+
+	claims["TokenType"] = tokenType
+	claims["scope"] = m.scope
+	claims["azp"] = m.clientId
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+
+	userType := reflect.TypeOf(*user)
+	userValue := reflect.ValueOf(*user)
+	for _, fieldName := range m.tokenFields {
+		field, ok := userType.FieldByName(fieldName)
+		if !ok {
+			return nil, fmt.Errorf("the token field %s is not a field of the user", fieldName)
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = fieldName
+		}
+		claims[name] = userValue.FieldByIndex(field.Index).Interface()
+	}
+
+	return toMapClaims(claims)
+}
+
+// toMapClaims returns the claims as they are encoded in the tokens.
+func toMapClaims(claims interface{}) (jwt.MapClaims, error) {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	var res jwt.MapClaims
+	err = json.Unmarshal(data, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
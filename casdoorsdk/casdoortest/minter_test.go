@@ -0,0 +1,201 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoortest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+)
+
+func newTestUser() *casdoorsdk.User {
+	return &casdoorsdk.User{
+		Owner:         "built-in",
+		Name:          "alice",
+		Id:            "alice-id",
+		DisplayName:   "Alice",
+		Avatar:        "https://example.com/alice.png",
+		Email:         "alice@example.com",
+		EmailVerified: true,
+		Phone:         "5550100",
+		Gender:        "female",
+		Address:       []string{"1 Main St"},
+		Password:      "secret",
+		Roles:         []*casdoorsdk.Role{{Owner: "built-in", Name: "admin"}},
+	}
+}
+
+func TestTokenMinterAlgorithms(t *testing.T) {
+	for _, algorithm := range SupportedAlgorithms {
+		minter, err := NewTokenMinter(algorithm)
+		if err != nil {
+			t.Fatalf("Failed to create the %s minter: %v", algorithm, err)
+		}
+		client := casdoorsdk.NewClientWithConf(minter.AuthConfig("built-in", "app-built-in"))
+
+		accessToken, err := minter.MintAccessToken(newTestUser(), nil)
+		if err != nil {
+			t.Fatalf("Failed to mint the %s access token: %v", algorithm, err)
+		}
+		claims, err := client.ParseJwtTokenWithOptions(accessToken, casdoorsdk.WithRejectRefreshTokens())
+		if err != nil {
+			t.Fatalf("Failed to parse the %s access token: %v", algorithm, err)
+		}
+		if claims.Name != "alice" || claims.Subject != "alice-id" || claims.Password != "" || len(claims.Roles) != 1 {
+			t.Errorf("Unexpected %s claims: %+v", algorithm, claims)
+		}
+
+		refreshToken, err := minter.MintRefreshToken(newTestUser(), nil)
+		if err != nil {
+			t.Fatalf("Failed to mint the %s refresh token: %v", algorithm, err)
+		}
+		_, err = client.ParseJwtTokenWithOptions(refreshToken, casdoorsdk.WithRejectRefreshTokens())
+		if !errors.Is(err, casdoorsdk.ErrRefreshTokenNotAllowed) {
+			t.Errorf("Expected ErrRefreshTokenNotAllowed for the %s refresh token, got %v", algorithm, err)
+		}
+
+		idToken, err := minter.MintIdToken(newTestUser(), "nonce", accessToken, nil)
+		if err != nil {
+			t.Fatalf("Failed to mint the %s ID token: %v", algorithm, err)
+		}
+		_, err = client.VerifyIDToken(context.Background(), idToken, accessToken, "", "nonce")
+		if err != nil {
+			t.Errorf("Failed to verify the %s ID token: %v", algorithm, err)
+		}
+	}
+
+	_, err := NewTokenMinter("HS256")
+	if err == nil {
+		t.Errorf("Expected an error for an unsupported algorithm")
+	}
+}
+
+func TestTokenMinterFormats(t *testing.T) {
+	user := newTestUser()
+
+	minter, err := NewTokenMinter(casdoorsdk.CertAlgorithmEs256, WithTokenFormat(TokenFormatJwtEmpty))
+	if err != nil {
+		t.Fatalf("Failed to create the minter: %v", err)
+	}
+	client := casdoorsdk.NewClientWithConf(minter.AuthConfig("built-in", "app-built-in"))
+	token, _ := minter.MintAccessToken(user, nil)
+	shortClaims, unmapped, err := casdoorsdk.ParseJwtTokenInto[casdoorsdk.ShortClaims](client, token)
+	if err != nil || shortClaims.Name != "alice" || shortClaims.Email != "alice@example.com" || len(unmapped) != 0 {
+		t.Errorf("Unexpected JWT-Empty claims: %+v, %v, %v", shortClaims, unmapped, err)
+	}
+
+	minter, err = NewTokenMinter(casdoorsdk.CertAlgorithmEs256, WithTokenFormat(TokenFormatJwtStandard), WithScope("openid profile phone address"))
+	if err != nil {
+		t.Fatalf("Failed to create the minter: %v", err)
+	}
+	client = casdoorsdk.NewClientWithConf(minter.AuthConfig("built-in", "app-built-in"))
+	token, _ = minter.MintAccessToken(user, nil)
+	// the claims are checked by their names, as in the tokens of Casdoor
+	standardClaims, _, err := casdoorsdk.ParseJwtTokenInto[map[string]interface{}](client, token)
+	if err != nil {
+		t.Fatalf("Failed to parse the JWT-Standard token: %v", err)
+	}
+	expected := map[string]interface{}{
+		"preferred_username":    "alice",
+		"name":                  "Alice",
+		"picture":               "https://example.com/alice.png",
+		"email":                 "alice@example.com",
+		"gender":                "female",
+		"phone_number":          "5550100",
+		"phone_number_verified": true,
+		"email_verified":        true,
+	}
+	for name, value := range expected {
+		if (*standardClaims)[name] != value {
+			t.Errorf("Expected the JWT-Standard claim %s to be %v, got %v", name, value, (*standardClaims)[name])
+		}
+	}
+	address, _ := (*standardClaims)["address"].(map[string]interface{})
+	if address["street_address"] != "1 Main St" {
+		t.Errorf("Unexpected JWT-Standard address: %v", (*standardClaims)["address"])
+	}
+	for _, name := range []string{"displayName", "avatar", "phone"} {
+		if _, ok := (*standardClaims)[name]; ok {
+			t.Errorf("Unexpected JWT-Standard claim %s", name)
+		}
+	}
+
+	minter, err = NewTokenMinter(casdoorsdk.CertAlgorithmRs256, WithTokenFormat(TokenFormatJwtCustom), WithTokenFields("Name", "DisplayName", "Roles"),
+		WithIssuer("https://door.example.com"), WithClientId("other-client"), WithExpireIn(time.Minute, time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to create the minter: %v", err)
+	}
+	client = casdoorsdk.NewClientWithConf(minter.AuthConfig("built-in", "app-built-in"))
+	type customClaims struct {
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+		TokenType   string `json:"TokenType"`
+	}
+	token, _ = minter.MintAccessToken(user, map[string]interface{}{"tenant": "acme", "azp": nil})
+	custom, unmapped, err := casdoorsdk.ParseJwtTokenInto[customClaims](client, token)
+	if err != nil {
+		t.Fatalf("Failed to parse the JWT-Custom token: %v", err)
+	}
+	if custom.Name != "alice" || custom.DisplayName != "Alice" || custom.TokenType != "access-token" {
+		t.Errorf("Unexpected JWT-Custom claims: %+v", custom)
+	}
+	if unmapped["tenant"] != "acme" || unmapped["roles"] == nil || unmapped["email"] != nil || unmapped["azp"] != nil || unmapped["aud"] == nil {
+		t.Errorf("Unexpected unmapped claims: %v", unmapped)
+	}
+	claims, err := client.ParseJwtTokenWithOptions(token)
+	if err != nil || !claims.ExpiresAt.Before(time.Now().Add(2*time.Minute)) {
+		t.Errorf("Unexpected expiry: %v", err)
+	}
+
+	// The overrides replace the claims
+	token, _ = minter.MintAccessToken(user, map[string]interface{}{"iss": "https://evil.example.com"})
+	_, err = client.ParseJwtTokenWithOptions(token)
+	if !errors.Is(err, casdoorsdk.ErrInvalidIssuer) {
+		t.Errorf("Expected ErrInvalidIssuer, got %v", err)
+	}
+
+	_, err = NewTokenMinter(casdoorsdk.CertAlgorithmRs256, WithTokenFormat("JWT-Unknown"))
+	if err == nil {
+		t.Errorf("Expected an error for an unsupported token format")
+	}
+}
+
+func TestTokenMinterJwks(t *testing.T) {
+	minter, err := NewTokenMinter(casdoorsdk.CertAlgorithmEs384)
+	if err != nil {
+		t.Fatalf("Failed to create the minter: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	minter.JwksHandler().ServeHTTP(w, httptest.NewRequest("GET", "/.well-known/jwks", nil))
+	var jwks casdoorsdk.JsonWebKeySet
+	err = json.NewDecoder(w.Body).Decode(&jwks)
+	if err != nil || len(jwks.Keys) != 1 {
+		t.Fatalf("Unexpected JWKS: %s, %v", w.Body.String(), err)
+	}
+
+	key := jwks.Keys[0]
+	if key.Kty != "EC" || key.Crv != "P-384" || key.Alg != "ES384" || key.Kid != minter.Cert.Name || len(key.X5c) != 1 {
+		t.Errorf("Unexpected JWK: %+v", key)
+	}
+	if _, err = key.PublicKey(); err != nil {
+		t.Errorf("Failed to get the public key of the JWK: %v", err)
+	}
+}
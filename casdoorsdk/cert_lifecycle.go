@@ -0,0 +1,469 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+)
+
+const (
+	CertAlgorithmRs256   = "RS256"
+	CertAlgorithmRs512   = "RS512"
+	CertAlgorithmEs256   = "ES256"
+	CertAlgorithmEs384   = "ES384"
+	CertAlgorithmEs512   = "ES512"
+	CertAlgorithmEd25519 = "Ed25519"
+)
+
+// isJwtCertAlgorithm reports whether the tokens signed with a cert of the crypto algorithm can
+// be verified by ParseJwtToken(), an empty algorithm is unknown and accepted.
+func isJwtCertAlgorithm(cryptoAlgorithm string) bool {
+	switch cryptoAlgorithm {
+	case "", CertAlgorithmRs256, CertAlgorithmRs512, CertAlgorithmEs256, CertAlgorithmEs384, CertAlgorithmEs512:
+		return true
+	default:
+		return false
+	}
+}
+
+// GenerateCert generates a key pair of the crypto algorithm and its self-signed X.509
+// certificate, valid for expireInYears, as a JWT cert to be added with AddCert(). The bitSize
+// is the size of the RSA keys, 4096 when 0, and is ignored for the other algorithms. The
+// Ed25519 certs are not for signing the tokens, as ParseJwtToken() can't verify them.
+func GenerateCert(name string, cryptoAlgorithm string, bitSize int, expireInYears int) (*Cert, error) {
+	if name == "" {
+		return nil, errors.New("GenerateCert() error: the name should not be empty")
+	}
+	if expireInYears <= 0 {
+		return nil, errors.New("GenerateCert() error: expireInYears should be positive")
+	}
+
+	var privateKey crypto.Signer
+	var privateKeyBlock *pem.Block
+	var err error
+	switch cryptoAlgorithm {
+	case CertAlgorithmRs256, CertAlgorithmRs512:
+		if bitSize == 0 {
+			bitSize = 4096
+		}
+		if bitSize < 2048 {
+			return nil, fmt.Errorf("GenerateCert() error: the RSA keys should have at least 2048 bits, got %d", bitSize)
+		}
+
+		var key *rsa.PrivateKey
+		key, err = rsa.GenerateKey(rand.Reader, bitSize)
+		if err != nil {
+			return nil, err
+		}
+		privateKey = key
+		privateKeyBlock = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	case CertAlgorithmEs256, CertAlgorithmEs384, CertAlgorithmEs512:
+		curve := map[string]elliptic.Curve{
+			CertAlgorithmEs256: elliptic.P256(),
+			CertAlgorithmEs384: elliptic.P384(),
+			CertAlgorithmEs512: elliptic.P521(),
+		}[cryptoAlgorithm]
+		bitSize = curve.Params().BitSize
+
+		var key *ecdsa.PrivateKey
+		key, err = ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		var der []byte
+		der, err = x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		privateKey = key
+		privateKeyBlock = &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+	case CertAlgorithmEd25519:
+		bitSize = 256
+
+		var key ed25519.PrivateKey
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		var der []byte
+		der, err = x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		privateKey = key
+		privateKeyBlock = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	default:
+		return nil, fmt.Errorf("GenerateCert() error: unsupported crypto algorithm %q", cryptoAlgorithm)
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             now,
+		NotAfter:              now.AddDate(expireInYears, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, privateKey.Public(), privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	cert := &Cert{
+		Name:            name,
+		CreatedTime:     GetCurrentTime(),
+		DisplayName:     name,
+		Scope:           "JWT",
+		Type:            "x509",
+		CryptoAlgorithm: cryptoAlgorithm,
+		BitSize:         bitSize,
+		ExpireInYears:   expireInYears,
+		ExpireTime:      template.NotAfter.Format(time.RFC3339),
+		Certificate:     string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		PrivateKey:      string(pem.EncodeToMemory(privateKeyBlock)),
+	}
+	return cert, nil
+}
+
+// AddGeneratedCert generates a cert with GenerateCert() and adds it to the client's organization.
+func (c *Client) AddGeneratedCert(name string, cryptoAlgorithm string, bitSize int, expireInYears int) (*Cert, error) {
+	cert, err := GenerateCert(name, cryptoAlgorithm, bitSize, expireInYears)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := c.AddCert(cert)
+	if err != nil {
+		return nil, err
+	}
+	if !affected {
+		return nil, fmt.Errorf("AddGeneratedCert() error: the cert %s was not added", name)
+	}
+
+	return cert, nil
+}
+
+// CertRotationState is the last completed step of a CertRotation.
+type CertRotationState string
+
+const (
+	CertRotationStaged    CertRotationState = "staged"
+	CertRotationSwitched  CertRotationState = "switched"
+	CertRotationCompleted CertRotationState = "completed"
+	CertRotationAborted   CertRotationState = "aborted"
+)
+
+// CertRotation is the replacement of the signing cert of an application, in three steps:
+//
+//  1. StageCertRotation() adds the new cert while the application still signs with the old
+//     one, so that the new Certificate can be given to the token verifiers first.
+//  2. SwitchCertRotation() makes the application sign its new tokens with the new cert.
+//  3. CompleteCertRotation() deletes the old cert once the tokens signed with it have expired.
+//
+// It can be stored between the steps, and AbortCertRotation() switches the application back.
+type CertRotation struct {
+	Application  string `json:"application"`
+	OldCert      string `json:"oldCert"`
+	OldCertOwner string `json:"oldCertOwner"`
+	NewCert      string `json:"newCert"`
+	NewCertOwner string `json:"newCertOwner"`
+	// CryptoAlgorithm is the crypto algorithm of the new cert.
+	CryptoAlgorithm string `json:"cryptoAlgorithm"`
+	// Certificate is the PEM of the new cert, for the verifiers of the application's tokens.
+	Certificate  string            `json:"certificate"`
+	State        CertRotationState `json:"state"`
+	SwitchedTime time.Time         `json:"switchedTime"`
+}
+
+func (r *CertRotation) checkStep(expected CertRotationState) error {
+	if r.State != expected {
+		return fmt.Errorf("the cert rotation of the application %s should be %s, got %s", r.Application, expected, r.State)
+	}
+
+	return nil
+}
+
+// findCert returns the cert of the name in the client's organization or, like Casdoor does for
+// the applications, among the global certs of "admin", or nil.
+func (c *Client) findCert(name string) (*Cert, error) {
+	cert, err := c.GetCert(name)
+	if err != nil || cert != nil {
+		return cert, err
+	}
+
+	return c.GetCert(getAdminId(name))
+}
+
+// StageCertRotation adds the new cert, made with GenerateCert(), for the application, whose
+// current cert is kept until SwitchCertRotation(). The new cert should be of a crypto algorithm
+// whose tokens ParseJwtToken() verifies, so not Ed25519.
+func (c *Client) StageCertRotation(applicationName string, newCert *Cert) (*CertRotation, error) {
+	if !isJwtCertAlgorithm(newCert.CryptoAlgorithm) {
+		return nil, fmt.Errorf("StageCertRotation() error: the tokens signed with the %s cert %s can't be verified", newCert.CryptoAlgorithm, newCert.Name)
+	}
+
+	application, err := c.GetApplication(applicationName)
+	if err != nil {
+		return nil, err
+	}
+	if application == nil {
+		return nil, fmt.Errorf("StageCertRotation() error: the application %s doesn't exist", applicationName)
+	}
+	if application.Cert == newCert.Name {
+		return nil, fmt.Errorf("StageCertRotation() error: the application %s already uses the cert %s", applicationName, newCert.Name)
+	}
+
+	rotation := &CertRotation{
+		Application:     applicationName,
+		OldCert:         application.Cert,
+		NewCert:         newCert.Name,
+		CryptoAlgorithm: newCert.CryptoAlgorithm,
+		Certificate:     newCert.Certificate,
+		State:           CertRotationStaged,
+	}
+	if application.Cert != "" {
+		oldCert, err := c.findCert(application.Cert)
+		if err != nil {
+			return nil, err
+		}
+		if oldCert != nil {
+			rotation.OldCertOwner = oldCert.Owner
+		}
+	}
+
+	affected, err := c.AddCert(newCert)
+	if err != nil {
+		return nil, err
+	}
+	if !affected {
+		return nil, fmt.Errorf("StageCertRotation() error: the cert %s was not added", newCert.Name)
+	}
+	rotation.NewCertOwner = newCert.Owner
+
+	return rotation, nil
+}
+
+// setApplicationCert sets the cert of the application of the rotation.
+func (c *Client) setApplicationCert(rotation *CertRotation, certName string) error {
+	application, err := c.GetApplication(rotation.Application)
+	if err != nil {
+		return err
+	}
+	if application == nil {
+		return fmt.Errorf("the application %s doesn't exist", rotation.Application)
+	}
+
+	application.Cert = certName
+	_, err = c.UpdateApplication(application)
+	return err
+}
+
+// SwitchCertRotation makes the application sign its tokens with the new cert. The tokens signed
+// with the old cert before are still valid until they expire.
+func (c *Client) SwitchCertRotation(rotation *CertRotation) error {
+	err := rotation.checkStep(CertRotationStaged)
+	if err != nil {
+		return err
+	}
+	if !isJwtCertAlgorithm(rotation.CryptoAlgorithm) {
+		return fmt.Errorf("SwitchCertRotation() error: the tokens signed with the %s cert %s can't be verified", rotation.CryptoAlgorithm, rotation.NewCert)
+	}
+
+	err = c.setApplicationCert(rotation, rotation.NewCert)
+	if err != nil {
+		return err
+	}
+
+	rotation.State = CertRotationSwitched
+	rotation.SwitchedTime = time.Now()
+	return nil
+}
+
+// CompleteCertRotation deletes the old cert when gracePeriod has passed since the switch, which
+// should be the longest lifetime of the application's tokens, see Application.ExpireInHours and
+// Application.RefreshExpireInHours. The old cert is kept when other applications still use it,
+// and the returned bool tells whether it was deleted.
+func (c *Client) CompleteCertRotation(rotation *CertRotation, gracePeriod time.Duration) (bool, error) {
+	err := rotation.checkStep(CertRotationSwitched)
+	if err != nil {
+		return false, err
+	}
+	if time.Since(rotation.SwitchedTime) < gracePeriod {
+		return false, fmt.Errorf("CompleteCertRotation() error: the tokens signed with the cert %s may be valid until %s", rotation.OldCert, rotation.SwitchedTime.Add(gracePeriod).Format(time.RFC3339))
+	}
+
+	deleted := false
+	if rotation.OldCert != "" && rotation.OldCertOwner != "" {
+		applications, err := c.GetApplications()
+		if err != nil {
+			return false, err
+		}
+
+		used := false
+		for _, application := range applications {
+			if application.Cert == rotation.OldCert {
+				used = true
+				break
+			}
+		}
+
+		if !used {
+			deleted, err = c.DeleteCert(&Cert{Owner: rotation.OldCertOwner, Name: rotation.OldCert})
+			if err != nil {
+				return false, err
+			}
+		}
+	}
+
+	rotation.State = CertRotationCompleted
+	return deleted, nil
+}
+
+// AbortCertRotation switches the application back to its old cert and deletes the new one.
+// After SwitchCertRotation(), the tokens signed with the new cert become invalid.
+func (c *Client) AbortCertRotation(rotation *CertRotation) error {
+	if rotation.State != CertRotationStaged && rotation.State != CertRotationSwitched {
+		return fmt.Errorf("the cert rotation of the application %s can't be aborted when %s", rotation.Application, rotation.State)
+	}
+
+	if rotation.State == CertRotationSwitched {
+		err := c.setApplicationCert(rotation, rotation.OldCert)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := c.DeleteCert(&Cert{Owner: rotation.NewCertOwner, Name: rotation.NewCert})
+	if err != nil {
+		return err
+	}
+
+	rotation.State = CertRotationAborted
+	return nil
+}
+
+// CertExpiry is when a cert expires. The times are zero when unknown.
+type CertExpiry struct {
+	Cert *Cert
+	// Err is why the expiry of the cert couldn't be parsed, set by GetExpiringCerts().
+	Err error
+	// NotAfter is the expiry of the X.509 certificate, parsed from Cert.Certificate.
+	NotAfter time.Time
+	// ExpireTime and DomainExpireTime are Cert.ExpireTime and Cert.DomainExpireTime, as stored
+	// by Casdoor, which may differ from the certificate after it was replaced.
+	ExpireTime       time.Time
+	DomainExpireTime time.Time
+}
+
+// GetExpiry returns the earliest of the expiry times, or zero when they are all unknown.
+func (e *CertExpiry) GetExpiry() time.Time {
+	var res time.Time
+	for _, t := range []time.Time{e.NotAfter, e.ExpireTime, e.DomainExpireTime} {
+		if !t.IsZero() && (res.IsZero() || t.Before(res)) {
+			res = t
+		}
+	}
+
+	return res
+}
+
+// GetCertExpiry parses the expiry times of the cert, the NotAfter of the first certificate of
+// its PEM chain, and its ExpireTime and DomainExpireTime in RFC 3339.
+func GetCertExpiry(cert *Cert) (*CertExpiry, error) {
+	res := &CertExpiry{Cert: cert}
+
+	if cert.Certificate != "" {
+		block, _ := pem.Decode([]byte(cert.Certificate))
+		if block == nil || block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("GetCertExpiry() error: the cert %s has no PEM certificate", cert.Name)
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("GetCertExpiry() error: the cert %s has an invalid certificate: %w", cert.Name, err)
+		}
+		res.NotAfter = certificate.NotAfter
+	}
+
+	var err error
+	if cert.ExpireTime != "" {
+		res.ExpireTime, err = time.Parse(time.RFC3339, cert.ExpireTime)
+		if err != nil {
+			return nil, fmt.Errorf("GetCertExpiry() error: the cert %s has an invalid expireTime: %w", cert.Name, err)
+		}
+	}
+	if cert.DomainExpireTime != "" {
+		res.DomainExpireTime, err = time.Parse(time.RFC3339, cert.DomainExpireTime)
+		if err != nil {
+			return nil, fmt.Errorf("GetCertExpiry() error: the cert %s has an invalid domainExpireTime: %w", cert.Name, err)
+		}
+	}
+
+	return res, nil
+}
+
+// GetExpiringCerts returns the certs of the client's organization that expire within the
+// duration, or have expired, the soonest first. The certs whose expiry can't be parsed come
+// first, with their Err set, so that one broken cert doesn't hide the others.
+func (c *Client) GetExpiringCerts(within time.Duration) ([]*CertExpiry, error) {
+	certs, err := c.GetCerts()
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(within)
+	var res []*CertExpiry
+	for _, cert := range certs {
+		expiry, err := GetCertExpiry(cert)
+		if err != nil {
+			res = append(res, &CertExpiry{Cert: cert, Err: err})
+			continue
+		}
+
+		t := expiry.GetExpiry()
+		if !t.IsZero() && t.Before(deadline) {
+			res = append(res, expiry)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if (res[i].Err != nil) != (res[j].Err != nil) {
+			return res[i].Err != nil
+		}
+		return res[i].GetExpiry().Before(res[j].GetExpiry())
+	})
+	return res, nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import "time"
+
+func AddGeneratedCert(name string, cryptoAlgorithm string, bitSize int, expireInYears int) (*Cert, error) {
+	return globalClient.AddGeneratedCert(name, cryptoAlgorithm, bitSize, expireInYears)
+}
+
+func StageCertRotation(applicationName string, newCert *Cert) (*CertRotation, error) {
+	return globalClient.StageCertRotation(applicationName, newCert)
+}
+
+func SwitchCertRotation(rotation *CertRotation) error {
+	return globalClient.SwitchCertRotation(rotation)
+}
+
+func CompleteCertRotation(rotation *CertRotation, gracePeriod time.Duration) (bool, error) {
+	return globalClient.CompleteCertRotation(rotation, gracePeriod)
+}
+
+func AbortCertRotation(rotation *CertRotation) error {
+	return globalClient.AbortCertRotation(rotation)
+}
+
+func GetExpiringCerts(within time.Duration) ([]*CertExpiry, error) {
+	return globalClient.GetExpiringCerts(within)
+}
@@ -0,0 +1,294 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestGenerateCert(t *testing.T) {
+	for _, algorithm := range []string{CertAlgorithmRs256, CertAlgorithmRs512, CertAlgorithmEs256, CertAlgorithmEs384, CertAlgorithmEs512, CertAlgorithmEd25519} {
+		cert, err := GenerateCert("cert-"+algorithm, algorithm, 2048, 2)
+		if err != nil {
+			t.Fatalf("Failed to generate the %s cert: %v", algorithm, err)
+		}
+
+		expiry, err := GetCertExpiry(cert)
+		if err != nil {
+			t.Fatalf("Failed to get the expiry of the %s cert: %v", algorithm, err)
+		}
+		if !expiry.NotAfter.Equal(expiry.ExpireTime) || expiry.NotAfter.Before(time.Now().AddDate(2, 0, -1)) {
+			t.Errorf("Unexpected expiry of the %s cert: %+v", algorithm, expiry)
+		}
+		if block, _ := pem.Decode([]byte(cert.PrivateKey)); block == nil {
+			t.Errorf("Unexpected private key of the %s cert: %s", algorithm, cert.PrivateKey)
+		}
+	}
+
+	// The tokens signed with the private keys are verified with the certificates
+	for _, test := range []struct {
+		algorithm string
+		method    jwt.SigningMethod
+	}{
+		{CertAlgorithmRs256, jwt.SigningMethodRS256},
+		{CertAlgorithmEs256, jwt.SigningMethodES256},
+		{CertAlgorithmEs512, jwt.SigningMethodES512},
+	} {
+		cert, err := GenerateCert("cert", test.algorithm, 0, 1)
+		if err != nil {
+			t.Fatalf("Failed to generate the %s cert: %v", test.algorithm, err)
+		}
+
+		var key interface{}
+		if test.method == jwt.SigningMethodRS256 {
+			key, err = jwt.ParseRSAPrivateKeyFromPEM([]byte(cert.PrivateKey))
+		} else {
+			key, err = jwt.ParseECPrivateKeyFromPEM([]byte(cert.PrivateKey))
+		}
+		if err != nil {
+			t.Fatalf("Failed to parse the %s private key: %v", test.algorithm, err)
+		}
+
+		token, err := jwt.NewWithClaims(test.method, newTestClaims("http://localhost:8000", "client-id")).SignedString(key)
+		if err != nil {
+			t.Fatalf("Failed to sign the %s token: %v", test.algorithm, err)
+		}
+		client := NewClient("http://localhost:8000", "client-id", "client-secret", cert.Certificate, "built-in", "app-built-in")
+		_, err = client.ParseJwtTokenWithOptions(token)
+		if err != nil {
+			t.Errorf("Failed to verify the %s token: %v", test.algorithm, err)
+		}
+	}
+
+	for _, args := range []struct {
+		algorithm     string
+		bitSize       int
+		expireInYears int
+	}{
+		{"HS256", 0, 1},
+		{CertAlgorithmRs256, 1024, 1},
+		{CertAlgorithmEs256, 0, 0},
+	} {
+		_, err := GenerateCert("cert", args.algorithm, args.bitSize, args.expireInYears)
+		if err == nil {
+			t.Errorf("Expected an error for %+v", args)
+		}
+	}
+}
+
+// newTestCertServer returns a server with the cert and application APIs of Casdoor, where the
+// applications are "app-built-in", which uses the global "cert-built-in", and "app-other".
+func newTestCertServer(t *testing.T) (*httptest.Server, map[string]*Cert, map[string]*Application) {
+	var mutex sync.Mutex
+	certs := map[string]*Cert{
+		"admin/cert-built-in": {Owner: "admin", Name: "cert-built-in"},
+	}
+	applications := map[string]*Application{
+		"admin/app-built-in": {Owner: "admin", Name: "app-built-in", Cert: "cert-built-in"},
+		"admin/app-other":    {Owner: "admin", Name: "app-other", Cert: "cert-other"},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		id := r.URL.Query().Get("id")
+		response := Response{Status: "ok", Data: "Affected"}
+		switch r.URL.Path {
+		case "/api/get-certs":
+			var res []*Cert
+			for _, cert := range certs {
+				if cert.Owner == r.URL.Query().Get("owner") {
+					res = append(res, cert)
+				}
+			}
+			response.Data = res
+		case "/api/get-cert":
+			response.Data = certs[id]
+		case "/api/add-cert":
+			var cert Cert
+			_ = json.NewDecoder(r.Body).Decode(&cert)
+			certs[id] = &cert
+		case "/api/delete-cert":
+			if certs[id] == nil {
+				response.Data = "Unaffected"
+			}
+			delete(certs, id)
+		case "/api/get-applications":
+			var res []*Application
+			for _, application := range applications {
+				res = append(res, application)
+			}
+			response.Data = res
+		case "/api/get-application":
+			response.Data = applications[id]
+		case "/api/update-application":
+			var application Application
+			_ = json.NewDecoder(r.Body).Decode(&application)
+			applications[id] = &application
+		default:
+			t.Errorf("Unexpected request: %s", r.URL.Path)
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+
+	return server, certs, applications
+}
+
+func TestCertRotation(t *testing.T) {
+	server, certs, applications := newTestCertServer(t)
+	defer server.Close()
+	client := NewClient(server.URL, "client-id", "client-secret", "", "built-in", "app-built-in")
+
+	newCert, err := GenerateCert("cert-2026", CertAlgorithmEs256, 0, 1)
+	if err != nil {
+		t.Fatalf("Failed to generate the cert: %v", err)
+	}
+	rotation, err := client.StageCertRotation("app-built-in", newCert)
+	if err != nil {
+		t.Fatalf("Failed to stage the rotation: %v", err)
+	}
+	if rotation.OldCert != "cert-built-in" || rotation.OldCertOwner != "admin" || rotation.NewCertOwner != "built-in" || rotation.Certificate != newCert.Certificate {
+		t.Errorf("Unexpected rotation: %+v", rotation)
+	}
+	if certs["built-in/cert-2026"] == nil || applications["admin/app-built-in"].Cert != "cert-built-in" {
+		t.Errorf("Expected the new cert to be added without switching the application")
+	}
+
+	// The steps are in order
+	_, err = client.CompleteCertRotation(rotation, 0)
+	if err == nil {
+		t.Errorf("Expected an error when completing a staged rotation")
+	}
+
+	err = client.SwitchCertRotation(rotation)
+	if err != nil {
+		t.Fatalf("Failed to switch the rotation: %v", err)
+	}
+	if applications["admin/app-built-in"].Cert != "cert-2026" || rotation.State != CertRotationSwitched {
+		t.Errorf("Expected the application to use the new cert")
+	}
+
+	_, err = client.CompleteCertRotation(rotation, time.Hour)
+	if err == nil {
+		t.Errorf("Expected an error before the end of the grace period")
+	}
+	deleted, err := client.CompleteCertRotation(rotation, 0)
+	if err != nil || !deleted || certs["admin/cert-built-in"] != nil || rotation.State != CertRotationCompleted {
+		t.Errorf("Expected the old cert to be deleted, got %v, %v", deleted, err)
+	}
+
+	// The tokens signed with an Ed25519 cert can't be verified
+	edCert, err := GenerateCert("cert-ed25519", CertAlgorithmEd25519, 0, 1)
+	if err != nil {
+		t.Fatalf("Failed to generate the cert: %v", err)
+	}
+	_, err = client.StageCertRotation("app-built-in", edCert)
+	if err == nil || certs["built-in/cert-ed25519"] != nil {
+		t.Errorf("Expected the Ed25519 cert to be refused")
+	}
+	err = client.SwitchCertRotation(&CertRotation{Application: "app-built-in", NewCert: "cert-ed25519", CryptoAlgorithm: CertAlgorithmEd25519, State: CertRotationStaged})
+	if err == nil {
+		t.Errorf("Expected the switch to an Ed25519 cert to be refused")
+	}
+
+	// The old cert is kept when another application uses it, and an aborted rotation switches back
+	certs["built-in/cert-other"] = &Cert{Owner: "built-in", Name: "cert-other"}
+	applications["admin/app-built-in"].Cert = "cert-other"
+	rotation, err = client.StageCertRotation("app-built-in", &Cert{Name: "cert-2027"})
+	if err != nil {
+		t.Fatalf("Failed to stage the rotation: %v", err)
+	}
+	err = client.SwitchCertRotation(rotation)
+	if err != nil {
+		t.Fatalf("Failed to switch the rotation: %v", err)
+	}
+	data, _ := json.Marshal(rotation)
+	rotation = &CertRotation{}
+	_ = json.Unmarshal(data, rotation)
+
+	err = client.AbortCertRotation(rotation)
+	if err != nil || applications["admin/app-built-in"].Cert != "cert-other" || certs["built-in/cert-2027"] != nil {
+		t.Errorf("Expected the rotation to be aborted: %v", err)
+	}
+
+	applications["admin/app-built-in"].Cert = "cert-2027"
+	rotation.State = CertRotationSwitched
+	deleted, err = client.CompleteCertRotation(rotation, 0)
+	if err != nil || deleted || certs["built-in/cert-other"] == nil {
+		t.Errorf("Expected the old cert to be kept for app-other, got %v, %v", deleted, err)
+	}
+}
+
+func TestGetExpiringCerts(t *testing.T) {
+	server, certs, _ := newTestCertServer(t)
+	defer server.Close()
+	client := NewClient(server.URL, "client-id", "client-secret", "", "built-in", "app-built-in")
+
+	// ExpireTime is wrong after the certificate was replaced, the certificate is trusted first
+	soon, err := GenerateCert("cert-soon", CertAlgorithmEs256, 0, 1)
+	if err != nil {
+		t.Fatalf("Failed to generate the cert: %v", err)
+	}
+	soon.Owner = "built-in"
+	soon.ExpireTime = time.Now().AddDate(5, 0, 0).Format(time.RFC3339)
+	certs["built-in/cert-soon"] = soon
+
+	domain, err := GenerateCert("cert-domain", CertAlgorithmEs256, 0, 10)
+	if err != nil {
+		t.Fatalf("Failed to generate the cert: %v", err)
+	}
+	domain.Owner = "built-in"
+	domain.DomainExpireTime = time.Now().Add(24 * time.Hour).Format(time.RFC3339)
+	certs["built-in/cert-domain"] = domain
+
+	later, err := GenerateCert("cert-later", CertAlgorithmEs256, 0, 10)
+	if err != nil {
+		t.Fatalf("Failed to generate the cert: %v", err)
+	}
+	later.Owner = "built-in"
+	certs["built-in/cert-later"] = later
+	certs["built-in/cert-empty"] = &Cert{Owner: "built-in", Name: "cert-empty"}
+
+	expiring, err := client.GetExpiringCerts(400 * 24 * time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to get the expiring certs: %v", err)
+	}
+	if len(expiring) != 2 || expiring[0].Cert.Name != "cert-domain" || expiring[1].Cert.Name != "cert-soon" {
+		t.Fatalf("Unexpected expiring certs: %+v", expiring)
+	}
+	if !expiring[1].GetExpiry().Equal(expiring[1].NotAfter) {
+		t.Errorf("Expected the expiry of the certificate, got %s", expiring[1].GetExpiry())
+	}
+
+	// An invalid cert is reported with the others
+	certs["built-in/cert-broken"] = &Cert{Owner: "built-in", Name: "cert-broken", Certificate: "not a PEM"}
+	expiring, err = client.GetExpiringCerts(400 * 24 * time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to get the expiring certs: %v", err)
+	}
+	if len(expiring) != 3 || expiring[0].Cert.Name != "cert-broken" || expiring[0].Err == nil || expiring[1].Cert.Name != "cert-domain" {
+		t.Errorf("Expected the invalid cert to be reported first, got %+v", expiring)
+	}
+}
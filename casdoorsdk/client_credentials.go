@@ -0,0 +1,123 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const GrantTypeClientCredentials = "client_credentials"
+
+// ErrGrantTypeNotEnabled is returned when Casdoor rejects a grant type because it is not
+// enabled in the application's "Grant types" (Application.GrantTypes).
+var ErrGrantTypeNotEnabled = errors.New("the grant type is not enabled in the application")
+
+func (c *Client) getClientCredentialsConfig(scopes []string) *clientcredentials.Config {
+	config := c.getOAuthConfig("access_token")
+	return &clientcredentials.Config{
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		TokenURL:     config.Endpoint.TokenURL,
+		Scopes:       scopes,
+		AuthStyle:    config.Endpoint.AuthStyle,
+	}
+}
+
+// checkGrantType converts the "unsupported_grant_type" and "unauthorized_client" errors of the
+// token endpoint into ErrGrantTypeNotEnabled, keeping the server's description.
+func checkGrantType(grantType string, err error) error {
+	var errorCode, errorDescription string
+	var retrieveErr *oauth2.RetrieveError
+	var oauthErr *OAuthError
+	if errors.As(err, &retrieveErr) {
+		errorCode, errorDescription = retrieveErr.ErrorCode, retrieveErr.ErrorDescription
+	} else if errors.As(err, &oauthErr) {
+		errorCode, errorDescription = oauthErr.ErrorCode, oauthErr.ErrorDescription
+	} else {
+		return err
+	}
+
+	if errorCode != "unsupported_grant_type" && errorCode != "unauthorized_client" {
+		return err
+	}
+
+	return fmt.Errorf("%w: %q, add it to the application's \"Grant types\" in Casdoor (%s)", ErrGrantTypeNotEnabled, grantType, errorDescription)
+}
+
+// GetOAuthTokenByClientCredentials gets an OAuth token for the application itself via the
+// "client_credentials" grant type of OAuth 2.0, which is meant for service-to-service calls
+// where no user is involved. The "client_credentials" grant type must be enabled in the
+// application's "Grant types" in Casdoor, otherwise ErrGrantTypeNotEnabled is returned.
+// The ctx can carry a custom *http.Client with the oauth2.HTTPClient key.
+func (c *Client) GetOAuthTokenByClientCredentials(ctx context.Context, scopes ...string) (*oauth2.Token, error) {
+	return c.GetOAuthTokenByClientCredentialsWithOptions(ctx, scopes)
+}
+
+// GetOAuthTokenByClientCredentialsWithOptions is like GetOAuthTokenByClientCredentials() but
+// accepts the OAuthOption functions, e.g., WithResources() to get a token for a given API.
+func (c *Client) GetOAuthTokenByClientCredentialsWithOptions(ctx context.Context, scopes []string, opts ...OAuthOption) (*oauth2.Token, error) {
+	options := getOAuthOptions(opts...)
+	err := checkOAuthOptions("GetOAuthTokenByClientCredentialsWithOptions", options, "WithScopes()")
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err = c.withOAuthContext(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes = append(append([]string{}, scopes...), options.scopes...)
+	token, err := checkOAuthToken(c.getClientCredentialsConfig(scopes).Token(ctx))
+	if err != nil {
+		return nil, checkGrantType(GrantTypeClientCredentials, err)
+	}
+
+	return checkTokenResources(token, options.resources)
+}
+
+// clientCredentialsTokenSource gets a new token on every call, it is meant to be wrapped
+// in oauth2.ReuseTokenSource().
+type clientCredentialsTokenSource struct {
+	ctx    context.Context
+	client *Client
+	scopes []string
+}
+
+func (s *clientCredentialsTokenSource) Token() (*oauth2.Token, error) {
+	return s.client.GetOAuthTokenByClientCredentials(s.ctx, s.scopes...)
+}
+
+// ClientCredentialsTokenSource returns an oauth2.TokenSource of the application's tokens got
+// by GetOAuthTokenByClientCredentials(). The token is cached and a new one is requested only
+// when it expires, so it is safe and cheap to call Token() before every request, e.g., via
+// oauth2.NewClient():
+//
+//	httpClient := oauth2.NewClient(ctx, client.ClientCredentialsTokenSource(ctx, "read"))
+//	resp, err := httpClient.Get("https://api.example.com/resource")
+//
+// The ctx is used for all the token requests, so it should not be a per-request context.
+func (c *Client) ClientCredentialsTokenSource(ctx context.Context, scopes ...string) oauth2.TokenSource {
+	return oauth2.ReuseTokenSource(nil, &clientCredentialsTokenSource{
+		ctx:    ctx,
+		client: c,
+		scopes: scopes,
+	})
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientCredentials(t *testing.T) {
+	enabled := true
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.Header().Set("Content-Type", "application/json")
+		if r.FormValue("grant_type") != GrantTypeClientCredentials || r.FormValue("client_id") != "client-id" || r.FormValue("client_secret") != "client-secret" {
+			t.Errorf("Unexpected token request: %v", r.Form)
+		}
+
+		if !enabled {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"error":             "unsupported_grant_type",
+				"error_description": "grant_type: client_credentials is not supported in this application",
+			})
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "app-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"scope":        r.FormValue("scope"),
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "client-id", "client-secret", "", "built-in", "app-built-in")
+	ctx := context.Background()
+
+	token, err := client.GetOAuthTokenByClientCredentials(ctx, "read", "write")
+	if err != nil {
+		t.Fatalf("Failed to get token: %v", err)
+	}
+	if token.AccessToken != "app-token" || token.Extra("scope") != "read write" {
+		t.Fatalf("Token mismatch: %v", token)
+	}
+
+	// The token source caches the token until it expires
+	tokenSource := client.ClientCredentialsTokenSource(ctx)
+	for i := 0; i < 3; i++ {
+		_, err = tokenSource.Token()
+		if err != nil {
+			t.Fatalf("Failed to get token: %v", err)
+		}
+	}
+	if requestCount != 2 {
+		t.Fatalf("Expected the token to be cached, got %d requests", requestCount)
+	}
+
+	// WithScopes() adds scopes, the options of the sign-in URL are rejected
+	token, err = client.GetOAuthTokenByClientCredentialsWithOptions(ctx, []string{"read"}, WithScopes("write"))
+	if err != nil || token.Extra("scope") != "read write" {
+		t.Fatalf("Expected the scopes of WithScopes(), got %v, %v", token, err)
+	}
+	_, err = client.GetOAuthTokenByClientCredentialsWithOptions(ctx, nil, WithNonce("nonce"))
+	if err == nil {
+		t.Errorf("Expected an error for WithNonce()")
+	}
+	for _, opt := range []OAuthOption{WithScopes("read"), WithState("state"), WithNonce("nonce")} {
+		_, err = client.RefreshOAuthToken("refresh-token", opt)
+		if err == nil {
+			t.Errorf("Expected an error for an option that the refresh token request doesn't send")
+		}
+	}
+
+	enabled = false
+	_, err = client.GetOAuthTokenByClientCredentials(ctx)
+	if !errors.Is(err, ErrGrantTypeNotEnabled) {
+		t.Fatalf("Expected ErrGrantTypeNotEnabled, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const GrantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+
+var (
+	ErrDeviceAccessDenied = errors.New("the user denied the device authorization request")
+	ErrDeviceCodeExpired  = errors.New("the device code has expired")
+)
+
+// WithPollInterval sets the interval between two token requests of PollDeviceToken(), it
+// should be the Interval returned by StartDeviceAuthorization(). The default is 5 seconds.
+func WithPollInterval(interval time.Duration) OAuthOption {
+	return func(opts *oauthOptions) {
+		opts.pollInterval = interval
+	}
+}
+
+func (c *Client) getDeviceOAuthConfig(scopes []string) oauth2.Config {
+	config := c.getOAuthConfig("access_token")
+	config.Endpoint.DeviceAuthURL = fmt.Sprintf("%s/api/device-auth", c.Endpoint)
+	config.Scopes = scopes
+	return config
+}
+
+// StartDeviceAuthorization starts the "Device Authorization Grant" of RFC 8628, which lets
+// devices that can't open a browser, like CLIs and TVs, sign in a user. The user opens the
+// returned VerificationURI on another device and enters the UserCode (or opens the
+// VerificationURIComplete, which already contains it), meanwhile the device calls
+// PollDeviceToken() with the returned DeviceCode until the user has signed in:
+//
+//	auth, err := client.StartDeviceAuthorization(ctx, []string{"openid", "profile"})
+//	fmt.Printf("Open %s and enter %s\n", auth.VerificationURI, auth.UserCode)
+//	ctx, cancel := context.WithDeadline(ctx, auth.Expiry)
+//	defer cancel()
+//	token, err := client.PollDeviceToken(ctx, auth.DeviceCode,
+//		casdoorsdk.WithPollInterval(time.Duration(auth.Interval)*time.Second))
+//
+// The "urn:ietf:params:oauth:grant-type:device_code" grant type must be enabled in the
+// application's "Grant types" in Casdoor.
+func (c *Client) StartDeviceAuthorization(ctx context.Context, scopes []string, opts ...OAuthOption) (*oauth2.DeviceAuthResponse, error) {
+	options := getOAuthOptions(opts...)
+	err := checkOAuthOptions("StartDeviceAuthorization", options, "WithScopes()")
+	if err != nil {
+		return nil, err
+	}
+
+	config := c.getDeviceOAuthConfig(append(append([]string{}, scopes...), options.scopes...))
+	ctx, err = c.withOAuthContext(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceAuth, err := config.DeviceAuth(ctx)
+	if err != nil {
+		return nil, checkGrantType(GrantTypeDeviceCode, err)
+	}
+
+	return deviceAuth, nil
+}
+
+// PollDeviceToken polls the token endpoint with the device code returned by
+// StartDeviceAuthorization() until the user approves or denies the request. The
+// "authorization_pending" and "slow_down" answers are handled by waiting, the latter also
+// increases the interval by 5 seconds as required by RFC 8628. A denied request returns
+// ErrDeviceAccessDenied and an expired device code returns ErrDeviceCodeExpired.
+// The polling also stops when the ctx is done, so give it the deadline of the device code.
+func (c *Client) PollDeviceToken(ctx context.Context, deviceCode string, opts ...OAuthOption) (*oauth2.Token, error) {
+	if deviceCode == "" {
+		return nil, errors.New("PollDeviceToken() error: the deviceCode should not be empty")
+	}
+
+	options := getOAuthOptions(opts...)
+	err := checkOAuthOptions("PollDeviceToken", options)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err = c.withOAuthContext(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceAuth := &oauth2.DeviceAuthResponse{
+		DeviceCode: deviceCode,
+		Interval:   int64((options.pollInterval + time.Second - 1) / time.Second),
+	}
+
+	config := c.getDeviceOAuthConfig(nil)
+	token, err := checkOAuthToken(config.DeviceAccessToken(ctx, deviceAuth))
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) {
+			switch retrieveErr.ErrorCode {
+			case "access_denied":
+				return nil, ErrDeviceAccessDenied
+			case "expired_token":
+				return nil, ErrDeviceCodeExpired
+			}
+		}
+
+		return nil, checkGrantType(GrantTypeDeviceCode, err)
+	}
+
+	return checkTokenResources(token, options.resources)
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeviceAuthorization(t *testing.T) {
+	pollCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/device-auth":
+			if r.FormValue("client_id") != "client-id" || r.FormValue("scope") != "openid profile" {
+				t.Errorf("Unexpected device authorization request: %v", r.Form)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"device_code":               "device-code",
+				"user_code":                 "ABCD-EFGH",
+				"verification_uri":          "http://localhost:8000/login/device",
+				"verification_uri_complete": "http://localhost:8000/login/device?user_code=ABCD-EFGH",
+				"expires_in":                120,
+				"interval":                  1,
+			})
+		case "/api/login/oauth/access_token":
+			if r.FormValue("grant_type") != GrantTypeDeviceCode {
+				t.Errorf("Unexpected grant type: %s", r.FormValue("grant_type"))
+			}
+			pollCount++
+			switch r.FormValue("device_code") {
+			case "device-code":
+				if pollCount == 1 {
+					w.WriteHeader(http.StatusBadRequest)
+					_ = json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"access_token": "device-token",
+					"token_type":   "Bearer",
+					"expires_in":   3600,
+				})
+			case "denied-code":
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "access_denied"})
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "expired_token"})
+			}
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "client-id", "client-secret", "", "built-in", "app-built-in")
+	ctx := context.Background()
+
+	auth, err := client.StartDeviceAuthorization(ctx, []string{"openid", "profile"})
+	if err != nil {
+		t.Fatalf("Failed to start device authorization: %v", err)
+	}
+	if auth.UserCode != "ABCD-EFGH" || auth.VerificationURIComplete == "" || auth.Interval != 1 {
+		t.Fatalf("Device authorization mismatch: %+v", auth)
+	}
+
+	pollInterval := WithPollInterval(time.Duration(auth.Interval) * time.Second)
+	token, err := client.PollDeviceToken(ctx, auth.DeviceCode, pollInterval)
+	if err != nil {
+		t.Fatalf("Failed to poll device token: %v", err)
+	}
+	if token.AccessToken != "device-token" || pollCount != 2 {
+		t.Fatalf("Device token mismatch: %s after %d polls", token.AccessToken, pollCount)
+	}
+
+	_, err = client.PollDeviceToken(ctx, "denied-code", pollInterval)
+	if !errors.Is(err, ErrDeviceAccessDenied) {
+		t.Fatalf("Expected ErrDeviceAccessDenied, got %v", err)
+	}
+
+	_, err = client.PollDeviceToken(ctx, "expired-code", pollInterval)
+	if !errors.Is(err, ErrDeviceCodeExpired) {
+		t.Fatalf("Expected ErrDeviceCodeExpired, got %v", err)
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// DPoPSigner creates the DPoP proofs of RFC 9449 ("OAuth 2.0 Demonstrating Proof of
+// Possession"), which bind the tokens issued by Casdoor to the signer's key, so that a
+// stolen token can't be used without the key. It also remembers the nonces provided by
+// the servers via the "DPoP-Nonce" header. It is safe for concurrent use.
+// Use NewDPoPSigner() to get one and WithDPoP() to bind it to a client.
+type DPoPSigner struct {
+	key    crypto.Signer
+	method jwt.SigningMethod
+	jwk    *JsonWebKey
+
+	mu     sync.Mutex
+	nonces map[string]string
+}
+
+// NewDPoPSigner returns a DPoPSigner that signs the proofs with the key, which is an
+// *ecdsa.PrivateKey (P-256, P-384 or P-521) or an ed25519.PrivateKey. The key should be
+// kept for the lifetime of the tokens, as they are bound to it.
+func NewDPoPSigner(key crypto.Signer) (*DPoPSigner, error) {
+	var method jwt.SigningMethod
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			method = jwt.SigningMethodES256
+		case elliptic.P384():
+			method = jwt.SigningMethodES384
+		case elliptic.P521():
+			method = jwt.SigningMethodES512
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", k.Curve.Params().Name)
+		}
+	case ed25519.PrivateKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported DPoP key type: %T", key)
+	}
+
+	jwk, err := NewJsonWebKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	return &DPoPSigner{
+		key:    key,
+		method: method,
+		jwk:    jwk,
+		nonces: map[string]string{},
+	}, nil
+}
+
+// Thumbprint returns the JWK thumbprint of the signer's public key, which is the "jkt" of
+// the tokens bound to it, see Claims.Cnf.
+func (s *DPoPSigner) Thumbprint() (string, error) {
+	return s.jwk.Thumbprint()
+}
+
+// Proof returns a DPoP proof for an HTTP request with the given method and URL. The
+// accessToken is the token sent along with the proof to a resource server, it is empty
+// for the requests to the token endpoint.
+func (s *DPoPSigner) Proof(method string, requestUrl string, accessToken string) (string, error) {
+	htu, err := getDPoPHtu(requestUrl)
+	if err != nil {
+		return "", err
+	}
+
+	jti := make([]byte, 16)
+	_, err = rand.Read(jti)
+	if err != nil {
+		return "", err
+	}
+
+	claims := jwt.MapClaims{
+		"jti": base64.RawURLEncoding.EncodeToString(jti),
+		"htm": method,
+		"htu": htu,
+		"iat": time.Now().Unix(),
+	}
+	if accessToken != "" {
+		claims["ath"] = getDPoPAth(accessToken)
+	}
+	if nonce := s.getNonce(requestUrl); nonce != "" {
+		claims["nonce"] = nonce
+	}
+
+	token := jwt.NewWithClaims(s.method, claims)
+	token.Header["typ"] = "dpop+jwt"
+	token.Header["jwk"] = s.jwk
+	return token.SignedString(s.key)
+}
+
+func (s *DPoPSigner) getNonce(requestUrl string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.nonces[getOrigin(requestUrl)]
+}
+
+// saveNonce remembers the nonce sent by the server in the "DPoP-Nonce" header, and reports
+// whether it differs from the one used so far.
+func (s *DPoPSigner) saveNonce(requestUrl string, header http.Header) bool {
+	nonce := header.Get("DPoP-Nonce")
+	if nonce == "" {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	origin := getOrigin(requestUrl)
+	if s.nonces[origin] == nonce {
+		return false
+	}
+
+	s.nonces[origin] = nonce
+	return true
+}
+
+// setHeaders sets the "Authorization: DPoP" and "DPoP" headers of a request to a resource server.
+func (s *DPoPSigner) setHeaders(req *http.Request, accessToken string) error {
+	proof, err := s.Proof(req.Method, req.URL.String(), accessToken)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "DPoP "+accessToken)
+	req.Header.Set("DPoP", proof)
+	return nil
+}
+
+// isNonceChallenge reports whether the response asks for a DPoP proof with a new nonce,
+// i.e., it is a "use_dpop_nonce" error of the token endpoint (400) or of a resource server (401).
+func (s *DPoPSigner) isNonceChallenge(req *http.Request, resp *http.Response) bool {
+	if resp.StatusCode != http.StatusBadRequest && resp.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+
+	return s.saveNonce(req.URL.String(), resp.Header)
+}
+
+// dpopTransport adds a DPoP proof to the requests sent to the token endpoint, retrying once
+// when the server asks for a nonce.
+type dpopTransport struct {
+	signer *DPoPSigner
+	base   http.RoundTripper
+}
+
+func (t *dpopTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	dpopReq, err := t.newRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(dpopReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if !t.signer.isNonceChallenge(req, resp) {
+		t.signer.saveNonce(req.URL.String(), resp.Header)
+		return resp, nil
+	}
+
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	err = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	dpopReq, err = t.newRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetBody != nil {
+		dpopReq.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return t.base.RoundTrip(dpopReq)
+}
+
+// newRequest returns a copy of the request with a DPoP proof, as a RoundTripper must not
+// modify the original request.
+func (t *dpopTransport) newRequest(req *http.Request) (*http.Request, error) {
+	proof, err := t.signer.Proof(req.Method, req.URL.String(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	dpopReq := req.Clone(req.Context())
+	dpopReq.Header.Set("DPoP", proof)
+	return dpopReq, nil
+}
+
+// WithDPoP returns a copy of the client that uses DPoP with the signer: the token requests
+// (GetOAuthToken(), RefreshOAuthToken(), ...) carry a DPoP proof, so that Casdoor issues
+// tokens bound to the signer's key, and the API requests made with a user's access token
+// (WithAccessToken(), WithTokenSource()) use the "DPoP" authorization scheme with a proof.
+// The requests authenticated with the client ID and client secret are left unchanged.
+//
+//	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+//	signer, _ := casdoorsdk.NewDPoPSigner(key)
+//	dpopClient := client.WithDPoP(signer)
+//	token, err := dpopClient.GetOAuthToken(code, state)
+//	user, err := dpopClient.WithAccessToken(token.AccessToken).GetAccount()
+func (c *Client) WithDPoP(signer *DPoPSigner) *Client {
+	dpopClient := c.WithAccessToken(c.AccessToken)
+	dpopClient.tokenSource = c.tokenSource
+	dpopClient.dpopSigner = signer
+	return dpopClient
+}
+
+// getDPoPHtu returns the "htu" claim of a request URL: the URL without query and fragment.
+func getDPoPHtu(requestUrl string) (string, error) {
+	u, err := url.Parse(requestUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if !u.IsAbs() || u.Host == "" {
+		return "", fmt.Errorf("the DPoP target URL should be absolute: %s", requestUrl)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.RawQuery = ""
+	u.Fragment = ""
+	u.RawFragment = ""
+	if (u.Scheme == "https" && u.Port() == "443") || (u.Scheme == "http" && u.Port() == "80") {
+		u.Host = u.Hostname()
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	return u.String(), nil
+}
+
+// getDPoPAth returns the "ath" claim of an access token: its base64url-encoded SHA-256 hash.
+func getDPoPAth(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func getOrigin(requestUrl string) string {
+	u, err := url.Parse(requestUrl)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
@@ -0,0 +1,209 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDPoPProof(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	for name, signer := range map[string]*DPoPSigner{
+		"ES256": mustNewDPoPSigner(t, ecKey),
+		"EdDSA": mustNewDPoPSigner(t, edKey),
+	} {
+		t.Run(name, func(t *testing.T) {
+			verifier := NewDPoPVerifier()
+
+			proof, err := signer.Proof("GET", "https://api.example.com:443/resource?id=1", "access-token")
+			if err != nil {
+				t.Fatalf("Failed to create proof: %v", err)
+			}
+
+			_, err = verifier.VerifyProof(proof, "POST", "https://api.example.com/resource", "access-token")
+			if !errors.Is(err, ErrInvalidDPoPProof) {
+				t.Errorf("Expected ErrInvalidDPoPProof for a wrong method, got %v", err)
+			}
+
+			_, err = verifier.VerifyProof(proof, "GET", "https://other.example.com/resource", "access-token")
+			if !errors.Is(err, ErrInvalidDPoPProof) {
+				t.Errorf("Expected ErrInvalidDPoPProof for a wrong URL, got %v", err)
+			}
+
+			_, err = verifier.VerifyProof(proof, "GET", "https://api.example.com/resource", "other-token")
+			if !errors.Is(err, ErrInvalidDPoPProof) {
+				t.Errorf("Expected ErrInvalidDPoPProof for a wrong access token, got %v", err)
+			}
+
+			verified, err := verifier.VerifyProof(proof, "GET", "https://api.example.com/resource", "access-token")
+			if err != nil {
+				t.Fatalf("Failed to verify proof: %v", err)
+			}
+
+			thumbprint, err := signer.Thumbprint()
+			if err != nil {
+				t.Fatalf("Failed to get thumbprint: %v", err)
+			}
+			if verified.Jkt != thumbprint {
+				t.Errorf("Expected jkt %s, got %s", thumbprint, verified.Jkt)
+			}
+
+			_, err = verifier.VerifyProof(proof, "GET", "https://api.example.com/resource", "access-token")
+			if !errors.Is(err, ErrDPoPProofReplayed) {
+				t.Errorf("Expected ErrDPoPProofReplayed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDPoPRequests(t *testing.T) {
+	testSigner := newTestSigner(t)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	signer := mustNewDPoPSigner(t, key)
+
+	var serverUrl string
+	var tokenRequests, accountRequests int32
+	tokenVerifier := NewDPoPVerifier()
+	tokenVerifier.Nonce = func() string { return "token-nonce" }
+	resourceVerifier := NewDPoPVerifier()
+	resourceVerifier.Nonce = func() string { return "resource-nonce" }
+	var client *Client
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/login/oauth/access_token":
+			atomic.AddInt32(&tokenRequests, 1)
+			proof, err := tokenVerifier.VerifyProof(r.Header.Get("DPoP"), r.Method, serverUrl+r.URL.Path, "")
+			if err != nil {
+				w.Header().Set("DPoP-Nonce", "token-nonce")
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "use_dpop_nonce"})
+				return
+			}
+
+			claims := newTestClaims(serverUrl, "client-id")
+			claims.Cnf = &Confirmation{Jkt: proof.Jkt}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token": testSigner.sign(t, claims),
+				"token_type":   "DPoP",
+				"expires_in":   3600,
+			})
+		case "/api/get-account":
+			atomic.AddInt32(&accountRequests, 1)
+			claims, _, err := client.VerifyDPoPRequest(r, resourceVerifier)
+			if err != nil {
+				w.Header().Set("DPoP-Nonce", "resource-nonce")
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"status": "ok",
+				"data":   map[string]string{"owner": claims.Owner, "name": claims.Name},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	serverUrl = server.URL
+
+	client = NewClient(server.URL, "client-id", "client-secret", testSigner.Certificate, "built-in", "app-built-in")
+	dpopClient := client.WithDPoP(signer)
+
+	token, err := dpopClient.GetOAuthToken("code", "state")
+	if err != nil {
+		t.Fatalf("Failed to get token: %v", err)
+	}
+	if tokenRequests != 2 {
+		t.Errorf("Expected a retry with the token endpoint's nonce, got %d requests", tokenRequests)
+	}
+
+	user, err := dpopClient.WithAccessToken(token.AccessToken).GetAccount()
+	if err != nil {
+		t.Fatalf("Failed to get account: %v", err)
+	}
+	if user.Name != "admin" {
+		t.Errorf("Expected user admin, got %s", user.Name)
+	}
+	if accountRequests != 2 {
+		t.Errorf("Expected a retry with the resource server's nonce, got %d requests", accountRequests)
+	}
+
+	// The token is bound to the signer's key
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	_, err = client.WithDPoP(mustNewDPoPSigner(t, otherKey)).WithAccessToken(token.AccessToken).GetAccount()
+	if err == nil {
+		t.Errorf("Expected an error for a token used with another key")
+	}
+}
+
+func mustNewDPoPSigner(t *testing.T, key crypto.Signer) *DPoPSigner {
+	signer, err := NewDPoPSigner(key)
+	if err != nil {
+		t.Fatalf("Failed to create DPoP signer: %v", err)
+	}
+	return signer
+}
+
+func TestDPoPJtiPruning(t *testing.T) {
+	verifier := NewDPoPVerifier()
+	now := time.Now()
+
+	// The jtis expire in their own order, not in the order they were seen
+	if !verifier.saveJti("a", now.Add(2*time.Minute), now) || !verifier.saveJti("b", now.Add(time.Minute), now) {
+		t.Fatalf("Expected new jtis to be saved")
+	}
+	if verifier.saveJti("a", now.Add(2*time.Minute), now) {
+		t.Errorf("Expected a replayed jti to be rejected")
+	}
+
+	later := now.Add(90 * time.Second)
+	if !verifier.saveJti("c", later.Add(time.Minute), later) {
+		t.Fatalf("Expected a new jti to be saved")
+	}
+	if len(verifier.jtis) != 2 || len(verifier.jtiExpiry) != 2 {
+		t.Errorf("Expected only the expired jti to be pruned, got %v", verifier.jtis)
+	}
+	if !verifier.saveJti("b", later.Add(time.Minute), later) || verifier.saveJti("a", later.Add(time.Minute), later) {
+		t.Errorf("Expected the expired jti to be accepted again and the others to be kept")
+	}
+}
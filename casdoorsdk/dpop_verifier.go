@@ -0,0 +1,276 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"container/heap"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	ErrInvalidDPoPProof  = errors.New("invalid DPoP proof")
+	ErrDPoPProofReplayed = errors.New("DPoP proof has been used before")
+	ErrDPoPKeyMismatch   = errors.New("token is not bound to the DPoP key")
+)
+
+// defaultDPoPProofMaxAge is how long a DPoP proof is accepted after its "iat".
+const defaultDPoPProofMaxAge = time.Minute
+
+// dpopAlgorithms are the asymmetric algorithms accepted for the DPoP proofs.
+var dpopAlgorithms = []string{"ES256", "ES384", "ES512", "EdDSA", "RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}
+
+// DPoPProof is a verified DPoP proof.
+type DPoPProof struct {
+	Jti   string
+	Htm   string
+	Htu   string
+	Iat   time.Time
+	Ath   string
+	Nonce string
+	Jwk   *JsonWebKey
+	// Jkt is the JWK thumbprint of the proof's key.
+	Jkt string
+}
+
+type dpopClaims struct {
+	Jti   string           `json:"jti"`
+	Htm   string           `json:"htm"`
+	Htu   string           `json:"htu"`
+	Iat   *jwt.NumericDate `json:"iat"`
+	Ath   string           `json:"ath"`
+	Nonce string           `json:"nonce"`
+}
+
+// Valid lets the verifier check the claims itself, with its own clock skew and replay checks.
+func (c *dpopClaims) Valid() error {
+	return nil
+}
+
+// DPoPVerifier verifies the DPoP proofs received by a resource server. It remembers the "jti"
+// of the accepted proofs to reject replayed ones, so one verifier should be shared by all the
+// requests. It is safe for concurrent use.
+type DPoPVerifier struct {
+	// MaxAge is how long a proof is accepted after it was created, the default is one minute.
+	MaxAge time.Duration
+	// Leeway is the allowed clock skew between the client and this machine.
+	Leeway time.Duration
+	// Nonce returns the nonce that the proofs must carry, leave it nil to not require nonces.
+	// When a proof lacks the nonce, return the nonce to the client in the "DPoP-Nonce" header
+	// with a "use_dpop_nonce" error.
+	Nonce func() string
+
+	mu        sync.Mutex
+	jtis      map[string]time.Time
+	jtiExpiry jtiHeap
+}
+
+// NewDPoPVerifier returns a DPoPVerifier with the default settings.
+func NewDPoPVerifier() *DPoPVerifier {
+	return &DPoPVerifier{MaxAge: defaultDPoPProofMaxAge}
+}
+
+// VerifyProof verifies the DPoP proof sent with an HTTP request with the given method and URL.
+// It checks the proof's signature with its embedded key and its "htm", "htu", "iat", "jti",
+// "nonce" and "ath" claims. The accessToken is the token sent along with the proof, an empty
+// accessToken means that no token is expected, like at a token endpoint.
+func (v *DPoPVerifier) VerifyProof(proof string, method string, requestUrl string, accessToken string) (*DPoPProof, error) {
+	var jwk *JsonWebKey
+	claims := &dpopClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods(dpopAlgorithms))
+	_, err := parser.ParseWithClaims(proof, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Header["typ"] != "dpop+jwt" {
+			return nil, fmt.Errorf("the \"typ\" header should be \"dpop+jwt\", got %v", token.Header["typ"])
+		}
+
+		jwkBytes, err := json.Marshal(token.Header["jwk"])
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal(jwkBytes, &jwk)
+		if err != nil || jwk == nil {
+			return nil, errors.New("the \"jwk\" header is missing")
+		}
+
+		if jwk.D != "" {
+			return nil, errors.New("the \"jwk\" header contains a private key")
+		}
+
+		return jwk.PublicKey()
+	})
+	if err != nil {
+		return nil, newValidationError(ErrInvalidDPoPProof, "%s", err.Error())
+	}
+
+	htu, err := getDPoPHtu(requestUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	claimHtu, err := getDPoPHtu(claims.Htu)
+	if err != nil || claimHtu != htu {
+		return nil, newValidationError(ErrInvalidDPoPProof, "the \"htu\" claim should be %q, got %q", htu, claims.Htu)
+	}
+
+	if claims.Htm != method {
+		return nil, newValidationError(ErrInvalidDPoPProof, "the \"htm\" claim should be %q, got %q", method, claims.Htm)
+	}
+
+	if claims.Jti == "" || claims.Iat == nil {
+		return nil, newValidationError(ErrInvalidDPoPProof, "the \"jti\" and \"iat\" claims are required")
+	}
+
+	maxAge := v.MaxAge
+	if maxAge == 0 {
+		maxAge = defaultDPoPProofMaxAge
+	}
+
+	now := time.Now()
+	if claims.Iat.After(now.Add(v.Leeway)) || now.Sub(claims.Iat.Time) > maxAge+v.Leeway {
+		return nil, newValidationError(ErrInvalidDPoPProof, "the proof was created at %s", claims.Iat.Format(time.RFC3339))
+	}
+
+	if v.Nonce != nil && subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(v.Nonce())) != 1 {
+		return nil, newValidationError(ErrInvalidDPoPProof, "use_dpop_nonce")
+	}
+
+	if accessToken != "" && subtle.ConstantTimeCompare([]byte(claims.Ath), []byte(getDPoPAth(accessToken))) != 1 {
+		return nil, newValidationError(ErrInvalidDPoPProof, "the \"ath\" claim does not match the access token")
+	}
+
+	jkt, err := jwk.Thumbprint()
+	if err != nil {
+		return nil, err
+	}
+
+	if !v.saveJti(jkt+":"+claims.Jti, claims.Iat.Add(maxAge+2*v.Leeway), now) {
+		return nil, &ValidationError{Err: ErrDPoPProofReplayed}
+	}
+
+	return &DPoPProof{
+		Jti:   claims.Jti,
+		Htm:   claims.Htm,
+		Htu:   claims.Htu,
+		Iat:   claims.Iat.Time,
+		Ath:   claims.Ath,
+		Nonce: claims.Nonce,
+		Jwk:   jwk,
+		Jkt:   jkt,
+	}, nil
+}
+
+// saveJti remembers the jti until expiry, and reports whether it has not been seen before.
+// The expired jtis are pruned in the order of their expiry, so a proof doesn't scan them all.
+func (v *DPoPVerifier) saveJti(jti string, expiry time.Time, now time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.jtis == nil {
+		v.jtis = map[string]time.Time{}
+	}
+
+	for len(v.jtiExpiry) > 0 && now.After(v.jtiExpiry[0].expiry) {
+		delete(v.jtis, heap.Pop(&v.jtiExpiry).(jtiEntry).jti)
+	}
+
+	if _, ok := v.jtis[jti]; ok {
+		return false
+	}
+
+	v.jtis[jti] = expiry
+	heap.Push(&v.jtiExpiry, jtiEntry{jti: jti, expiry: expiry})
+	return true
+}
+
+type jtiEntry struct {
+	jti    string
+	expiry time.Time
+}
+
+// jtiHeap orders the jtis by expiry, the first one expires first.
+type jtiHeap []jtiEntry
+
+func (h jtiHeap) Len() int           { return len(h) }
+func (h jtiHeap) Less(i, j int) bool { return h[i].expiry.Before(h[j].expiry) }
+func (h jtiHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *jtiHeap) Push(x interface{}) {
+	*h = append(*h, x.(jtiEntry))
+}
+
+func (h *jtiHeap) Pop() interface{} {
+	old := *h
+	entry := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return entry
+}
+
+// VerifyDPoPRequest authenticates a request received by a resource server that uses DPoP: it
+// parses the access token of the "Authorization: DPoP" header with ParseJwtToken(), verifies the
+// proof of the "DPoP" header with the verifier, and checks that the token is bound to the
+// proof's key via its "cnf.jkt" claim.
+// The request URL is rebuilt from the request's Host and TLS state, so behind a reverse proxy
+// that changes them, call the verifier's VerifyProof() with the public URL instead.
+func (c *Client) VerifyDPoPRequest(r *http.Request, verifier *DPoPVerifier) (*Claims, *DPoPProof, error) {
+	authorization := r.Header.Get("Authorization")
+	if len(authorization) <= len("DPoP ") || !strings.EqualFold(authorization[:len("DPoP ")], "DPoP ") {
+		return nil, nil, newValidationError(ErrInvalidDPoPProof, "the \"Authorization: DPoP\" header is missing")
+	}
+	accessToken := authorization[len("DPoP "):]
+
+	proofs := r.Header.Values("DPoP")
+	if len(proofs) != 1 {
+		return nil, nil, newValidationError(ErrInvalidDPoPProof, "exactly one \"DPoP\" header is required")
+	}
+
+	claims, err := c.ParseJwtToken(accessToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proof, err := verifier.VerifyProof(proofs[0], r.Method, getRequestUrl(r), accessToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if claims.Cnf == nil || subtle.ConstantTimeCompare([]byte(claims.Cnf.Jkt), []byte(proof.Jkt)) != 1 {
+		return nil, nil, &ValidationError{Err: ErrDPoPKeyMismatch}
+	}
+
+	return claims, proof, nil
+}
+
+// getRequestUrl returns the absolute URL of a request received by a server.
+func getRequestUrl(r *http.Request) string {
+	if r.URL.IsAbs() {
+		return r.URL.String()
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
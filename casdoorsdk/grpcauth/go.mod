@@ -0,0 +1,22 @@
+module github.com/casdoor/casdoor-go-sdk/casdoorsdk/grpcauth
+
+go 1.23.0
+
+require (
+	github.com/casdoor/casdoor-go-sdk v1.21.0
+	github.com/golang-jwt/jwt/v4 v4.5.0
+	golang.org/x/oauth2 v0.28.0
+	google.golang.org/grpc v1.73.0
+)
+
+require (
+	golang.org/x/net v0.38.0 // indirect
+	golang.org/x/sys v0.31.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250324211829-b45e905df463 // indirect
+	google.golang.org/protobuf v1.36.6 // indirect
+)
+
+// The replace only applies to the builds in this repository: the SDK must be tagged
+// with the version required above before this module, see "Sub-modules" in README.md.
+replace github.com/casdoor/casdoor-go-sdk => ../..
@@ -0,0 +1,166 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcauth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk/casdoortest"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// newTestClient returns a client that verifies the tokens of the returned minter.
+func newTestClient(t *testing.T) (*casdoorsdk.Client, *casdoortest.TokenMinter) {
+	minter, err := casdoortest.NewTokenMinter(casdoorsdk.CertAlgorithmRs256)
+	if err != nil {
+		t.Fatalf("Failed to create the minter: %v", err)
+	}
+
+	return casdoorsdk.NewClientWithConf(minter.AuthConfig("built-in", "app-built-in")), minter
+}
+
+func newTestUser(roles ...string) *casdoorsdk.User {
+	user := &casdoorsdk.User{Owner: "built-in", Name: "alice", Id: "alice-id"}
+	for _, role := range roles {
+		user.Roles = append(user.Roles, &casdoorsdk.Role{Owner: "built-in", Name: role})
+	}
+
+	return user
+}
+
+// mintToken mints an access token of alice with the scope and roles.
+func mintToken(t *testing.T, minter *casdoortest.TokenMinter, scope string, roles ...string) string {
+	token, err := minter.MintAccessToken(newTestUser(roles...), map[string]interface{}{"scope": scope})
+	if err != nil {
+		t.Fatalf("Failed to mint the token: %v", err)
+	}
+	return token
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	client, minter := newTestClient(t)
+	refreshToken, err := minter.MintRefreshToken(newTestUser(), map[string]interface{}{"scope": "orders"})
+	if err != nil {
+		t.Fatalf("Failed to mint the refresh token: %v", err)
+	}
+
+	interceptor := UnaryServerInterceptor(client,
+		WithPublicMethods("/test.Health/*"),
+		WithMethodRules("/test.Orders/Delete", RequireAnyRole("admin")),
+		WithMethodRules("/test.Orders/*", RequireScopes("orders")))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		claims, ok := ClaimsFromContext(ctx)
+		if !ok {
+			return "anonymous", nil
+		}
+		return claims.Name, nil
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		code          codes.Code
+		reply         string
+	}{
+		{name: "valid token", method: "/test.Orders/Get", authorization: "Bearer " + mintToken(t, minter, "orders"), code: codes.OK, reply: "alice"},
+		{name: "public method", method: "/test.Health/Check", code: codes.OK, reply: "anonymous"},
+		{name: "no token", method: "/test.Orders/Get", code: codes.Unauthenticated},
+		{name: "other scheme", method: "/test.Orders/Get", authorization: "Basic YWxpY2U6MTIz", code: codes.Unauthenticated},
+		{name: "invalid token", method: "/test.Orders/Get", authorization: "Bearer invalid", code: codes.Unauthenticated},
+		{name: "refresh token", method: "/test.Orders/Get", authorization: "Bearer " + refreshToken, code: codes.Unauthenticated},
+		{name: "missing scope", method: "/test.Orders/Get", authorization: "Bearer " + mintToken(t, minter, "read"), code: codes.PermissionDenied},
+		{name: "missing role", method: "/test.Orders/Delete", authorization: "Bearer " + mintToken(t, minter, "orders", "user"), code: codes.PermissionDenied},
+		{name: "role", method: "/test.Orders/Delete", authorization: "Bearer " + mintToken(t, minter, "orders", "admin"), code: codes.OK, reply: "alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.authorization))
+			}
+
+			reply, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if status.Code(err) != tt.code {
+				t.Fatalf("Expected code %s, got %v", tt.code, err)
+			}
+			if tt.reply != "" && reply != tt.reply {
+				t.Errorf("Expected reply %q, got %v", tt.reply, reply)
+			}
+		})
+	}
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	client, minter := newTestClient(t)
+	interceptor := StreamServerInterceptor(client)
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Orders/Watch"}
+
+	var name string
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		claims, _ := ClaimsFromContext(stream.Context())
+		name = claims.Name
+		return nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+mintToken(t, minter, "read")))
+	err := interceptor(nil, &testServerStream{ctx: ctx}, info, handler)
+	if err != nil {
+		t.Fatalf("Failed to authenticate the stream: %v", err)
+	}
+	if name != "alice" {
+		t.Errorf("Expected alice, got %q", name)
+	}
+
+	err = interceptor(nil, &testServerStream{ctx: context.Background()}, info, handler)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated, got %v", err)
+	}
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	interceptor := UnaryClientInterceptor(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "access-token"}))
+
+	var authorization []string
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		authorization = md.Get("authorization")
+		return nil
+	}
+
+	err := interceptor(context.Background(), "/test.Orders/Get", nil, nil, nil, invoker)
+	if err != nil {
+		t.Fatalf("Failed to invoke: %v", err)
+	}
+	if len(authorization) != 1 || authorization[0] != "Bearer access-token" {
+		t.Errorf("Expected the bearer token, got %v", authorization)
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package grpcauth provides the gRPC interceptors that authenticate the calls with the access
+// tokens issued by Casdoor. On the server side:
+//
+//	server := grpc.NewServer(
+//		grpc.UnaryInterceptor(grpcauth.UnaryServerInterceptor(client,
+//			grpcauth.WithMethodRules("/shop.Orders/Delete", grpcauth.RequireAnyRole("admin")))),
+//		grpc.StreamInterceptor(grpcauth.StreamServerInterceptor(client)))
+//
+// The handlers get the token's claims with ClaimsFromContext(ctx). On the client side,
+// UnaryClientInterceptor() and StreamClientInterceptor() attach the token of a token source.
+//
+// It is a separate module, so that the SDK itself doesn't depend on gRPC:
+//
+//	go get github.com/casdoor/casdoor-go-sdk/casdoorsdk/grpcauth
+package grpcauth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/golang-jwt/jwt/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type principalContextKey struct{}
+
+// NewContext returns a copy of ctx carrying the principal, it is mainly useful in tests.
+func NewContext(ctx context.Context, principal *casdoorsdk.Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal of the call's access token, set by the server interceptors.
+func PrincipalFromContext(ctx context.Context) (*casdoorsdk.Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*casdoorsdk.Principal)
+	return principal, ok && principal != nil
+}
+
+// ClaimsFromContext returns the claims of the call's access token, set by the server
+// interceptors. The second result is false for the public methods and for the opaque tokens
+// checked via introspection, see PrincipalFromContext().
+func ClaimsFromContext(ctx context.Context) (*casdoorsdk.Claims, bool) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok || principal.Claims == nil {
+		return nil, false
+	}
+
+	return principal.Claims, true
+}
+
+// Rule is an authorization check of the calls to a method, run after the token validation.
+// A returned error that is not a gRPC status is reported as codes.PermissionDenied.
+type Rule func(ctx context.Context, fullMethod string, principal *casdoorsdk.Principal) error
+
+// RequireScopes requires the token to have all the given scopes.
+func RequireScopes(scopes ...string) Rule {
+	return func(ctx context.Context, fullMethod string, principal *casdoorsdk.Principal) error {
+		for _, scope := range scopes {
+			if !principal.HasScope(scope) {
+				return status.Errorf(codes.PermissionDenied, "the token is missing the scope %q", scope)
+			}
+		}
+
+		return nil
+	}
+}
+
+// RequireAnyRole requires the user to have at least one of the given roles (Claims.User.Roles),
+// identified by their name ("admin") or by their owner and name ("built-in/admin"). The roles
+// are only known for the JWTs, so it rejects the opaque tokens.
+func RequireAnyRole(roles ...string) Rule {
+	return func(ctx context.Context, fullMethod string, principal *casdoorsdk.Principal) error {
+		if principal.Claims != nil {
+			for _, role := range principal.Claims.Roles {
+				if role == nil {
+					continue
+				}
+
+				for _, requiredRole := range roles {
+					if requiredRole == role.Name || requiredRole == role.Owner+"/"+role.Name {
+						return nil
+					}
+				}
+			}
+		}
+
+		return status.Error(codes.PermissionDenied, "the user has none of the required roles")
+	}
+}
+
+// RequireEnforce allows the call when client.Enforce() allows the Casbin request built by
+// getRequest, e.g., []interface{}{principal.Claims.GetId(), fullMethod, "call"}, against the
+// permission identified by permissionId ("owner/name").
+func RequireEnforce(client *casdoorsdk.Client, permissionId string, getRequest func(ctx context.Context, fullMethod string, principal *casdoorsdk.Principal) casdoorsdk.CasbinRequest) Rule {
+	return func(ctx context.Context, fullMethod string, principal *casdoorsdk.Principal) error {
+		allowed, err := client.Enforce(permissionId, "", "", "", "", getRequest(ctx, fullMethod, principal))
+		if err != nil {
+			return status.Errorf(codes.Unavailable, "failed to enforce the permission: %v", err)
+		}
+
+		if !allowed {
+			return status.Error(codes.PermissionDenied, "the permission is denied")
+		}
+
+		return nil
+	}
+}
+
+// Option is a function type for configuring the server interceptors.
+type Option func(*options)
+
+// options holds the configuration of the server interceptors.
+type options struct {
+	validator     *casdoorsdk.TokenValidator
+	publicMethods []string
+	methodRules   map[string][]Rule
+}
+
+// WithTokenValidator validates the tokens with the validator, e.g., to check the opaque tokens
+// via introspection. By default, only the JWTs are accepted, validated by
+// casdoorsdk.ParseJwtTokenWithOptions() with the refresh tokens rejected.
+func WithTokenValidator(validator *casdoorsdk.TokenValidator) Option {
+	return func(opts *options) {
+		opts.validator = validator
+	}
+}
+
+// WithPublicMethods lets the calls to the given methods through without any token. A method
+// is a full method name ("/package.Service/Method") or a service followed by "*"
+// ("/package.Service/*"), like for WithMethodRules().
+func WithPublicMethods(methods ...string) Option {
+	return func(opts *options) {
+		opts.publicMethods = append(opts.publicMethods, methods...)
+	}
+}
+
+// WithMethodRules adds the rules that the calls to the method must pass.
+func WithMethodRules(method string, rules ...Rule) Option {
+	return func(opts *options) {
+		opts.methodRules[method] = append(opts.methodRules[method], rules...)
+	}
+}
+
+func getOptions(client *casdoorsdk.Client, opts ...Option) *options {
+	options := &options{methodRules: map[string][]Rule{}}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.validator == nil {
+		options.validator = client.NewTokenValidator(
+			casdoorsdk.WithIntrospectionPolicy(casdoorsdk.IntrospectNever),
+			casdoorsdk.WithValidationOptions(casdoorsdk.WithRejectRefreshTokens()))
+	}
+
+	return options
+}
+
+// UnaryServerInterceptor returns a server interceptor that authenticates the unary calls.
+func UnaryServerInterceptor(client *casdoorsdk.Client, opts ...Option) grpc.UnaryServerInterceptor {
+	options := getOptions(client, opts...)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := options.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a server interceptor that authenticates the streaming calls.
+func StreamServerInterceptor(client *casdoorsdk.Client, opts ...Option) grpc.StreamServerInterceptor {
+	options := getOptions(client, opts...)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := options.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// serverStream overrides the context of a grpc.ServerStream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate validates the token of the incoming metadata and checks the method's rules,
+// returning a copy of ctx carrying the principal.
+func (opts *options) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	if matchMethod(opts.publicMethods, fullMethod) {
+		return ctx, nil
+	}
+
+	token, err := getToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	principal, err := opts.validator.Validate(ctx, token)
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	for method, rules := range opts.methodRules {
+		if !matchMethod([]string{method}, fullMethod) {
+			continue
+		}
+
+		for _, rule := range rules {
+			err = rule(ctx, fullMethod, principal)
+			if err != nil {
+				if _, ok := status.FromError(err); ok {
+					return nil, err
+				}
+				return nil, status.Error(codes.PermissionDenied, err.Error())
+			}
+		}
+	}
+
+	return NewContext(ctx, principal), nil
+}
+
+// getToken returns the Bearer token of the "authorization" metadata.
+func getToken(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	authorizations := md.Get("authorization")
+	if len(authorizations) == 0 {
+		return "", status.Error(codes.Unauthenticated, "the bearer token is missing")
+	}
+	if len(authorizations) > 1 {
+		return "", status.Error(codes.Unauthenticated, "multiple authorization metadata")
+	}
+
+	scheme, token, _ := strings.Cut(authorizations[0], " ")
+	token = strings.TrimSpace(token)
+	if !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", status.Error(codes.Unauthenticated, "the authorization should be a bearer token")
+	}
+
+	return token, nil
+}
+
+// getStatusError converts a token validation error into a gRPC status.
+func getStatusError(err error) error {
+	if errors.Is(err, casdoorsdk.ErrMissingScope) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	var validationErr *casdoorsdk.ValidationError
+	var jwtErr *jwt.ValidationError
+	if errors.As(err, &validationErr) || errors.As(err, &jwtErr) {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return status.Errorf(codes.Unavailable, "failed to validate the token: %v", err)
+}
+
+// matchMethod reports whether the full method matches one of the methods, which can end with "*".
+func matchMethod(methods []string, fullMethod string) bool {
+	for _, method := range methods {
+		if method == fullMethod || (strings.HasSuffix(method, "*") && strings.HasPrefix(fullMethod, strings.TrimSuffix(method, "*"))) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,167 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	ErrMissingClaim   = errors.New("token is missing a required claim")
+	ErrInvalidNonce   = errors.New("token has an invalid nonce")
+	ErrAuthTimeTooOld = errors.New("end-user authentication is too old")
+	ErrInvalidAtHash  = errors.New("token has an invalid at_hash")
+	ErrInvalidCHash   = errors.New("token has an invalid c_hash")
+)
+
+// IDTokenClaims is the Claims of an OpenID Connect ID token, extended with the standard
+// claims of https://openid.net/specs/openid-connect-core-1_0.html#IDToken
+type IDTokenClaims struct {
+	Claims
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+	AtHash   string           `json:"at_hash,omitempty"`
+	CHash    string           `json:"c_hash,omitempty"`
+	Acr      string           `json:"acr,omitempty"`
+	Amr      []string         `json:"amr,omitempty"`
+	Sid      string           `json:"sid,omitempty"`
+}
+
+// WithMaxAuthAge sets the "max_age" sent in the authorization request, the ID token is
+// rejected when the end-user authenticated ("auth_time") longer than maxAge ago.
+// It is only used by VerifyIDToken().
+func WithMaxAuthAge(maxAge time.Duration) ValidationOption {
+	return func(opts *validationOptions) {
+		opts.maxAuthAge = maxAge
+	}
+}
+
+// VerifyIDToken verifies the ID token returned by the token endpoint, following the ID token
+// validation steps of OpenID Connect Core 1.0, section 3.1.3.7:
+// signature, "iss", "aud", "azp" (required when there are multiple audiences), "exp", "iat",
+// "nonce" and "auth_time" (when WithMaxAuthAge() is set).
+// The accessToken and code are checked against the "at_hash" and "c_hash" claims, pass an
+// empty string to skip the respective check. The expectedNonce is the nonce sent in the
+// authorization request, an empty expectedNonce skips the nonce check.
+// The raw ID token is in the "id_token" field of the token response:
+//
+//	token, err := client.GetOAuthToken(code, state)
+//	rawIDToken, _ := token.Extra("id_token").(string)
+//	claims, err := client.VerifyIDToken(ctx, rawIDToken, token.AccessToken, "", nonce)
+func (c *Client) VerifyIDToken(ctx context.Context, rawIDToken string, accessToken string, code string, expectedNonce string, opts ...ValidationOption) (*IDTokenClaims, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if rawIDToken == "" {
+		return nil, errors.New("VerifyIDToken() error: the rawIDToken should not be empty")
+	}
+
+	claims := &IDTokenClaims{}
+	t, err := c.parseJwtTokenSignature(rawIDToken, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	options := c.getValidationOptions(opts...)
+	options.rejectRefreshTokens = true
+
+	if claims.ExpiresAt == nil {
+		return nil, newValidationError(ErrMissingClaim, "exp")
+	}
+	if claims.IssuedAt == nil {
+		return nil, newValidationError(ErrMissingClaim, "iat")
+	}
+	if len(claims.Audience) > 1 && claims.Azp == "" {
+		return nil, newValidationError(ErrInvalidAuthorizedParty, "azp is required when there are multiple audiences")
+	}
+
+	err = options.validate(&claims.Claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if expectedNonce != "" && subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(expectedNonce)) != 1 {
+		return nil, newValidationError(ErrInvalidNonce, "the nonce does not match the one of the authorization request")
+	}
+
+	if options.maxAuthAge > 0 {
+		if claims.AuthTime == nil {
+			return nil, newValidationError(ErrMissingClaim, "auth_time")
+		}
+
+		if jwt.TimeFunc().Sub(claims.AuthTime.Time) > options.maxAuthAge+options.leeway {
+			return nil, newValidationError(ErrAuthTimeTooOld, "authenticated at %s", claims.AuthTime.Format(time.RFC3339))
+		}
+	}
+
+	if accessToken != "" && claims.AtHash != "" {
+		err = verifyTokenHash(t.Method.Alg(), accessToken, claims.AtHash, ErrInvalidAtHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if code != "" && claims.CHash != "" {
+		err = verifyTokenHash(t.Method.Alg(), code, claims.CHash, ErrInvalidCHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return claims, nil
+}
+
+// getTokenHash returns the "at_hash" or "c_hash" of value: the base64url encoding of the
+// left-most half of its hash, where the hash algorithm is the one of the ID token's "alg".
+func getTokenHash(alg string, value string) (string, error) {
+	var h hash.Hash
+	switch alg {
+	case "RS256", "ES256", "PS256", "HS256":
+		h = sha256.New()
+	case "RS384", "ES384", "PS384", "HS384":
+		h = sha512.New384()
+	case "RS512", "ES512", "PS512", "HS512", "EdDSA":
+		h = sha512.New()
+	default:
+		return "", fmt.Errorf("unsupported signing method: %s", alg)
+	}
+
+	h.Write([]byte(value))
+	sum := h.Sum(nil)
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2]), nil
+}
+
+func verifyTokenHash(alg string, value string, expected string, validationErr error) error {
+	tokenHash, err := getTokenHash(alg, value)
+	if err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(tokenHash), []byte(expected)) != 1 {
+		return newValidationError(validationErr, "expected %q, got %q", tokenHash, expected)
+	}
+
+	return nil
+}
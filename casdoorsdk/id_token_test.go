@@ -0,0 +1,119 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestVerifyIDToken(t *testing.T) {
+	signer := newTestSigner(t)
+	client := NewClient("http://localhost:8000", "client-id", "client-secret", signer.Certificate, "built-in", "app-built-in")
+
+	accessToken := "access-token-value"
+	code := "code-value"
+	atHash, err := getTokenHash("RS256", accessToken)
+	if err != nil {
+		t.Fatalf("Failed to hash access token: %v", err)
+	}
+	cHash, err := getTokenHash("RS256", code)
+	if err != nil {
+		t.Fatalf("Failed to hash code: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(claims *IDTokenClaims)
+		opts   []ValidationOption
+		err    error
+	}{
+		{name: "valid"},
+		{
+			name:   "wrong nonce",
+			modify: func(claims *IDTokenClaims) { claims.Nonce = "other-nonce" },
+			err:    ErrInvalidNonce,
+		},
+		{
+			name:   "wrong at_hash",
+			modify: func(claims *IDTokenClaims) { claims.AtHash = cHash },
+			err:    ErrInvalidAtHash,
+		},
+		{
+			name:   "wrong c_hash",
+			modify: func(claims *IDTokenClaims) { claims.CHash = atHash },
+			err:    ErrInvalidCHash,
+		},
+		{
+			name:   "missing iat",
+			modify: func(claims *IDTokenClaims) { claims.IssuedAt = nil },
+			err:    ErrMissingClaim,
+		},
+		{
+			name:   "multiple audiences without azp",
+			modify: func(claims *IDTokenClaims) { claims.Audience = []string{"client-id", "other"}; claims.Azp = "" },
+			err:    ErrInvalidAuthorizedParty,
+		},
+		{
+			name: "recent authentication",
+			opts: []ValidationOption{WithMaxAuthAge(10 * time.Minute)},
+		},
+		{
+			name:   "old authentication",
+			modify: func(claims *IDTokenClaims) { claims.AuthTime = jwt.NewNumericDate(time.Now().Add(-time.Hour)) },
+			opts:   []ValidationOption{WithMaxAuthAge(10 * time.Minute)},
+			err:    ErrAuthTimeTooOld,
+		},
+		{
+			name:   "refresh token",
+			modify: func(claims *IDTokenClaims) { claims.TokenType = "refresh-token" },
+			err:    ErrRefreshTokenNotAllowed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claims := &IDTokenClaims{
+				Claims:   *newTestClaims(client.Endpoint, client.ClientId),
+				AuthTime: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+				AtHash:   atHash,
+				CHash:    cHash,
+			}
+			claims.Nonce = "nonce"
+			if test.modify != nil {
+				test.modify(claims)
+			}
+
+			verified, err := client.VerifyIDToken(context.Background(), signer.sign(t, claims), accessToken, code, "nonce", test.opts...)
+			if test.err == nil {
+				if err != nil {
+					t.Fatalf("Failed to verify ID token: %v", err)
+				}
+				if verified.AtHash != atHash || verified.Name != "admin" {
+					t.Fatalf("Verified claims mismatch: %+v", verified)
+				}
+				return
+			}
+
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected error %v, got %v", test.err, err)
+			}
+		})
+	}
+}
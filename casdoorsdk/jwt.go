@@ -15,6 +15,7 @@
 package casdoorsdk
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -31,9 +32,17 @@ type Claims struct {
 	Provider string `json:"provider,omitempty"`
 
 	SigninMethod string `json:"signinMethod,omitempty"`
+	// the `cnf` (Confirmation) claim of the DPoP-bound tokens. See https://datatracker.ietf.org/doc/html/rfc9449#section-6
+	Cnf *Confirmation `json:"cnf,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// Confirmation is the `cnf` claim of a proof-of-possession token, Jkt is the JWK thumbprint
+// of the DPoP key that the token is bound to.
+type Confirmation struct {
+	Jkt string `json:"jkt,omitempty"`
+}
+
 // IsRefreshToken returns true if the token is a refresh token.
 // Casdoor emits the claim as "tokenType" for the JWT, JWT-Empty and JWT-Standard
 // formats, and as "TokenType" for JWT-Custom. Both land in TokenType, because
@@ -43,20 +52,7 @@ func (c Claims) IsRefreshToken() bool {
 }
 
 func (c *Client) ParseJwtToken(token string) (*Claims, error) {
-	t, err := jwt.ParseWithClaims(token, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		switch token.Method.Alg() {
-		case jwt.SigningMethodES256.Alg():
-			return jwt.ParseECPublicKeyFromPEM([]byte(c.Certificate))
-		case jwt.SigningMethodES512.Alg():
-			return jwt.ParseECPublicKeyFromPEM([]byte(c.Certificate))
-		case jwt.SigningMethodRS256.Alg():
-			return jwt.ParseRSAPublicKeyFromPEM([]byte(c.Certificate))
-		case jwt.SigningMethodRS512.Alg():
-			return jwt.ParseRSAPublicKeyFromPEM([]byte(c.Certificate))
-		default:
-			return nil, fmt.Errorf("unsupported signing method: %v", token.Header["alg"])
-		}
-	})
+	t, err := jwt.ParseWithClaims(token, &Claims{}, c.getJwtKey)
 
 	if t != nil {
 		if claims, ok := t.Claims.(*Claims); ok && t.Valid {
@@ -66,3 +62,38 @@ func (c *Client) ParseJwtToken(token string) (*Claims, error) {
 
 	return nil, err
 }
+
+// getJwtKey returns the public key that verifies the signature of the token, it is
+// the jwt.Keyfunc shared by all the JWT parsing functions of the client.
+func (c *Client) getJwtKey(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodES256.Alg():
+		return jwt.ParseECPublicKeyFromPEM([]byte(c.Certificate))
+	case jwt.SigningMethodES384.Alg():
+		return jwt.ParseECPublicKeyFromPEM([]byte(c.Certificate))
+	case jwt.SigningMethodES512.Alg():
+		return jwt.ParseECPublicKeyFromPEM([]byte(c.Certificate))
+	case jwt.SigningMethodRS256.Alg():
+		return jwt.ParseRSAPublicKeyFromPEM([]byte(c.Certificate))
+	case jwt.SigningMethodRS512.Alg():
+		return jwt.ParseRSAPublicKeyFromPEM([]byte(c.Certificate))
+	default:
+		return nil, fmt.Errorf("unsupported signing method: %v", token.Header["alg"])
+	}
+}
+
+// parseJwtTokenSignature parses the token into claims and only verifies its signature,
+// the claims are left to be validated by the caller.
+func (c *Client) parseJwtTokenSignature(token string, claims jwt.Claims) (*jwt.Token, error) {
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+	t, err := parser.ParseWithClaims(token, claims, c.getJwtKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if !t.Valid {
+		return nil, errors.New("token is invalid")
+	}
+
+	return t, nil
+}
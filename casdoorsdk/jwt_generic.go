@@ -0,0 +1,210 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// ShortUser is the user of the "JWT-Empty" token format.
+type ShortUser struct {
+	Owner       string `json:"owner"`
+	Name        string `json:"name"`
+	Id          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	Avatar      string `json:"avatar"`
+	Email       string `json:"email"`
+	Phone       string `json:"phone"`
+}
+
+// ShortClaims are the claims of the "JWT-Empty" token format, which only has the main fields
+// of the user.
+type ShortClaims struct {
+	ShortUser
+	TokenType string `json:"tokenType,omitempty"`
+	Nonce     string `json:"nonce,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	Azp       string `json:"azp,omitempty"`
+	Provider  string `json:"provider,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// StandardAddress is the "address" claim of OpenID Connect Core 1.0, section 5.1.1.
+type StandardAddress struct {
+	Formatted     string `json:"formatted"`
+	StreetAddress string `json:"street_address"`
+	Locality      string `json:"locality"`
+	Region        string `json:"region"`
+	PostalCode    string `json:"postal_code"`
+	Country       string `json:"country"`
+}
+
+// StandardClaims are the claims of the "JWT-Standard" token format, which has the standard
+// claims of OpenID Connect instead of the fields of the user, depending on the token's scopes:
+// like in Userinfo, the user's name is "preferred_username", and "name" is their display name.
+// Its "address" claim is an object, so these tokens can't be parsed into Claims.
+type StandardClaims struct {
+	Owner               string          `json:"owner"`
+	Name                string          `json:"preferred_username,omitempty"`
+	Id                  string          `json:"id"`
+	DisplayName         string          `json:"name,omitempty"`
+	Avatar              string          `json:"picture,omitempty"`
+	Email               string          `json:"email,omitempty"`
+	EmailVerified       bool            `json:"email_verified,omitempty"`
+	PhoneNumber         string          `json:"phone_number,omitempty"`
+	PhoneNumberVerified bool            `json:"phone_number_verified,omitempty"`
+	Gender              string          `json:"gender,omitempty"`
+	Address             StandardAddress `json:"address,omitempty"`
+	TokenType           string          `json:"tokenType,omitempty"`
+	Nonce               string          `json:"nonce,omitempty"`
+	Scope               string          `json:"scope,omitempty"`
+	Azp                 string          `json:"azp,omitempty"`
+	Provider            string          `json:"provider,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// ParseJwtTokenInto parses the token into the claims struct T, for the token formats other than
+// "JWT", whose claims are Claims: ShortClaims for "JWT-Empty", StandardClaims for "JWT-Standard",
+// or a struct of the caller for "JWT-Custom", whose claims are set by Application.TokenFields
+// and Application.JwtItem. The claims that have no field in T are returned in a map, where the
+// numbers are json.Number values.
+//
+// The signature is checked with the client's Certificate and the claims are validated like in
+// ParseJwtTokenWithOptions(), whichever T is:
+//
+//	type MyClaims struct {
+//		Name      string `json:"name"`
+//		TokenType string `json:"TokenType"`
+//		Tenant    string `json:"tenant"`
+//		jwt.RegisteredClaims
+//	}
+//
+//	claims, unmapped, err := casdoorsdk.ParseJwtTokenInto[MyClaims](client, token)
+func ParseJwtTokenInto[T any](c *Client, token string, opts ...ValidationOption) (*T, map[string]interface{}, error) {
+	_, err := c.parseJwtTokenSignature(token, jwt.MapClaims{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the claims are decoded from the payload itself, so that their numbers are kept as they are
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, errors.New("ParseJwtTokenInto() error: the token should have 3 parts")
+	}
+	payload, err := jwt.DecodeSegment(parts[1])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var validated validatedClaims
+	err = json.Unmarshal(payload, &validated)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = c.getValidationOptions(opts...).validateClaims(&validated)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	claims := new(T)
+	err = json.Unmarshal(payload, claims)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	unmapped, err := getUnmappedClaims(payload, reflect.TypeOf(claims).Elem())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return claims, unmapped, nil
+}
+
+// getUnmappedClaims returns the claims of the payload that encoding/json doesn't decode into
+// a field of the type t. All the claims are mapped when t is a map.
+func getUnmappedClaims(payload []byte, t reflect.Type) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	res := map[string]interface{}{}
+	err := decoder.Decode(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		if t.Kind() == reflect.Map || t.Kind() == reflect.Interface {
+			return map[string]interface{}{}, nil
+		}
+		return res, nil
+	}
+
+	// encoding/json falls back to a case-insensitive match of the names
+	names := getJsonFieldNames(t)
+	for claim := range res {
+		for _, name := range names {
+			if strings.EqualFold(claim, name) {
+				delete(res, claim)
+				break
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// getJsonFieldNames returns the JSON names of the fields of the struct type t, including the
+// fields promoted from its embedded structs.
+func getJsonFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				names = append(names, getJsonFieldNames(fieldType)...)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
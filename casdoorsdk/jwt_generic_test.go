@@ -0,0 +1,134 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// newTestMapClaims returns the registered claims of a token of Casdoor for the client.
+func newTestMapClaims(endpoint string, clientId string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss": endpoint,
+		"sub": "admin-id",
+		"aud": []string{clientId},
+		"exp": now.Add(time.Hour).Unix(),
+		"nbf": now.Unix(),
+		"iat": now.Unix(),
+		"azp": clientId,
+	}
+}
+
+func TestParseJwtTokenInto(t *testing.T) {
+	signer := newTestSigner(t)
+	client := NewClient("http://localhost:8000", "client-id", "client-secret", signer.Certificate, "built-in", "app-built-in")
+
+	// JWT-Custom, with the claims of Application.TokenFields and Application.JwtItem
+	type customClaims struct {
+		Owner     string   `json:"owner"`
+		Name      string   `json:"name"`
+		TokenType string   `json:"TokenType"`
+		Roles     []string `json:"roles"`
+		jwt.RegisteredClaims
+	}
+	mapClaims := newTestMapClaims("http://localhost:8000", "client-id")
+	mapClaims["owner"] = "built-in"
+	mapClaims["name"] = "admin"
+	mapClaims["TokenType"] = "access-token"
+	mapClaims["Roles"] = []string{"admin"}
+	mapClaims["tenant"] = "acme"
+	mapClaims["quota"] = int64(9007199254740993)
+
+	claims, unmapped, err := ParseJwtTokenInto[customClaims](client, signer.sign(t, mapClaims))
+	if err != nil {
+		t.Fatalf("Failed to parse the JWT-Custom token: %v", err)
+	}
+	if claims.Name != "admin" || claims.TokenType != "access-token" || len(claims.Roles) != 1 || claims.Subject != "admin-id" {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+	if len(unmapped) != 3 || unmapped["tenant"] != "acme" || unmapped["quota"] != json.Number("9007199254740993") || unmapped["azp"] != "client-id" {
+		t.Errorf("Unexpected unmapped claims: %v", unmapped)
+	}
+
+	// The validation applies to the custom claims
+	mapClaims["TokenType"] = "refresh-token"
+	_, _, err = ParseJwtTokenInto[customClaims](client, signer.sign(t, mapClaims), WithRejectRefreshTokens())
+	if !errors.Is(err, ErrRefreshTokenNotAllowed) {
+		t.Errorf("Expected ErrRefreshTokenNotAllowed, got %v", err)
+	}
+	mapClaims["exp"] = time.Now().Add(-time.Minute).Unix()
+	_, _, err = ParseJwtTokenInto[customClaims](client, signer.sign(t, mapClaims))
+	if !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("Expected ErrTokenExpired, got %v", err)
+	}
+	_, _, err = ParseJwtTokenInto[customClaims](client, newTestSigner(t).sign(t, newTestMapClaims("http://localhost:8000", "client-id")))
+	if err == nil {
+		t.Errorf("Expected an error for a token of another certificate")
+	}
+
+	// JWT-Standard, whose address can't be parsed into Claims
+	mapClaims = newTestMapClaims("http://localhost:8000", "client-id")
+	mapClaims["owner"] = "built-in"
+	mapClaims["preferred_username"] = "admin"
+	mapClaims["name"] = "Admin"
+	mapClaims["picture"] = "https://cdn.example.com/admin.png"
+	mapClaims["tokenType"] = "access-token"
+	mapClaims["scope"] = "openid profile phone address"
+	mapClaims["email_verified"] = true
+	mapClaims["phone_number"] = "+1 555 0100"
+	mapClaims["address"] = map[string]string{"street_address": "1 Main St", "country": "US"}
+	token := signer.sign(t, mapClaims)
+
+	_, err = client.ParseJwtTokenWithOptions(token)
+	if err == nil {
+		t.Errorf("Expected an error for the JWT-Standard address in Claims")
+	}
+	standardClaims, unmapped, err := ParseJwtTokenInto[StandardClaims](client, token, WithRequiredScopes("phone"))
+	if err != nil {
+		t.Fatalf("Failed to parse the JWT-Standard token: %v", err)
+	}
+	if standardClaims.Name != "admin" || standardClaims.DisplayName != "Admin" || standardClaims.Avatar != "https://cdn.example.com/admin.png" || !standardClaims.EmailVerified || standardClaims.PhoneNumber != "+1 555 0100" || standardClaims.Address.StreetAddress != "1 Main St" {
+		t.Errorf("Unexpected JWT-Standard claims: %+v", standardClaims)
+	}
+	if len(unmapped) != 0 {
+		t.Errorf("Unexpected unmapped claims: %v", unmapped)
+	}
+
+	// JWT-Empty
+	mapClaims = newTestMapClaims("http://localhost:8000", "client-id")
+	mapClaims["owner"] = "built-in"
+	mapClaims["name"] = "admin"
+	mapClaims["tokenType"] = "access-token"
+	shortClaims, _, err := ParseJwtTokenInto[ShortClaims](client, signer.sign(t, mapClaims), WithExpectedAudiences("other-client"))
+	if !errors.Is(err, ErrInvalidAudience) || shortClaims != nil {
+		t.Errorf("Expected ErrInvalidAudience, got %v", err)
+	}
+	shortClaims, _, err = ParseJwtTokenInto[ShortClaims](client, signer.sign(t, mapClaims))
+	if err != nil || shortClaims.Owner != "built-in" || shortClaims.TokenType != "access-token" {
+		t.Errorf("Unexpected JWT-Empty claims: %+v, %v", shortClaims, err)
+	}
+
+	// All the claims are mapped into a map
+	all, unmapped, err := ParseJwtTokenInto[map[string]interface{}](client, signer.sign(t, mapClaims))
+	if err != nil || (*all)["name"] != "admin" || len(unmapped) != 0 {
+		t.Errorf("Unexpected map claims: %v, %v, %v", all, unmapped, err)
+	}
+}
@@ -17,3 +17,7 @@ package casdoorsdk
 func ParseJwtToken(token string) (*Claims, error) {
 	return globalClient.ParseJwtToken(token)
 }
+
+func ParseJwtTokenWithOptions(token string, opts ...ValidationOption) (*Claims, error) {
+	return globalClient.ParseJwtTokenWithOptions(token, opts...)
+}
@@ -0,0 +1,277 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	ErrTokenExpired           = errors.New("token is expired")
+	ErrTokenNotValidYet       = errors.New("token is not valid yet")
+	ErrTokenUsedBeforeIssued  = errors.New("token used before issued")
+	ErrTokenTooOld            = errors.New("token is too old")
+	ErrInvalidIssuer          = errors.New("token has an invalid issuer")
+	ErrInvalidAudience        = errors.New("token has an invalid audience")
+	ErrInvalidAuthorizedParty = errors.New("token has an invalid authorized party")
+	ErrMissingScope           = errors.New("token is missing a required scope")
+	ErrRefreshTokenNotAllowed = errors.New("refresh token is not allowed")
+)
+
+// ValidationError is returned by ParseJwtTokenWithOptions() when the signature of the token
+// is correct but one of its claims is rejected. Err is one of the Err* variables above, so
+// the reason can be checked with errors.Is(err, casdoorsdk.ErrInvalidAudience).
+type ValidationError struct {
+	Err    error
+	Detail string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Detail == "" {
+		return e.Err.Error()
+	}
+
+	return fmt.Sprintf("%s: %s", e.Err.Error(), e.Detail)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+func newValidationError(err error, format string, a ...interface{}) *ValidationError {
+	return &ValidationError{Err: err, Detail: fmt.Sprintf(format, a...)}
+}
+
+// ValidationOption is a function type for configuring the claim checks of ParseJwtTokenWithOptions().
+type ValidationOption func(*validationOptions)
+
+// validationOptions holds the claim checks of ParseJwtTokenWithOptions().
+type validationOptions struct {
+	issuer              string
+	audiences           []string
+	requiredScopes      []string
+	maxTokenAge         time.Duration
+	leeway              time.Duration
+	rejectRefreshTokens bool
+	maxAuthAge          time.Duration
+}
+
+// WithExpectedIssuer sets the expected "iss" claim, it defaults to the client's Endpoint.
+// Casdoor uses its "origin" config as the issuer, so set it when Casdoor is reached via
+// an internal address that differs from its public origin. An empty issuer disables the check.
+func WithExpectedIssuer(issuer string) ValidationOption {
+	return func(opts *validationOptions) {
+		opts.issuer = issuer
+	}
+}
+
+// WithExpectedAudiences sets the accepted "aud" claims, it defaults to the client's ClientId.
+// The token is accepted when its audience contains any of them, and its "azp" claim, if
+// present, must be one of them too. It is meant for the shared applications
+// (Application.IsShared), whose tokens are issued to more than one client ID.
+// Calling it without any audience disables the audience and "azp" checks.
+func WithExpectedAudiences(audiences ...string) ValidationOption {
+	return func(opts *validationOptions) {
+		opts.audiences = audiences
+	}
+}
+
+// WithRequiredScopes requires the token's "scope" claim to contain all the given scopes.
+func WithRequiredScopes(scopes ...string) ValidationOption {
+	return func(opts *validationOptions) {
+		opts.requiredScopes = append(opts.requiredScopes, scopes...)
+	}
+}
+
+// WithMaxTokenAge rejects the tokens issued ("iat") longer than maxAge ago, even if they are
+// not expired yet.
+func WithMaxTokenAge(maxAge time.Duration) ValidationOption {
+	return func(opts *validationOptions) {
+		opts.maxTokenAge = maxAge
+	}
+}
+
+// WithLeeway sets the allowed clock skew between Casdoor and this machine for the
+// "exp", "nbf" and "iat" checks.
+func WithLeeway(leeway time.Duration) ValidationOption {
+	return func(opts *validationOptions) {
+		opts.leeway = leeway
+	}
+}
+
+// WithRejectRefreshTokens rejects the refresh tokens, so that a refresh token can't be
+// used as an access token against a resource server.
+func WithRejectRefreshTokens() ValidationOption {
+	return func(opts *validationOptions) {
+		opts.rejectRefreshTokens = true
+	}
+}
+
+func (c *Client) getValidationOptions(opts ...ValidationOption) *validationOptions {
+	options := &validationOptions{
+		issuer:    c.Endpoint,
+		audiences: []string{c.ClientId},
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
+
+// ParseJwtTokenWithOptions is like ParseJwtToken() but also validates the claims of the token:
+// by default the "iss" claim must be the client's Endpoint and the "aud" claim must contain
+// the client's ClientId. More checks can be added with the ValidationOption functions:
+//
+//	claims, err := client.ParseJwtTokenWithOptions(token,
+//		casdoorsdk.WithLeeway(30*time.Second),
+//		casdoorsdk.WithRequiredScopes("profile"),
+//		casdoorsdk.WithRejectRefreshTokens())
+//
+// A rejected claim is reported as a *ValidationError.
+func (c *Client) ParseJwtTokenWithOptions(token string, opts ...ValidationOption) (*Claims, error) {
+	claims := &Claims{}
+	_, err := c.parseJwtTokenSignature(token, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.getValidationOptions(opts...).validate(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// validatedClaims are the claims checked by the validation options, which all the token
+// formats of Casdoor have, see ParseJwtTokenInto() for the formats other than Claims.
+type validatedClaims struct {
+	TokenType string `json:"tokenType,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	Azp       string `json:"azp,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func (claims *validatedClaims) isRefreshToken() bool {
+	return claims.TokenType == "refresh-token"
+}
+
+func (opts *validationOptions) validate(claims *Claims) error {
+	return opts.validateClaims(&validatedClaims{
+		TokenType:        claims.TokenType,
+		Scope:            claims.Scope,
+		Azp:              claims.Azp,
+		RegisteredClaims: claims.RegisteredClaims,
+	})
+}
+
+func (opts *validationOptions) validateClaims(claims *validatedClaims) error {
+	err := opts.validateTime(&claims.RegisteredClaims, jwt.TimeFunc())
+	if err != nil {
+		return err
+	}
+
+	if opts.issuer != "" && !IsSameIssuer(claims.Issuer, opts.issuer) {
+		return newValidationError(ErrInvalidIssuer, "expected %q, got %q", opts.issuer, claims.Issuer)
+	}
+
+	err = opts.validateAudience(claims.Audience, claims.Azp)
+	if err != nil {
+		return err
+	}
+
+	scopes := strings.Fields(claims.Scope)
+	for _, requiredScope := range opts.requiredScopes {
+		if !containsString(scopes, requiredScope) {
+			return newValidationError(ErrMissingScope, "%q", requiredScope)
+		}
+	}
+
+	if opts.rejectRefreshTokens && claims.isRefreshToken() {
+		return &ValidationError{Err: ErrRefreshTokenNotAllowed}
+	}
+
+	return nil
+}
+
+func (opts *validationOptions) validateTime(claims *jwt.RegisteredClaims, now time.Time) error {
+	if claims.ExpiresAt != nil && !now.Before(claims.ExpiresAt.Add(opts.leeway)) {
+		return newValidationError(ErrTokenExpired, "expired at %s", claims.ExpiresAt.Format(time.RFC3339))
+	}
+
+	if claims.NotBefore != nil && now.Add(opts.leeway).Before(claims.NotBefore.Time) {
+		return newValidationError(ErrTokenNotValidYet, "valid from %s", claims.NotBefore.Format(time.RFC3339))
+	}
+
+	if claims.IssuedAt != nil && now.Add(opts.leeway).Before(claims.IssuedAt.Time) {
+		return newValidationError(ErrTokenUsedBeforeIssued, "issued at %s", claims.IssuedAt.Format(time.RFC3339))
+	}
+
+	if opts.maxTokenAge > 0 {
+		if claims.IssuedAt == nil {
+			return newValidationError(ErrTokenTooOld, "the \"iat\" claim is missing")
+		}
+
+		if now.Sub(claims.IssuedAt.Time) > opts.maxTokenAge+opts.leeway {
+			return newValidationError(ErrTokenTooOld, "issued at %s", claims.IssuedAt.Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
+
+func (opts *validationOptions) validateAudience(audience jwt.ClaimStrings, azp string) error {
+	if len(opts.audiences) == 0 {
+		return nil
+	}
+
+	found := false
+	for _, aud := range audience {
+		if containsString(opts.audiences, aud) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return newValidationError(ErrInvalidAudience, "expected one of %v, got %v", opts.audiences, []string(audience))
+	}
+
+	if azp != "" && !containsString(opts.audiences, azp) {
+		return newValidationError(ErrInvalidAuthorizedParty, "expected one of %v, got %q", opts.audiences, azp)
+	}
+
+	return nil
+}
+
+// IsSameIssuer compares two issuers, ignoring a trailing "/".
+func IsSameIssuer(issuer string, expectedIssuer string) bool {
+	return strings.TrimRight(issuer, "/") == strings.TrimRight(expectedIssuer, "/")
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,240 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// testSigner signs tokens like a Casdoor server whose certificate is Certificate.
+type testSigner struct {
+	key         *rsa.PrivateKey
+	Certificate string
+}
+
+func newTestSigner(t *testing.T) *testSigner {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Casdoor Cert"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	return &testSigner{
+		key:         key,
+		Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+	}
+}
+
+func (s *testSigner) sign(t *testing.T, claims jwt.Claims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(s.key)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return token
+}
+
+func newTestClaims(endpoint string, clientId string) *Claims {
+	now := time.Now()
+	return &Claims{
+		User:      User{Owner: "built-in", Name: "admin"},
+		TokenType: "access-token",
+		Scope:     "openid profile",
+		Azp:       clientId,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    endpoint,
+			Subject:   "admin-id",
+			Audience:  []string{clientId},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+}
+
+func TestParseJwtTokenWithOptions(t *testing.T) {
+	signer := newTestSigner(t)
+	client := NewClient("http://localhost:8000", "client-id", "client-secret", signer.Certificate, "built-in", "app-built-in")
+
+	tests := []struct {
+		name   string
+		modify func(claims *Claims)
+		opts   []ValidationOption
+		err    error
+	}{
+		{name: "valid"},
+		{
+			name:   "issuer with trailing slash",
+			modify: func(claims *Claims) { claims.Issuer = "http://localhost:8000/" },
+		},
+		{
+			name:   "wrong issuer",
+			modify: func(claims *Claims) { claims.Issuer = "http://evil.example.com" },
+			err:    ErrInvalidIssuer,
+		},
+		{
+			name:   "wrong audience",
+			modify: func(claims *Claims) { claims.Audience = []string{"other-client"} },
+			err:    ErrInvalidAudience,
+		},
+		{
+			name: "shared application audience",
+			modify: func(claims *Claims) {
+				claims.Audience = []string{"client-id-org-acme"}
+				claims.Azp = "client-id-org-acme"
+			},
+			opts: []ValidationOption{WithExpectedAudiences("client-id", "client-id-org-acme")},
+		},
+		{
+			name:   "wrong azp",
+			modify: func(claims *Claims) { claims.Azp = "other-client" },
+			err:    ErrInvalidAuthorizedParty,
+		},
+		{
+			name:   "expired",
+			modify: func(claims *Claims) { claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute)) },
+			err:    ErrTokenExpired,
+		},
+		{
+			name:   "expired within leeway",
+			modify: func(claims *Claims) { claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute)) },
+			opts:   []ValidationOption{WithLeeway(2 * time.Minute)},
+		},
+		{
+			name:   "issued in the future",
+			modify: func(claims *Claims) { claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(time.Minute)) },
+			err:    ErrTokenUsedBeforeIssued,
+		},
+		{
+			name:   "too old",
+			modify: func(claims *Claims) { claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Hour)) },
+			opts:   []ValidationOption{WithMaxTokenAge(10 * time.Minute)},
+			err:    ErrTokenTooOld,
+		},
+		{
+			name: "required scopes",
+			opts: []ValidationOption{WithRequiredScopes("openid", "profile")},
+		},
+		{
+			name: "missing scope",
+			opts: []ValidationOption{WithRequiredScopes("email")},
+			err:  ErrMissingScope,
+		},
+		{
+			name:   "refresh token",
+			modify: func(claims *Claims) { claims.TokenType = "refresh-token" },
+			opts:   []ValidationOption{WithRejectRefreshTokens()},
+			err:    ErrRefreshTokenNotAllowed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claims := newTestClaims(client.Endpoint, client.ClientId)
+			if test.modify != nil {
+				test.modify(claims)
+			}
+
+			parsed, err := client.ParseJwtTokenWithOptions(signer.sign(t, claims), test.opts...)
+			if test.err == nil {
+				if err != nil {
+					t.Fatalf("Failed to parse token: %v", err)
+				}
+				if parsed.Name != "admin" {
+					t.Fatalf("Parsed claims mismatch: %s != admin", parsed.Name)
+				}
+				return
+			}
+
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Expected error %v, got %v", test.err, err)
+			}
+			var validationError *ValidationError
+			if !errors.As(err, &validationError) {
+				t.Fatalf("Expected a *ValidationError, got %T", err)
+			}
+		})
+	}
+}
+
+func TestParseJwtTokenWithOptionsBadSignature(t *testing.T) {
+	signer := newTestSigner(t)
+	client := NewClient("http://localhost:8000", "client-id", "client-secret", newTestSigner(t).Certificate, "built-in", "app-built-in")
+
+	_, err := client.ParseJwtTokenWithOptions(signer.sign(t, newTestClaims(client.Endpoint, client.ClientId)))
+	if err == nil {
+		t.Fatalf("Expected the token signed by another key to be rejected")
+	}
+}
+
+func TestParseJwtTokenEcdsa(t *testing.T) {
+	for _, test := range []struct {
+		curve  elliptic.Curve
+		method jwt.SigningMethod
+	}{
+		{elliptic.P256(), jwt.SigningMethodES256},
+		{elliptic.P384(), jwt.SigningMethodES384},
+		{elliptic.P521(), jwt.SigningMethodES512},
+	} {
+		key, err := ecdsa.GenerateKey(test.curve, rand.Reader)
+		if err != nil {
+			t.Fatalf("Failed to generate key: %v", err)
+		}
+
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "Casdoor Cert"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		if err != nil {
+			t.Fatalf("Failed to create certificate: %v", err)
+		}
+
+		certificate := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+		client := NewClient("http://localhost:8000", "client-id", "client-secret", certificate, "built-in", "app-built-in")
+		token, err := jwt.NewWithClaims(test.method, newTestClaims(client.Endpoint, client.ClientId)).SignedString(key)
+		if err != nil {
+			t.Fatalf("Failed to sign the %s token: %v", test.method.Alg(), err)
+		}
+
+		claims, err := client.ParseJwtToken(token)
+		if err != nil || claims.Name != "admin" {
+			t.Errorf("Failed to parse the %s token: %v", test.method.Alg(), err)
+		}
+	}
+}
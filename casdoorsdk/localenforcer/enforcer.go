@@ -0,0 +1,461 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package localenforcer evaluates the Casbin requests of a Casdoor enforcer in-process, instead
+// of calling Casdoor's "enforce" API for each of them. The model and the policies of the
+// enforcer are loaded from Casdoor into an embedded Casbin engine, and reloaded periodically
+// or when a Casdoor webhook reports a change:
+//
+//	e := localenforcer.New(client, "built-in/enforcer", localenforcer.WithRemoteFallback(5*time.Minute))
+//	err := e.Start(ctx)
+//	mux.Handle("/casdoor/webhook", e.WebhookHandler(webhookSecret))
+//
+//	allowed, err := e.Enforce("alice", "data1", "read")
+//
+// It is a separate module, so that the SDK itself doesn't depend on Casbin:
+//
+//	go get github.com/casdoor/casdoor-go-sdk/casdoorsdk/localenforcer
+package localenforcer
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+)
+
+// ErrNotLoaded is returned by Enforce() before the first load of the policies, when the remote
+// fallback is disabled.
+var ErrNotLoaded = errors.New("the policies of the enforcer are not loaded")
+
+const (
+	defaultRefreshInterval    = time.Minute
+	defaultMinTriggerInterval = 5 * time.Second
+)
+
+// Option is a function type for configuring an Enforcer.
+type Option func(*Enforcer)
+
+// WithRefreshInterval sets how often Start() reloads the policies, the default is one minute.
+// Zero disables the periodic reloads, leaving the ones of Trigger() and WebhookHandler().
+func WithRefreshInterval(interval time.Duration) Option {
+	return func(e *Enforcer) {
+		e.refreshInterval = interval
+	}
+}
+
+// WithMinTriggerInterval sets the minimum time between a reload and the next one asked by
+// Trigger() or WebhookHandler(), the default is 5 seconds. The triggers received meanwhile are
+// coalesced into a single reload at the end of the interval.
+func WithMinTriggerInterval(interval time.Duration) Option {
+	return func(e *Enforcer) {
+		e.minTriggerInterval = interval
+	}
+}
+
+// WithPolicyFilters only loads the policies matching the filters, with GetFilteredPolicies(),
+// for the enforcers with too many policies for the memory of the application.
+func WithPolicyFilters(filters ...*casdoorsdk.PolicyFilter) Option {
+	return func(e *Enforcer) {
+		e.filters = filters
+	}
+}
+
+// WithRemoteFallback calls Casdoor's "enforce" API instead of evaluating the request locally
+// when the policies are not loaded yet, or were last loaded more than maxStaleness ago. When
+// that call fails, the stale policies are used, if any, see Stats.StaleEnforcements.
+func WithRemoteFallback(maxStaleness time.Duration) Option {
+	return func(e *Enforcer) {
+		e.remoteFallback = true
+		e.maxStaleness = maxStaleness
+	}
+}
+
+// WithErrorHandler sets the function called with the errors of the background reloads, which
+// are otherwise only reported by Stats().
+func WithErrorHandler(errorHandler func(err error)) Option {
+	return func(e *Enforcer) {
+		e.errorHandler = errorHandler
+	}
+}
+
+// Enforcer evaluates the requests of a Casdoor enforcer locally, see New().
+type Enforcer struct {
+	client             *casdoorsdk.Client
+	enforcerId         string
+	refreshInterval    time.Duration
+	minTriggerInterval time.Duration
+	filters            []*casdoorsdk.PolicyFilter
+	remoteFallback     bool
+	maxStaleness       time.Duration
+	errorHandler       func(err error)
+
+	trigger chan struct{}
+	// refreshMu serializes the loads, so that an older load can't replace a newer one
+	refreshMu sync.Mutex
+
+	mu          sync.RWMutex
+	engine      *casbin.Enforcer
+	loadedTime  time.Time
+	policyCount int
+	lastError   error
+
+	refreshes          atomic.Int64
+	refreshErrors      atomic.Int64
+	localEnforcements  atomic.Int64
+	remoteEnforcements atomic.Int64
+	staleEnforcements  atomic.Int64
+}
+
+// New returns an Enforcer of the Casdoor enforcer of the ID, "owner/name", whose policies are
+// loaded by Refresh() or Start().
+func New(client *casdoorsdk.Client, enforcerId string, opts ...Option) *Enforcer {
+	e := &Enforcer{
+		client:             client,
+		enforcerId:         enforcerId,
+		refreshInterval:    defaultRefreshInterval,
+		minTriggerInterval: defaultMinTriggerInterval,
+		trigger:            make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Refresh loads the model and the policies of the enforcer from Casdoor. The previous ones are
+// kept when it fails.
+func (e *Enforcer) Refresh() error {
+	e.refreshMu.Lock()
+	defer e.refreshMu.Unlock()
+
+	e.refreshes.Add(1)
+
+	engine, policyCount, err := e.load()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err != nil {
+		e.refreshErrors.Add(1)
+		e.lastError = err
+		return err
+	}
+
+	e.engine = engine
+	e.loadedTime = time.Now()
+	e.policyCount = policyCount
+	e.lastError = nil
+	return nil
+}
+
+func (e *Enforcer) load() (*casbin.Enforcer, int, error) {
+	enforcer, err := e.client.GetEnforcer(e.enforcerId)
+	if err != nil {
+		return nil, 0, err
+	}
+	if enforcer == nil {
+		return nil, 0, fmt.Errorf("the enforcer %s doesn't exist", e.enforcerId)
+	}
+
+	m, err := e.client.GetModel(enforcer.Model)
+	if err != nil {
+		return nil, 0, err
+	}
+	if m == nil {
+		return nil, 0, fmt.Errorf("the model %s of the enforcer %s doesn't exist", enforcer.Model, e.enforcerId)
+	}
+
+	var rules []*casdoorsdk.CasbinRule
+	if len(e.filters) != 0 {
+		rules, err = e.client.GetFilteredPolicies(e.enforcerId, e.filters)
+	} else {
+		rules, err = e.client.GetPolicies(e.enforcerId, "")
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	casbinModel, err := model.NewModelFromString(m.ModelText)
+	if err != nil {
+		return nil, 0, fmt.Errorf("the model %s is invalid: %w", enforcer.Model, err)
+	}
+
+	for _, rule := range rules {
+		err = persist.LoadPolicyArray(getPolicyArray(rule), casbinModel)
+		if err != nil {
+			return nil, 0, fmt.Errorf("the policy %v is invalid: %w", getPolicyArray(rule), err)
+		}
+	}
+
+	engine, err := casbin.NewEnforcer(casbinModel)
+	if err != nil {
+		return nil, 0, err
+	}
+	err = engine.BuildRoleLinks()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return engine, len(rules), nil
+}
+
+// getPolicyArray returns the rule as a line of a policy file: its ptype and its values, without
+// the trailing empty ones.
+func getPolicyArray(rule *casdoorsdk.CasbinRule) []string {
+	res := []string{rule.Ptype, rule.V0, rule.V1, rule.V2, rule.V3, rule.V4, rule.V5}
+	for len(res) > 1 && res[len(res)-1] == "" {
+		res = res[:len(res)-1]
+	}
+
+	return res
+}
+
+// Start loads the policies and then reloads them in the background until the context is done,
+// every refresh interval and at each Trigger(), at most once per minimum trigger interval. The
+// background reloads are started even when the first load fails, whose error is returned.
+func (e *Enforcer) Start(ctx context.Context) error {
+	err := e.Refresh()
+	lastRefresh := time.Now()
+
+	go func() {
+		var tick <-chan time.Time
+		if e.refreshInterval > 0 {
+			ticker := time.NewTicker(e.refreshInterval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick:
+			case <-e.trigger:
+				wait := e.minTriggerInterval - time.Since(lastRefresh)
+				if wait > 0 {
+					timer := time.NewTimer(wait)
+					select {
+					case <-ctx.Done():
+						timer.Stop()
+						return
+					case <-timer.C:
+					}
+				}
+			}
+
+			err := e.Refresh()
+			lastRefresh = time.Now()
+			if err != nil && e.errorHandler != nil {
+				e.errorHandler(err)
+			}
+		}
+	}()
+
+	return err
+}
+
+// Trigger asks the background loop of Start() to reload the policies. The triggers received
+// during a reload are coalesced into a single next reload.
+func (e *Enforcer) Trigger() {
+	select {
+	case e.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// policyObjects are the objects of the Casdoor records whose changes may change the policies.
+var policyObjects = []string{"polic", "model", "enforcer", "adapter", "permission", "role", "group"}
+
+// WebhookHandler returns the handler of a Casdoor webhook, which triggers a reload when the
+// record it receives is about the policies, the models, the enforcers, the adapters, the
+// permissions, the roles or the groups. The requests must carry the "Authorization: Bearer"
+// header with the secret, to be added to the headers of the webhook in Casdoor, all of them
+// are rejected when the secret is empty. The policies are reloaded from Casdoor, so the
+// content of the records isn't trusted, and the reloads are rate limited, see
+// WithMinTriggerInterval().
+func (e *Enforcer) WebhookHandler(secret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if secret == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+secret)) != 1 {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		var record casdoorsdk.Record
+		err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&record)
+		if err != nil || record.Action == "" {
+			http.Error(w, "the webhook payload should be a record", http.StatusBadRequest)
+			return
+		}
+
+		action := strings.ToLower(record.Action)
+		for _, object := range policyObjects {
+			if strings.Contains(action, object) {
+				e.Trigger()
+				break
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// getEngine returns the Casbin engine to evaluate the requests locally. The second result is
+// true when they must be sent to Casdoor instead, the engine is then the stale one, if any.
+func (e *Enforcer) getEngine() (*casbin.Enforcer, bool, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.engine == nil {
+		if e.remoteFallback {
+			return nil, true, nil
+		}
+		if e.lastError != nil {
+			return nil, false, fmt.Errorf("%w: %v", ErrNotLoaded, e.lastError)
+		}
+		return nil, false, ErrNotLoaded
+	}
+
+	if e.remoteFallback && time.Since(e.loadedTime) > e.maxStaleness {
+		return e.engine, true, nil
+	}
+
+	return e.engine, false, nil
+}
+
+// Enforce returns whether the request, like ("alice", "data1", "read"), is allowed by the
+// policies of the enforcer, like Client.Enforce() with the enforcer's ID.
+func (e *Enforcer) Enforce(rvals ...interface{}) (bool, error) {
+	engine, remote, err := e.getEngine()
+	if err != nil {
+		return false, err
+	}
+
+	if remote {
+		allowed, err := e.client.Enforce("", "", "", e.enforcerId, "", rvals)
+		if err == nil || engine == nil {
+			e.remoteEnforcements.Add(1)
+			return allowed, err
+		}
+
+		// Casdoor is down, the stale policies are better than none
+		e.staleEnforcements.Add(1)
+		return engine.Enforce(rvals...)
+	}
+
+	e.localEnforcements.Add(1)
+	return engine.Enforce(rvals...)
+}
+
+// BatchEnforce returns whether each of the requests is allowed, see Enforce().
+func (e *Enforcer) BatchEnforce(requests []casdoorsdk.CasbinRequest) ([]bool, error) {
+	engine, remote, err := e.getEngine()
+	if err != nil {
+		return nil, err
+	}
+
+	if remote {
+		res, err := e.remoteBatchEnforce(requests)
+		if err == nil || engine == nil {
+			e.remoteEnforcements.Add(int64(len(requests)))
+			return res, err
+		}
+
+		e.staleEnforcements.Add(int64(len(requests)))
+		return localBatchEnforce(engine, requests)
+	}
+
+	e.localEnforcements.Add(int64(len(requests)))
+	return localBatchEnforce(engine, requests)
+}
+
+func (e *Enforcer) remoteBatchEnforce(requests []casdoorsdk.CasbinRequest) ([]bool, error) {
+	res, err := e.client.BatchEnforce("", "", "", e.enforcerId, "", requests)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) != 1 || len(res[0]) != len(requests) {
+		return nil, errors.New("Casdoor returned an unexpected number of results")
+	}
+
+	return res[0], nil
+}
+
+func localBatchEnforce(engine *casbin.Enforcer, requests []casdoorsdk.CasbinRequest) ([]bool, error) {
+	casbinRequests := make([][]interface{}, len(requests))
+	for i, request := range requests {
+		casbinRequests[i] = request
+	}
+
+	return engine.BatchEnforce(casbinRequests)
+}
+
+// Stats are the metrics of an Enforcer.
+type Stats struct {
+	// LoadedTime is when the policies were last loaded, zero when they never were.
+	LoadedTime time.Time
+	// Staleness is how long ago the policies were last loaded.
+	Staleness   time.Duration
+	PolicyCount int
+	// Refreshes counts the loads of the policies, of which RefreshErrors failed.
+	Refreshes     int64
+	RefreshErrors int64
+	// LastError is the error of the last load, nil when it succeeded.
+	LastError error
+	// LocalEnforcements and RemoteEnforcements count the requests evaluated in-process and
+	// by Casdoor. StaleEnforcements counts the requests evaluated in-process with the stale
+	// policies, because the call to Casdoor of WithRemoteFallback() failed.
+	LocalEnforcements  int64
+	RemoteEnforcements int64
+	StaleEnforcements  int64
+}
+
+// Stats returns the current metrics of the enforcer.
+func (e *Enforcer) Stats() Stats {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	stats := Stats{
+		LoadedTime:         e.loadedTime,
+		PolicyCount:        e.policyCount,
+		Refreshes:          e.refreshes.Load(),
+		RefreshErrors:      e.refreshErrors.Load(),
+		LastError:          e.lastError,
+		LocalEnforcements:  e.localEnforcements.Load(),
+		RemoteEnforcements: e.remoteEnforcements.Load(),
+		StaleEnforcements:  e.staleEnforcements.Load(),
+	}
+	if !e.loadedTime.IsZero() {
+		stats.Staleness = time.Since(e.loadedTime)
+	}
+
+	return stats
+}
@@ -0,0 +1,378 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localenforcer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+)
+
+const testModelText = `[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act
+
+[role_definition]
+g = _, _
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = g(r.sub, p.sub) && r.obj == p.obj && r.act == p.act`
+
+// testCasdoor answers the enforcer APIs of Casdoor with its policies.
+type testCasdoor struct {
+	mu              sync.Mutex
+	policies        []*casdoorsdk.CasbinRule
+	down            bool
+	policyRequests  int
+	enforceRequests int
+}
+
+func (c *testCasdoor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if c.down {
+		_ = json.NewEncoder(w).Encode(casdoorsdk.Response{Status: "error", Msg: "Casdoor is down"})
+		return
+	}
+
+	response := casdoorsdk.Response{Status: "ok"}
+	switch r.URL.Path {
+	case "/api/get-enforcer":
+		response.Data = &casdoorsdk.Enforcer{Owner: "built-in", Name: "enforcer", Model: "built-in/model"}
+	case "/api/get-model":
+		if r.URL.Query().Get("id") != "built-in/model" {
+			response.Data = nil
+		} else {
+			response.Data = &casdoorsdk.Model{Owner: "built-in", Name: "model", ModelText: testModelText}
+		}
+	case "/api/get-policies":
+		c.policyRequests++
+		response.Data = c.policies
+	case "/api/get-filtered-policies":
+		var filters []*casdoorsdk.PolicyFilter
+		_ = json.NewDecoder(r.Body).Decode(&filters)
+		var policies []*casdoorsdk.CasbinRule
+		for _, policy := range c.policies {
+			if policy.Ptype == filters[0].Ptype {
+				policies = append(policies, policy)
+			}
+		}
+		response.Data = policies
+	case "/api/enforce":
+		c.enforceRequests++
+		var request []interface{}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		response.Data = []bool{request[0] == "alice"}
+	case "/api/batch-enforce":
+		c.enforceRequests++
+		var requests [][]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&requests)
+		var res []bool
+		for _, request := range requests {
+			res = append(res, request[0] == "alice")
+		}
+		response.Data = [][]bool{res}
+	default:
+		response = casdoorsdk.Response{Status: "error", Msg: "unexpected request " + r.URL.Path}
+	}
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func newTestCasdoor(t *testing.T) (*testCasdoor, *casdoorsdk.Client) {
+	casdoor := &testCasdoor{
+		policies: []*casdoorsdk.CasbinRule{
+			{Ptype: "p", V0: "admin", V1: "data1", V2: "read"},
+			{Ptype: "p", V0: "bob", V1: "data2", V2: "write"},
+			{Ptype: "g", V0: "alice", V1: "admin"},
+		},
+	}
+	server := httptest.NewServer(casdoor)
+	t.Cleanup(server.Close)
+
+	return casdoor, casdoorsdk.NewClient(server.URL, "client-id", "client-secret", "", "built-in", "app-built-in")
+}
+
+func TestEnforce(t *testing.T) {
+	casdoor, client := newTestCasdoor(t)
+	e := New(client, "built-in/enforcer")
+
+	_, err := e.Enforce("alice", "data1", "read")
+	if !errors.Is(err, ErrNotLoaded) {
+		t.Errorf("Expected ErrNotLoaded, got %v", err)
+	}
+
+	err = e.Refresh()
+	if err != nil {
+		t.Fatalf("Failed to load the policies: %v", err)
+	}
+
+	tests := []struct {
+		request  []interface{}
+		expected bool
+	}{
+		{[]interface{}{"alice", "data1", "read"}, true},
+		{[]interface{}{"alice", "data1", "write"}, false},
+		{[]interface{}{"bob", "data2", "write"}, true},
+		{[]interface{}{"bob", "data1", "read"}, false},
+	}
+	for _, test := range tests {
+		allowed, err := e.Enforce(test.request...)
+		if err != nil || allowed != test.expected {
+			t.Errorf("Expected %v for %v, got %v, %v", test.expected, test.request, allowed, err)
+		}
+	}
+
+	results, err := e.BatchEnforce([]casdoorsdk.CasbinRequest{{"alice", "data1", "read"}, {"bob", "data1", "read"}})
+	if err != nil || len(results) != 2 || !results[0] || results[1] {
+		t.Errorf("Unexpected batch results: %v, %v", results, err)
+	}
+
+	// A failed refresh keeps the loaded policies
+	casdoor.mu.Lock()
+	casdoor.down = true
+	casdoor.mu.Unlock()
+	err = e.Refresh()
+	if err == nil {
+		t.Errorf("Expected an error when Casdoor is down")
+	}
+	allowed, err := e.Enforce("alice", "data1", "read")
+	if err != nil || !allowed {
+		t.Errorf("Expected the loaded policies to be kept, got %v, %v", allowed, err)
+	}
+
+	stats := e.Stats()
+	if stats.PolicyCount != 3 || stats.Refreshes != 2 || stats.RefreshErrors != 1 || stats.LastError == nil || stats.LocalEnforcements != 7 || stats.RemoteEnforcements != 0 || stats.LoadedTime.IsZero() {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	// The filtered policies
+	casdoor.down = false
+	e = New(client, "built-in/enforcer", WithPolicyFilters(&casdoorsdk.PolicyFilter{Ptype: "p"}))
+	err = e.Refresh()
+	if err != nil {
+		t.Fatalf("Failed to load the filtered policies: %v", err)
+	}
+	allowed, err = e.Enforce("alice", "data1", "read")
+	if err != nil || allowed || e.Stats().PolicyCount != 2 {
+		t.Errorf("Expected alice to have no role with the filtered policies, got %v, %v", allowed, err)
+	}
+}
+
+func TestRemoteFallback(t *testing.T) {
+	casdoor, client := newTestCasdoor(t)
+	e := New(client, "built-in/enforcer", WithRemoteFallback(time.Hour))
+
+	// Before the first load
+	allowed, err := e.Enforce("alice", "data9", "read")
+	if err != nil || !allowed || casdoor.enforceRequests != 1 {
+		t.Errorf("Expected the remote result, got %v, %v", allowed, err)
+	}
+	results, err := e.BatchEnforce([]casdoorsdk.CasbinRequest{{"alice", "data9", "read"}, {"bob", "data9", "read"}})
+	if err != nil || len(results) != 2 || !results[0] || results[1] {
+		t.Errorf("Unexpected remote batch results: %v, %v", results, err)
+	}
+
+	err = e.Refresh()
+	if err != nil {
+		t.Fatalf("Failed to load the policies: %v", err)
+	}
+	allowed, _ = e.Enforce("alice", "data9", "read")
+	if allowed || casdoor.enforceRequests != 2 {
+		t.Errorf("Expected the local result, got %v", allowed)
+	}
+
+	// When the policies are stale
+	e.maxStaleness = 0
+	time.Sleep(time.Millisecond)
+	allowed, _ = e.Enforce("alice", "data9", "read")
+	if !allowed || casdoor.enforceRequests != 3 {
+		t.Errorf("Expected the remote result for stale policies, got %v", allowed)
+	}
+
+	// When Casdoor is down too, the stale policies are used
+	casdoor.mu.Lock()
+	casdoor.down = true
+	casdoor.mu.Unlock()
+	allowed, err = e.Enforce("alice", "data1", "read")
+	if err != nil || !allowed {
+		t.Errorf("Expected the stale local result, got %v, %v", allowed, err)
+	}
+	results, err = e.BatchEnforce([]casdoorsdk.CasbinRequest{{"alice", "data9", "read"}, {"bob", "data2", "write"}})
+	if err != nil || len(results) != 2 || results[0] || !results[1] {
+		t.Errorf("Unexpected stale batch results: %v, %v", results, err)
+	}
+
+	stats := e.Stats()
+	if stats.LocalEnforcements != 1 || stats.RemoteEnforcements != 4 || stats.StaleEnforcements != 3 || stats.Staleness <= 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	// Without policies, the error of Casdoor is returned
+	e = New(client, "built-in/enforcer", WithRemoteFallback(time.Hour))
+	_, err = e.Enforce("alice", "data1", "read")
+	if err == nil {
+		t.Errorf("Expected an error when Casdoor is down without policies")
+	}
+}
+
+func TestWebhookRefresh(t *testing.T) {
+	casdoor, client := newTestCasdoor(t)
+	refreshed := make(chan error, 10)
+	e := New(client, "built-in/enforcer", WithRefreshInterval(0), WithMinTriggerInterval(0), WithErrorHandler(func(err error) {
+		refreshed <- err
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := e.Start(ctx)
+	if err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+
+	allowed, _ := e.Enforce("carol", "data1", "read")
+	if allowed {
+		t.Errorf("Expected carol to be denied")
+	}
+
+	handler := e.WebhookHandler("webhook-secret")
+	post := func(authorization string, body string) int {
+		r := httptest.NewRequest("POST", "/webhook", strings.NewReader(body))
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	casdoor.mu.Lock()
+	casdoor.policies = append(casdoor.policies, &casdoorsdk.CasbinRule{Ptype: "g", V0: "carol", V1: "admin"})
+	casdoor.mu.Unlock()
+
+	// The requests without the secret, the records of other objects and the invalid payloads
+	// don't reload the policies
+	tests := []struct {
+		authorization string
+		body          string
+		expected      int
+	}{
+		{"", `{"action":"add-policy"}`, http.StatusUnauthorized},
+		{"Bearer wrong-secret", `{"action":"add-policy"}`, http.StatusUnauthorized},
+		{"Bearer webhook-secret", `{"action":"update-user"}`, http.StatusOK},
+		{"Bearer webhook-secret", `not a record`, http.StatusBadRequest},
+		{"Bearer webhook-secret", `{}`, http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		code := post(test.authorization, test.body)
+		if code != test.expected {
+			t.Errorf("Expected %d for %q, %s, got %d", test.expected, test.authorization, test.body, code)
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	if e.Stats().PolicyCount != 3 {
+		t.Errorf("Expected the policies not to be reloaded")
+	}
+
+	if code := post("Bearer webhook-secret", `{"action":"add-policy"}`); code != http.StatusOK {
+		t.Errorf("Unexpected status: %d", code)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for e.Stats().PolicyCount != 4 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	allowed, _ = e.Enforce("carol", "data1", "read")
+	if !allowed {
+		t.Errorf("Expected carol to be allowed after the webhook")
+	}
+	casdoor.mu.Lock()
+	policyRequests := casdoor.policyRequests
+	casdoor.mu.Unlock()
+	if policyRequests != 2 {
+		t.Errorf("Expected 2 loads of the policies, got %d", policyRequests)
+	}
+
+	// The errors of the background reloads are reported
+	casdoor.mu.Lock()
+	casdoor.down = true
+	casdoor.mu.Unlock()
+	e.Trigger()
+	select {
+	case err = <-refreshed:
+		if err == nil {
+			t.Errorf("Expected an error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Expected the error of the reload")
+	}
+
+	// An empty secret rejects every request
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/webhook", strings.NewReader(`{"action":"add-policy"}`))
+	r.Header.Set("Authorization", "Bearer ")
+	e.WebhookHandler("").ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected %d with an empty secret, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestMinTriggerInterval(t *testing.T) {
+	casdoor, client := newTestCasdoor(t)
+	e := New(client, "built-in/enforcer", WithRefreshInterval(0), WithMinTriggerInterval(200*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := e.Start(ctx)
+	if err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+
+	// The triggers within the interval are coalesced into a single reload at its end
+	for i := 0; i < 10; i++ {
+		e.Trigger()
+	}
+	time.Sleep(50 * time.Millisecond)
+	if refreshes := e.Stats().Refreshes; refreshes != 1 {
+		t.Errorf("Expected no reload before the end of the interval, got %d refreshes", refreshes)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for e.Stats().Refreshes != 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	casdoor.mu.Lock()
+	policyRequests := casdoor.policyRequests
+	casdoor.mu.Unlock()
+	if policyRequests != 2 {
+		t.Errorf("Expected 2 loads of the policies, got %d", policyRequests)
+	}
+}
@@ -0,0 +1,24 @@
+module github.com/casdoor/casdoor-go-sdk/casdoorsdk/localenforcer
+
+go 1.23.0
+
+require (
+	github.com/casbin/casbin/v2 v2.135.0
+	github.com/casdoor/casdoor-go-sdk v1.21.0
+)
+
+require (
+	github.com/bmatcuk/doublestar/v4 v4.6.1 // indirect
+	github.com/casbin/govaluate v1.3.0 // indirect
+	github.com/golang-jwt/jwt/v4 v4.5.0 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	golang.org/x/net v0.16.0 // indirect
+	golang.org/x/oauth2 v0.13.0 // indirect
+	google.golang.org/appengine v1.6.7 // indirect
+	google.golang.org/protobuf v1.31.0 // indirect
+)
+
+// The replace only applies to the builds in this repository: the SDK must be tagged
+// with the version required above before this module, see "Sub-modules" in README.md.
+replace github.com/casdoor/casdoor-go-sdk => ../..
@@ -21,3 +21,7 @@ func Logout(accessToken string) error {
 func LogoutCurrentSession(accessToken string) error {
 	return globalClient.LogoutCurrentSession(accessToken)
 }
+
+func ParseLogoutToken(token string, opts ...ValidationOption) (*LogoutTokenClaims, error) {
+	return globalClient.ParseLogoutToken(token, opts...)
+}
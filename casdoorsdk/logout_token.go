@@ -0,0 +1,99 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// BackChannelLogoutEvent is the member of the "events" claim that identifies a logout token.
+const BackChannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+// defaultLogoutTokenMaxAge is how long a logout token is accepted after its "iat", unless
+// WithMaxTokenAge() is set.
+const defaultLogoutTokenMaxAge = 5 * time.Minute
+
+var ErrInvalidLogoutToken = errors.New("invalid logout token")
+
+// LogoutTokenClaims is the Claims of an OpenID Connect Back-Channel Logout token, see
+// https://openid.net/specs/openid-connect-backchannel-1_0.html#LogoutToken
+type LogoutTokenClaims struct {
+	Sid    string                     `json:"sid,omitempty"`
+	Events map[string]json.RawMessage `json:"events,omitempty"`
+	Nonce  interface{}                `json:"nonce,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// ParseLogoutToken verifies the "logout_token" of an OpenID Connect Back-Channel Logout request,
+// following the validation steps of its section 2.6: signature, "iss", "aud", "iat", "exp" when
+// present, "jti" is required, the "events" claim contains BackChannelLogoutEvent, "sub" or
+// "sid" is present, and there is no "nonce". The "iat" is accepted for 5 minutes, which can be
+// changed with WithMaxTokenAge().
+// The "jti" must also be checked against replays, which needs a store shared by the requests,
+// see the webauth package for a ready-made handler.
+func (c *Client) ParseLogoutToken(token string, opts ...ValidationOption) (*LogoutTokenClaims, error) {
+	claims := &LogoutTokenClaims{}
+	_, err := c.parseJwtTokenSignature(token, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.IssuedAt == nil {
+		return nil, newValidationError(ErrMissingClaim, "iat")
+	}
+
+	options := c.getValidationOptions(opts...)
+	if options.maxTokenAge == 0 {
+		options.maxTokenAge = defaultLogoutTokenMaxAge
+	}
+
+	err = options.validateTime(&claims.RegisteredClaims, jwt.TimeFunc())
+	if err != nil {
+		return nil, err
+	}
+
+	if options.issuer != "" && !IsSameIssuer(claims.Issuer, options.issuer) {
+		return nil, newValidationError(ErrInvalidIssuer, "expected %q, got %q", options.issuer, claims.Issuer)
+	}
+
+	err = options.validateAudience(claims.Audience, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.ID == "" {
+		return nil, newValidationError(ErrMissingClaim, "jti")
+	}
+
+	event, ok := claims.Events[BackChannelLogoutEvent]
+	var eventObject map[string]interface{}
+	if !ok || json.Unmarshal(event, &eventObject) != nil || eventObject == nil {
+		return nil, newValidationError(ErrInvalidLogoutToken, "the \"events\" claim should contain %q", BackChannelLogoutEvent)
+	}
+
+	if claims.Subject == "" && claims.Sid == "" {
+		return nil, newValidationError(ErrInvalidLogoutToken, "the \"sub\" or \"sid\" claim is required")
+	}
+
+	if claims.Nonce != nil {
+		return nil, newValidationError(ErrInvalidLogoutToken, "the \"nonce\" claim is prohibited")
+	}
+
+	return claims, nil
+}
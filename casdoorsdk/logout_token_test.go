@@ -0,0 +1,83 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func newTestLogoutClaims(endpoint string, clientId string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":    endpoint,
+		"sub":    "admin-id",
+		"aud":    clientId,
+		"iat":    time.Now().Unix(),
+		"jti":    "logout-jti",
+		"sid":    "session-id",
+		"events": map[string]interface{}{BackChannelLogoutEvent: map[string]interface{}{}},
+	}
+}
+
+func TestParseLogoutToken(t *testing.T) {
+	signer := newTestSigner(t)
+	endpoint := "https://door.example.com"
+	client := NewClient(endpoint, "client-id", "client-secret", signer.Certificate, "built-in", "app-built-in")
+
+	claims, err := client.ParseLogoutToken(signer.sign(t, newTestLogoutClaims(endpoint, "client-id")))
+	if err != nil {
+		t.Fatalf("Failed to parse logout token: %v", err)
+	}
+	if claims.Subject != "admin-id" || claims.Sid != "session-id" || claims.ID != "logout-jti" {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(jwt.MapClaims)
+		err    error
+	}{
+		{"wrong issuer", func(c jwt.MapClaims) { c["iss"] = "https://evil.com" }, ErrInvalidIssuer},
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "other-client" }, ErrInvalidAudience},
+		{"no iat", func(c jwt.MapClaims) { delete(c, "iat") }, ErrMissingClaim},
+		{"stale iat", func(c jwt.MapClaims) { c["iat"] = time.Now().Add(-time.Hour).Unix() }, ErrTokenTooOld},
+		{"no jti", func(c jwt.MapClaims) { delete(c, "jti") }, ErrMissingClaim},
+		{"no event", func(c jwt.MapClaims) { c["events"] = map[string]interface{}{} }, ErrInvalidLogoutToken},
+		{"event not an object", func(c jwt.MapClaims) {
+			c["events"] = map[string]interface{}{BackChannelLogoutEvent: "yes"}
+		}, ErrInvalidLogoutToken},
+		{"no sub nor sid", func(c jwt.MapClaims) { delete(c, "sub"); delete(c, "sid") }, ErrInvalidLogoutToken},
+		{"nonce", func(c jwt.MapClaims) { c["nonce"] = "nonce" }, ErrInvalidLogoutToken},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logoutClaims := newTestLogoutClaims(endpoint, "client-id")
+			test.modify(logoutClaims)
+			_, err := client.ParseLogoutToken(signer.sign(t, logoutClaims))
+			if !errors.Is(err, test.err) {
+				t.Errorf("Expected %v, got %v", test.err, err)
+			}
+		})
+	}
+
+	// A token signed with another key is rejected
+	_, err = client.ParseLogoutToken(newTestSigner(t).sign(t, newTestLogoutClaims(endpoint, "client-id")))
+	if err == nil {
+		t.Errorf("Expected an error for a token signed with another key")
+	}
+}
@@ -20,6 +20,8 @@ import (
 
 type MfaType string
 
+// EMAIL, SMS and APP are the MFA types as strings, for the mfaType arguments of Initiate() and
+// the other MFA functions. The MfaEnrollment functions take the MfaType* constants.
 const (
 	EMAIL string = "email"
 	SMS   string = "sms"
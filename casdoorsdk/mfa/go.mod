@@ -0,0 +1,5 @@
+module github.com/casdoor/casdoor-go-sdk/casdoorsdk/mfa
+
+go 1.23.0
+
+require rsc.io/qr v0.2.0
@@ -0,0 +1,224 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mfa
+
+import (
+	"bytes"
+	"encoding/base32"
+	"image/png"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateCode(t *testing.T) {
+	// The test vectors of RFC 6238, appendix B
+	secrets := map[Algorithm]string{
+		AlgorithmSha1:   base32.StdEncoding.EncodeToString([]byte("12345678901234567890")),
+		AlgorithmSha256: base32.StdEncoding.EncodeToString([]byte("12345678901234567890123456789012")),
+		AlgorithmSha512: base32.StdEncoding.EncodeToString([]byte("1234567890123456789012345678901234567890123456789012345678901234")),
+	}
+	tests := []struct {
+		unixTime int64
+		codes    map[Algorithm]string
+	}{
+		{59, map[Algorithm]string{AlgorithmSha1: "94287082", AlgorithmSha256: "46119246", AlgorithmSha512: "90693936"}},
+		{1111111109, map[Algorithm]string{AlgorithmSha1: "07081804", AlgorithmSha256: "68084774", AlgorithmSha512: "25091201"}},
+		{1111111111, map[Algorithm]string{AlgorithmSha1: "14050471", AlgorithmSha256: "67062674", AlgorithmSha512: "99943326"}},
+		{1234567890, map[Algorithm]string{AlgorithmSha1: "89005924", AlgorithmSha256: "91819424", AlgorithmSha512: "93441116"}},
+		{2000000000, map[Algorithm]string{AlgorithmSha1: "69279037", AlgorithmSha256: "90698825", AlgorithmSha512: "38618901"}},
+		{20000000000, map[Algorithm]string{AlgorithmSha1: "65353130", AlgorithmSha256: "77737706", AlgorithmSha512: "47863826"}},
+	}
+
+	for _, test := range tests {
+		for algorithm, expected := range test.codes {
+			code, err := GenerateCode(secrets[algorithm], time.Unix(test.unixTime, 0), WithAlgorithm(algorithm), WithDigits(8))
+			if err != nil {
+				t.Fatalf("Failed to generate the %s code: %v", algorithm, err)
+			}
+			if code != expected {
+				t.Errorf("Expected %s for %s at %d, got %s", expected, algorithm, test.unixTime, code)
+			}
+		}
+	}
+
+	_, err := GenerateCode("not base32!", time.Now())
+	if err == nil {
+		t.Errorf("Expected an error for an invalid secret")
+	}
+	_, err = GenerateCode(secrets[AlgorithmSha1], time.Now(), WithAlgorithm("MD5"))
+	if err == nil {
+		t.Errorf("Expected an error for an unsupported algorithm")
+	}
+}
+
+func TestValidateCode(t *testing.T) {
+	// the authenticators accept the secrets in lower case, with spaces and without padding
+	secret := "gezd gnbv gy3t qojq gezd gnbv gy3t qojq"
+	now := time.Unix(1111111111, 0)
+	previous, _ := GenerateCode(secret, now.Add(-30*time.Second))
+	current, _ := GenerateCode(secret, now)
+	old, _ := GenerateCode(secret, now.Add(-90*time.Second))
+
+	tests := []struct {
+		name     string
+		passcode string
+		opts     []Option
+		expected bool
+	}{
+		{"current", current, nil, true},
+		{"previous", previous, nil, true},
+		{"previous without window", previous, []Option{WithWindow(0)}, false},
+		{"old", old, nil, false},
+		{"old with a larger window", old, []Option{WithWindow(3)}, true},
+		{"old with the skew", old, []Option{WithSkew(-90 * time.Second), WithWindow(0)}, true},
+		{"wrong length", current[:5], nil, false},
+		{"spaces", " " + current + " ", nil, true},
+	}
+
+	for _, test := range tests {
+		valid, err := ValidateCode(test.passcode, secret, now, test.opts...)
+		if err != nil {
+			t.Fatalf("%s: failed to validate: %v", test.name, err)
+		}
+		if valid != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, valid)
+		}
+	}
+}
+
+func TestParseKeyUri(t *testing.T) {
+	key, err := ParseKeyUri("otpauth://totp/Casdoor:alice?algorithm=SHA1&digits=6&issuer=Casdoor&period=30&secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+	if err != nil {
+		t.Fatalf("Failed to parse the URI: %v", err)
+	}
+	if key.Issuer != "Casdoor" || key.AccountName != "alice" || key.Algorithm != AlgorithmSha1 || key.Digits != 6 || key.Period != 30 {
+		t.Errorf("Unexpected key: %+v", key)
+	}
+
+	code, err := key.GenerateCode(time.Unix(59, 0))
+	if err != nil || code != "287082" {
+		t.Errorf("Expected 287082, got %s, %v", code, err)
+	}
+	valid, err := key.ValidateCode("287082", time.Unix(59, 0))
+	if err != nil || !valid {
+		t.Errorf("Expected the code to be valid, got %v, %v", valid, err)
+	}
+
+	// The URI is rebuilt with the same key
+	rebuilt, err := ParseKeyUri(key.Url())
+	if err != nil || *rebuilt != *key {
+		t.Errorf("Unexpected rebuilt key: %+v, %v", rebuilt, err)
+	}
+
+	// The defaults and the label without issuer
+	key, err = ParseKeyUri("otpauth://totp/bob%40example.com?secret=gezdgnbvgy3tqojq&algorithm=sha256&digits=8&period=60")
+	if err != nil {
+		t.Fatalf("Failed to parse the URI: %v", err)
+	}
+	if key.Issuer != "" || key.AccountName != "bob@example.com" || key.Algorithm != AlgorithmSha256 || key.Digits != 8 || key.Period != 60 {
+		t.Errorf("Unexpected key: %+v", key)
+	}
+
+	for _, uri := range []string{
+		"https://totp/alice?secret=GEZDGNBV",
+		"otpauth://hotp/alice?secret=GEZDGNBV&counter=1",
+		"otpauth://totp/alice",
+		"otpauth://totp/alice?secret=1234",
+		"otpauth://totp/alice?secret=GEZDGNBV&algorithm=MD5",
+		"otpauth://totp/alice?secret=GEZDGNBV&digits=4",
+		"otpauth://totp/alice?secret=GEZDGNBV&period=0",
+	} {
+		_, err = ParseKeyUri(uri)
+		if err == nil {
+			t.Errorf("Expected an error for %s", uri)
+		}
+	}
+}
+
+func TestRenderQr(t *testing.T) {
+	uri := "otpauth://totp/Casdoor:alice?issuer=Casdoor&secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	data, err := RenderQrPng(uri, 256)
+	if err != nil {
+		t.Fatalf("Failed to render the PNG: %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to decode the PNG: %v", err)
+	}
+	bounds := img.Bounds()
+	if bounds.Dx() != bounds.Dy() || bounds.Dx() > 256 || bounds.Dx() < 128 {
+		t.Errorf("Unexpected PNG size: %v", bounds)
+	}
+
+	// The quiet zone is white and the finder pattern at the top left corner starts with black
+	scale := bounds.Dx() / (getModuleCount(t, uri) + 2*quietZone)
+	isBlack := func(x, y int) bool {
+		r, _, _, _ := img.At(x, y).RGBA()
+		return r == 0
+	}
+	if isBlack(0, 0) || !isBlack(quietZone*scale, quietZone*scale) {
+		t.Errorf("Unexpected PNG modules")
+	}
+
+	svg, err := RenderQrSvg(uri, 200)
+	if err != nil {
+		t.Fatalf("Failed to render the SVG: %v", err)
+	}
+	if !strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="200" height="200"`) || !strings.Contains(svg, `d="M4 4h7v1h-7z`) {
+		t.Errorf("Unexpected SVG: %s", svg)
+	}
+
+	_, err = RenderQrPng("", 256)
+	if err == nil {
+		t.Errorf("Expected an error for an empty content")
+	}
+}
+
+// getModuleCount returns the number of modules of the QR code of the content.
+func getModuleCount(t *testing.T, content string) int {
+	qrCode, err := encodeQr(content)
+	if err != nil {
+		t.Fatalf("Failed to encode the QR code: %v", err)
+	}
+
+	return qrCode.Size
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes := make([]string, 10)
+	for i := range codes {
+		codes[i] = strings.Repeat(string(rune('a'+i)), 8)
+	}
+
+	formatted := FormatRecoveryCodes(codes)
+	if !strings.HasPrefix(formatted, " 1. aaaaaaaa\n 2. bbbbbbbb\n") || !strings.HasSuffix(formatted, "10. jjjjjjjj\n") {
+		t.Errorf("Unexpected formatted codes: %q", formatted)
+	}
+
+	w := httptest.NewRecorder()
+	err := WriteRecoveryCodesFile(w, "My Casdoor/../", "alice", codes)
+	if err != nil {
+		t.Fatalf("Failed to write the file: %v", err)
+	}
+	if w.Header().Get("Content-Disposition") != `attachment; filename="My-Casdoor-recovery-codes.txt"` {
+		t.Errorf("Unexpected Content-Disposition: %s", w.Header().Get("Content-Disposition"))
+	}
+	if !strings.HasPrefix(w.Body.String(), "Recovery codes for alice at My Casdoor/../\n") || !strings.Contains(w.Body.String(), formatted) {
+		t.Errorf("Unexpected file: %s", w.Body.String())
+	}
+}
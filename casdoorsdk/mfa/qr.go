@@ -0,0 +1,107 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mfa
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+
+	"rsc.io/qr"
+)
+
+// quietZone is the white margin around the QR codes, in modules, required by the readers.
+const quietZone = 4
+
+func encodeQr(content string) (*qr.Code, error) {
+	if content == "" {
+		return nil, errors.New("the QR code content should not be empty")
+	}
+
+	return qr.Encode(content, qr.M)
+}
+
+// RenderQrPng renders the content, typically an otpauth URI, as a PNG QR code of about size
+// pixels wide. Each module takes a whole number of pixels, so the image is slightly smaller
+// than size, or larger when size is too small for the content.
+func RenderQrPng(content string, size int) ([]byte, error) {
+	code, err := encodeQr(content)
+	if err != nil {
+		return nil, err
+	}
+
+	modules := code.Size + 2*quietZone
+	scale := size / modules
+	if scale < 1 {
+		scale = 1
+	}
+
+	img := image.NewPaletted(image.Rect(0, 0, modules*scale, modules*scale), color.Palette{color.White, color.Black})
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if !code.Black(x, y) {
+				continue
+			}
+
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex((x+quietZone)*scale+dx, (y+quietZone)*scale+dy, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	err = png.Encode(&buf, img)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// RenderQrSvg renders the content, typically an otpauth URI, as an SVG QR code of size
+// pixels wide, which can be inlined in an HTML page.
+func RenderQrSvg(content string, size int) (string, error) {
+	code, err := encodeQr(content)
+	if err != nil {
+		return "", err
+	}
+
+	modules := code.Size + 2*quietZone
+	var path strings.Builder
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if !code.Black(x, y) {
+				continue
+			}
+
+			// the black modules of a row are drawn as runs
+			run := 1
+			for x+run < code.Size && code.Black(x+run, y) {
+				run++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", x+quietZone, y+quietZone, run, run)
+			x += run - 1
+		}
+	}
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+		`<rect width="100%%" height="100%%" fill="#fff"/><path fill="#000" d="%s"/></svg>`, size, size, modules, modules, path.String()), nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mfa
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// FormatRecoveryCodes returns the recovery codes, like the RecoveryCodes returned by
+// casdoorsdk.Client.Initiate(), numbered one per line for display.
+func FormatRecoveryCodes(codes []string) string {
+	width := len(fmt.Sprint(len(codes)))
+
+	var b strings.Builder
+	for i, code := range codes {
+		fmt.Fprintf(&b, "%*d. %s\n", width, i+1, code)
+	}
+
+	return b.String()
+}
+
+// RecoveryCodesFile returns a text file of the recovery codes of the user's account at the
+// issuer, to be downloaded and kept by the user.
+func RecoveryCodesFile(issuer string, accountName string, codes []string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Recovery codes for %s", accountName)
+	if issuer != "" {
+		fmt.Fprintf(&b, " at %s", issuer)
+	}
+	b.WriteString("\n\nKeep these codes in a safe place. Each code signs you in once when you\n")
+	b.WriteString("can't use your authenticator app.\n\n")
+	b.WriteString(FormatRecoveryCodes(codes))
+
+	return []byte(b.String())
+}
+
+// WriteRecoveryCodesFile writes the RecoveryCodesFile() as a download, named after the issuer.
+func WriteRecoveryCodesFile(w http.ResponseWriter, issuer string, accountName string, codes []string) error {
+	filename := "recovery-codes.txt"
+	if name := getFilename(issuer); name != "" {
+		filename = name + "-" + filename
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.Header().Set("Cache-Control", "no-store")
+	_, err := w.Write(RecoveryCodesFile(issuer, accountName, codes))
+	return err
+}
+
+// getFilename keeps the characters of the name that are safe in a file name.
+func getFilename(name string) string {
+	return strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		case r == ' ':
+			return '-'
+		default:
+			return -1
+		}
+	}, name), "-.")
+}
@@ -0,0 +1,355 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mfa provides the helpers of the enrollment of the users in Casdoor's app-based
+// multi-factor authentication: the TOTP codes of RFC 6238, the QR codes of the otpauth URIs
+// and the recovery codes.
+//
+//	resp, err := client.Initiate(owner, casdoorsdk.APP, name)
+//	png, err := mfa.RenderQrPng(resp.Data.URL, 256)
+//	// show the QR code and mfa.FormatRecoveryCodes(resp.Data.RecoveryCodes) to the user
+//
+//	key, err := mfa.ParseKeyUri(resp.Data.URL)
+//	ok, err := key.ValidateCode(passcode, time.Now())
+//	// check the user's passcode before calling client.Verify()
+//
+// It is a separate module, so that the SDK itself doesn't depend on the QR code library:
+//
+//	go get github.com/casdoor/casdoor-go-sdk/casdoorsdk/mfa
+package mfa
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Algorithm is the HMAC algorithm of a TOTP key.
+type Algorithm string
+
+const (
+	AlgorithmSha1   Algorithm = "SHA1"
+	AlgorithmSha256 Algorithm = "SHA256"
+	AlgorithmSha512 Algorithm = "SHA512"
+)
+
+func (a Algorithm) newHash() (func() hash.Hash, error) {
+	switch a {
+	case AlgorithmSha1:
+		return sha1.New, nil
+	case AlgorithmSha256:
+		return sha256.New, nil
+	case AlgorithmSha512:
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("unsupported TOTP algorithm: %q", a)
+	}
+}
+
+// Option is a function type for configuring GenerateCode() and ValidateCode().
+type Option func(*options)
+
+// options holds the configuration of GenerateCode() and ValidateCode().
+type options struct {
+	algorithm Algorithm
+	digits    int
+	period    int
+	window    int
+	skew      time.Duration
+}
+
+// WithAlgorithm sets the HMAC algorithm, the default is SHA1.
+func WithAlgorithm(algorithm Algorithm) Option {
+	return func(o *options) {
+		o.algorithm = algorithm
+	}
+}
+
+// WithDigits sets the length of the codes, the default is 6.
+func WithDigits(digits int) Option {
+	return func(o *options) {
+		o.digits = digits
+	}
+}
+
+// WithPeriod sets how long each code is valid in seconds, the default is 30.
+func WithPeriod(period int) Option {
+	return func(o *options) {
+		o.period = period
+	}
+}
+
+// WithWindow sets how many periods before and after the current one ValidateCode() accepts
+// the codes of, to tolerate the delay of the users typing them, the default is 1.
+func WithWindow(window int) Option {
+	return func(o *options) {
+		o.window = window
+	}
+}
+
+// WithSkew sets the known offset of the authenticators' clocks, which is added to the time
+// of GenerateCode() and ValidateCode().
+func WithSkew(skew time.Duration) Option {
+	return func(o *options) {
+		o.skew = skew
+	}
+}
+
+func getOptions(opts []Option) (*options, error) {
+	o := &options{
+		algorithm: AlgorithmSha1,
+		digits:    6,
+		period:    30,
+		window:    1,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.digits < 6 || o.digits > 10 {
+		return nil, fmt.Errorf("the TOTP digits should be between 6 and 10, got %d", o.digits)
+	}
+	if o.period <= 0 {
+		return nil, fmt.Errorf("the TOTP period should be positive, got %d", o.period)
+	}
+	if o.window < 0 {
+		return nil, fmt.Errorf("the TOTP window should not be negative, got %d", o.window)
+	}
+
+	return o, nil
+}
+
+// decodeSecret decodes a base32 secret, which the authenticators accept without padding, in
+// lower case and with spaces.
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.Join(strings.Fields(secret), ""))
+	secret = strings.TrimRight(secret, "=")
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+	if len(key) == 0 {
+		return nil, errors.New("the TOTP secret should not be empty")
+	}
+
+	return key, nil
+}
+
+// generateCode returns the HOTP code of the counter, see RFC 4226, section 5.3.
+func generateCode(key []byte, counter uint64, o *options) (string, error) {
+	newHash, err := o.algorithm.newHash()
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(newHash, key)
+	_ = binary.Write(mac, binary.BigEndian, counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0xf
+	value := uint64(binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff)
+	code := value % uint64(math.Pow10(o.digits))
+	return fmt.Sprintf("%0*d", o.digits, code), nil
+}
+
+func getCounter(t time.Time, o *options) int64 {
+	return t.Add(o.skew).Unix() / int64(o.period)
+}
+
+// GenerateCode returns the TOTP code of the base32 secret at the time t.
+func GenerateCode(secret string, t time.Time, opts ...Option) (string, error) {
+	o, err := getOptions(opts)
+	if err != nil {
+		return "", err
+	}
+
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return generateCode(key, uint64(getCounter(t, o)), o)
+}
+
+// ValidateCode checks the passcode of a user against the TOTP codes of the base32 secret
+// around the time t, see WithWindow(). The codes are compared in constant time.
+func ValidateCode(passcode string, secret string, t time.Time, opts ...Option) (bool, error) {
+	o, err := getOptions(opts)
+	if err != nil {
+		return false, err
+	}
+
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return false, err
+	}
+
+	passcode = strings.TrimSpace(passcode)
+	if len(passcode) != o.digits {
+		return false, nil
+	}
+
+	counter := getCounter(t, o)
+	valid := false
+	for i := -o.window; i <= o.window; i++ {
+		if counter+int64(i) < 0 {
+			continue
+		}
+
+		code, err := generateCode(key, uint64(counter+int64(i)), o)
+		if err != nil {
+			return false, err
+		}
+
+		if subtle.ConstantTimeCompare([]byte(code), []byte(passcode)) == 1 {
+			valid = true
+		}
+	}
+
+	return valid, nil
+}
+
+// Key is a TOTP key of an otpauth URI, like the URL returned by casdoorsdk.Client.Initiate().
+type Key struct {
+	Issuer      string
+	AccountName string
+	// Secret is the base32 secret shared with the authenticator.
+	Secret    string
+	Algorithm Algorithm
+	Digits    int
+	// Period is how long each code is valid in seconds.
+	Period int
+}
+
+// ParseKeyUri parses an otpauth URI of a TOTP key, like
+// "otpauth://totp/Casdoor:alice?secret=...&issuer=Casdoor".
+func ParseKeyUri(uri string) (*Key, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "otpauth" {
+		return nil, fmt.Errorf("invalid otpauth URI: unexpected scheme %q", u.Scheme)
+	}
+	if u.Host != "totp" {
+		return nil, fmt.Errorf("invalid otpauth URI: only the TOTP keys are supported, got %q", u.Host)
+	}
+
+	key := &Key{
+		Algorithm: AlgorithmSha1,
+		Digits:    6,
+		Period:    30,
+	}
+
+	// the label is "issuer:account" or "account", with the issuer parameter taking precedence
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, accountName, ok := strings.Cut(label, ":"); ok {
+		key.Issuer = strings.TrimSpace(issuer)
+		key.AccountName = strings.TrimSpace(accountName)
+	} else {
+		key.AccountName = strings.TrimSpace(label)
+	}
+
+	query := u.Query()
+	if issuer := query.Get("issuer"); issuer != "" {
+		key.Issuer = issuer
+	}
+
+	key.Secret = query.Get("secret")
+	if key.Secret == "" {
+		return nil, errors.New("invalid otpauth URI: the secret is missing")
+	}
+	_, err = decodeSecret(key.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if algorithm := query.Get("algorithm"); algorithm != "" {
+		key.Algorithm = Algorithm(strings.ToUpper(algorithm))
+		_, err = key.Algorithm.newHash()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if digits := query.Get("digits"); digits != "" {
+		key.Digits, err = strconv.Atoi(digits)
+		if err != nil {
+			return nil, fmt.Errorf("invalid otpauth URI: invalid digits %q", digits)
+		}
+	}
+
+	if period := query.Get("period"); period != "" {
+		key.Period, err = strconv.Atoi(period)
+		if err != nil {
+			return nil, fmt.Errorf("invalid otpauth URI: invalid period %q", period)
+		}
+	}
+
+	_, err = getOptions(key.Options())
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// Url returns the otpauth URI of the key.
+func (k *Key) Url() string {
+	label := k.AccountName
+	if k.Issuer != "" {
+		label = k.Issuer + ":" + label
+	}
+
+	query := url.Values{}
+	query.Set("secret", k.Secret)
+	if k.Issuer != "" {
+		query.Set("issuer", k.Issuer)
+	}
+	query.Set("algorithm", string(k.Algorithm))
+	query.Set("digits", strconv.Itoa(k.Digits))
+	query.Set("period", strconv.Itoa(k.Period))
+
+	u := url.URL{Scheme: "otpauth", Host: "totp", Path: "/" + label, RawQuery: query.Encode()}
+	return u.String()
+}
+
+// Options returns the options of the key's algorithm, digits and period.
+func (k *Key) Options() []Option {
+	return []Option{WithAlgorithm(k.Algorithm), WithDigits(k.Digits), WithPeriod(k.Period)}
+}
+
+// GenerateCode returns the code of the key at the time t.
+func (k *Key) GenerateCode(t time.Time, opts ...Option) (string, error) {
+	return GenerateCode(k.Secret, t, append(k.Options(), opts...)...)
+}
+
+// ValidateCode checks the passcode of a user against the codes of the key around the time t,
+// see the package-level ValidateCode().
+func (k *Key) ValidateCode(passcode string, t time.Time, opts ...Option) (bool, error) {
+	return ValidateCode(passcode, k.Secret, t, append(k.Options(), opts...)...)
+}
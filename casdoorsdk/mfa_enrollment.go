@@ -0,0 +1,495 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	MfaTypeEmail MfaType = "email"
+	MfaTypeSms   MfaType = "sms"
+	MfaTypeApp   MfaType = "app"
+)
+
+func (t MfaType) isValid() bool {
+	return t == MfaTypeEmail || t == MfaTypeSms || t == MfaTypeApp
+}
+
+// maxMfaAge is how long a user has to complete an MfaEnrollment or an MfaChallenge, which is
+// also how long Casdoor's verification codes are valid.
+const maxMfaAge = 10 * time.Minute
+
+var (
+	ErrMfaWrongCode      = errors.New("wrong MFA passcode")
+	ErrMfaExpired        = errors.New("the MFA passcode or enrollment has expired")
+	ErrMfaAlreadyEnabled = errors.New("the MFA type is already enabled")
+	ErrMfaNotEnabled     = errors.New("the MFA type is not enabled")
+	ErrMfaStepOutOfOrder = errors.New("the MFA enrollment steps are out of order")
+)
+
+// MfaError is returned by the MFA enrollment and challenge functions. Err is one of the ErrMfa*
+// variables above, so the reason can be checked with errors.Is(err, casdoorsdk.ErrMfaWrongCode),
+// and Msg is the message of Casdoor, if any.
+type MfaError struct {
+	Err error
+	Msg string
+}
+
+func (e *MfaError) Error() string {
+	if e.Msg == "" {
+		return e.Err.Error()
+	}
+
+	return fmt.Sprintf("%s: %s", e.Err.Error(), e.Msg)
+}
+
+func (e *MfaError) Unwrap() error {
+	return e.Err
+}
+
+// newMfaError returns the *MfaError of a message of Casdoor's MFA APIs, or the error as is
+// when its reason is unknown. Casdoor has no error codes, so the reason is found in the English
+// messages, which the requests made with withEnglishMessages() get.
+func newMfaError(err error) error {
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "expire"), strings.Contains(msg, "should verify your code in"), strings.Contains(msg, "has not been sent yet"):
+		return &MfaError{Err: ErrMfaExpired, Msg: err.Error()}
+	case strings.Contains(msg, "wrong"), strings.Contains(msg, "passcode error"), strings.Contains(msg, "incorrect"):
+		return &MfaError{Err: ErrMfaWrongCode, Msg: err.Error()}
+	case strings.Contains(msg, "already") && strings.Contains(msg, "enabled"):
+		return &MfaError{Err: ErrMfaAlreadyEnabled, Msg: err.Error()}
+	default:
+		return err
+	}
+}
+
+// withEnglishMessages returns a copy of the client whose requests carry "Accept-Language: en",
+// as Casdoor translates its messages into the request's language, and newMfaError() only
+// understands the English ones.
+func (c *Client) withEnglishMessages() *Client {
+	englishClient := *c
+	englishClient.CustomHeaders = make(map[string]string, len(c.CustomHeaders)+1)
+	for key, value := range c.CustomHeaders {
+		englishClient.CustomHeaders[key] = value
+	}
+	englishClient.CustomHeaders["Accept-Language"] = "en"
+
+	return &englishClient
+}
+
+// MfaEnrollmentState is the last completed step of an MfaEnrollment.
+type MfaEnrollmentState string
+
+const (
+	MfaEnrollmentInitiated MfaEnrollmentState = "initiated"
+	MfaEnrollmentVerified  MfaEnrollmentState = "verified"
+	MfaEnrollmentEnabled   MfaEnrollmentState = "enabled"
+)
+
+// MfaEnrollment is the enrollment of a user in an MFA type, started by InitiateMfaEnrollment(),
+// verified with the user's first passcode by VerifyMfaEnrollment() and completed by
+// EnableMfaEnrollment(). The passcode of the "email" and "sms" types is a verification code
+// sent by SendMfaEnrollmentCode(). It can be kept in the user's session between the steps.
+type MfaEnrollment struct {
+	Owner   string  `json:"owner"`
+	Name    string  `json:"name"`
+	MfaType MfaType `json:"mfaType"`
+	// Secret is the TOTP secret of the "app" type, or the email or phone number receiving the
+	// verification codes of the "email" and "sms" types.
+	Secret      string `json:"secret"`
+	CountryCode string `json:"countryCode,omitempty"`
+	// Url is the otpauth URI of the "app" type, to be shown as a QR code, see the mfa package.
+	Url string `json:"url,omitempty"`
+	// RecoveryCodes sign the user in when the second factor is lost, they should be shown to
+	// the user before the enrollment is enabled. Casdoor issues a single recovery code per
+	// enrollment, which it stores when the enrollment is enabled.
+	RecoveryCodes []string           `json:"recoveryCodes"`
+	State         MfaEnrollmentState `json:"state"`
+	CreatedTime   time.Time          `json:"createdTime"`
+}
+
+// isMfaEnabled returns whether the user has enabled the MFA type.
+func isMfaEnabled(user *User, mfaType MfaType) bool {
+	for _, props := range user.MultiFactorAuths {
+		if props != nil && props.Enabled && MfaType(props.MfaType) == mfaType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// InitiateMfaEnrollment starts the enrollment of the user in the MFA type. For the "app" type,
+// the user adds the returned Url to their authenticator app. For the "email" and "sms" types,
+// SendMfaEnrollmentCode() sends the verification code to the returned Secret, the user's email
+// or phone number.
+func (c *Client) InitiateMfaEnrollment(owner string, name string, mfaType MfaType) (*MfaEnrollment, error) {
+	if !mfaType.isValid() {
+		return nil, fmt.Errorf("InitiateMfaEnrollment() error: unsupported MFA type %q", mfaType)
+	}
+
+	user, err := c.GetUser(owner + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("InitiateMfaEnrollment() error: the user %s/%s doesn't exist", owner, name)
+	}
+	if isMfaEnabled(user, mfaType) {
+		return nil, &MfaError{Err: ErrMfaAlreadyEnabled, Msg: string(mfaType)}
+	}
+
+	resp, err := c.withEnglishMessages().Initiate(owner, string(mfaType), name)
+	if err != nil {
+		return nil, newMfaError(err)
+	}
+
+	enrollment := &MfaEnrollment{
+		Owner:         owner,
+		Name:          name,
+		MfaType:       mfaType,
+		Secret:        resp.Data.Secret,
+		Url:           resp.Data.URL,
+		RecoveryCodes: resp.Data.RecoveryCodes,
+		State:         MfaEnrollmentInitiated,
+		CreatedTime:   time.Now(),
+	}
+
+	if enrollment.Secret == "" {
+		switch mfaType {
+		case MfaTypeEmail:
+			enrollment.Secret = user.Email
+		case MfaTypeSms:
+			enrollment.Secret = user.Phone
+			enrollment.CountryCode = user.CountryCode
+		}
+	}
+	if enrollment.Secret == "" {
+		return nil, fmt.Errorf("InitiateMfaEnrollment() error: the user %s/%s has no destination for the %s MFA", owner, name, mfaType)
+	}
+
+	return enrollment, nil
+}
+
+// checkStep checks that the enrollment is at the expected state and not expired.
+func (e *MfaEnrollment) checkStep(expected MfaEnrollmentState) error {
+	if e.State == MfaEnrollmentEnabled {
+		return &MfaError{Err: ErrMfaAlreadyEnabled, Msg: string(e.MfaType)}
+	}
+	if e.State != expected {
+		return &MfaError{Err: ErrMfaStepOutOfOrder, Msg: fmt.Sprintf("expected the %q state, got %q", expected, e.State)}
+	}
+	if time.Since(e.CreatedTime) > maxMfaAge {
+		return &MfaError{Err: ErrMfaExpired, Msg: fmt.Sprintf("the enrollment was initiated at %s", e.CreatedTime.Format(time.RFC3339))}
+	}
+
+	return nil
+}
+
+// SendMfaEnrollmentCode sends the verification code of an "email" or "sms" enrollment to its
+// Secret, with the email or SMS provider of the application in Casdoor. It can be called
+// again to send a new code, within the limits of Casdoor.
+func (c *Client) SendMfaEnrollmentCode(enrollment *MfaEnrollment) error {
+	err := enrollment.checkStep(MfaEnrollmentInitiated)
+	if err != nil {
+		return err
+	}
+
+	return c.sendMfaCode("SendMfaEnrollmentCode", "mfaSetup", enrollment.MfaType, enrollment.Secret, enrollment.CountryCode, "")
+}
+
+// sendMfaCode sends a verification code with Casdoor's "send-verification-code" API, whose
+// method is "mfaSetup" for the enrollments and "mfaAuth" for the sign-ins.
+func (c *Client) sendMfaCode(funcName string, method string, mfaType MfaType, dest string, countryCode string, session string) error {
+	var destType string
+	switch mfaType {
+	case MfaTypeEmail:
+		destType = "email"
+	case MfaTypeSms:
+		destType = "phone"
+	default:
+		return fmt.Errorf("%s() error: the %s MFA has no code to send", funcName, mfaType)
+	}
+
+	contentType, body, err := createForm(map[string]string{
+		"captchaType":   "none",
+		"method":        method,
+		"type":          destType,
+		"dest":          dest,
+		"countryCode":   countryCode,
+		"applicationId": getAdminId(c.ApplicationName),
+	})
+	if err != nil {
+		return err
+	}
+
+	respBytes, _, err := c.withEnglishMessages().doSessionRequest("POST", "send-verification-code", nil, contentType, body, session)
+	if err != nil {
+		return err
+	}
+
+	_, err = parseSessionResponse(respBytes)
+	if err != nil {
+		return newMfaError(err)
+	}
+
+	return nil
+}
+
+// getSetupForm returns the form of Casdoor's "mfa/setup" APIs for the enrollment, which take
+// the email or phone number of the "email" and "sms" types as "dest".
+func (e *MfaEnrollment) getSetupForm() map[string]string {
+	form := map[string]string{
+		"owner":   e.Owner,
+		"name":    e.Name,
+		"mfaType": string(e.MfaType),
+		"secret":  e.Secret,
+	}
+	if e.MfaType != MfaTypeApp {
+		form["dest"] = e.Secret
+		form["countryCode"] = e.CountryCode
+	}
+
+	return form
+}
+
+// VerifyMfaEnrollment checks the first passcode of the user, from their authenticator app or
+// the verification code sent to them. A wrong passcode can be retried.
+func (c *Client) VerifyMfaEnrollment(enrollment *MfaEnrollment, passcode string) error {
+	err := enrollment.checkStep(MfaEnrollmentInitiated)
+	if err != nil {
+		return err
+	}
+
+	form := enrollment.getSetupForm()
+	form["passcode"] = passcode
+	postBytes, err := json.Marshal(form)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.withEnglishMessages().DoPost("mfa/setup/verify", nil, postBytes, true, false)
+	if err != nil {
+		return newMfaError(err)
+	}
+
+	enrollment.State = MfaEnrollmentVerified
+	return nil
+}
+
+// EnableMfaEnrollment enables the verified MFA for the user, with the enrollment's recovery
+// code. Casdoor's enable API takes a single recovery code, the one it issued, so the enrollment
+// should have exactly one.
+func (c *Client) EnableMfaEnrollment(enrollment *MfaEnrollment) error {
+	err := enrollment.checkStep(MfaEnrollmentVerified)
+	if err != nil {
+		return err
+	}
+
+	if len(enrollment.RecoveryCodes) != 1 {
+		return fmt.Errorf("EnableMfaEnrollment() error: expected 1 recovery code, got %d", len(enrollment.RecoveryCodes))
+	}
+
+	form := enrollment.getSetupForm()
+	form["recoveryCodes"] = enrollment.RecoveryCodes[0]
+	postBytes, err := json.Marshal(form)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.withEnglishMessages().DoPost("mfa/setup/enable", nil, postBytes, true, false)
+	if err != nil {
+		return newMfaError(err)
+	}
+
+	enrollment.State = MfaEnrollmentEnabled
+	return nil
+}
+
+// GetMfaChallenges returns the MFA types that the user has enabled, from User.MultiFactorAuths,
+// the preferred one first. The user has to pass one of them at sign-in when it isn't empty,
+// see LoginWithPassword().
+func GetMfaChallenges(user *User) []*MfaProps {
+	var res []*MfaProps
+	for _, props := range user.MultiFactorAuths {
+		if props == nil || !props.Enabled {
+			continue
+		}
+
+		if props.IsPreferred || MfaType(props.MfaType) == MfaType(user.PreferredMfaType) {
+			res = append([]*MfaProps{props}, res...)
+		} else {
+			res = append(res, props)
+		}
+	}
+
+	return res
+}
+
+// IsMfaRequired returns whether the user has to pass a second factor at sign-in.
+func IsMfaRequired(user *User) bool {
+	return len(GetMfaChallenges(user)) > 0
+}
+
+// MfaChallenge is a sign-in at Casdoor waiting for the user's second factor, returned by
+// LoginWithPassword(). Casdoor keeps the sign-in in a session, to which the codes sent by
+// SendMfaChallengeCode() and the passcode checked by VerifyMfaChallenge() belong. It can be
+// kept in the user's session between the steps, where it must stay secret like the tokens.
+type MfaChallenge struct {
+	// MfaTypes are the MFA types the user can pass, whose secrets are masked by Casdoor, like
+	// "a***@example.com", to be shown to the user.
+	MfaTypes    []*MfaProps `json:"mfaTypes"`
+	RedirectUri string      `json:"redirectUri"`
+	State       string      `json:"state"`
+	// Session is the cookie of the Casdoor session.
+	Session     string    `json:"session"`
+	CreatedTime time.Time `json:"createdTime"`
+}
+
+// getMfaProps returns the MFA of the type that the user can pass, or an error.
+func (challenge *MfaChallenge) getMfaProps(mfaType MfaType) (*MfaProps, error) {
+	if time.Since(challenge.CreatedTime) > maxMfaAge {
+		return nil, &MfaError{Err: ErrMfaExpired, Msg: fmt.Sprintf("the sign-in was started at %s", challenge.CreatedTime.Format(time.RFC3339))}
+	}
+
+	for _, props := range challenge.MfaTypes {
+		if props != nil && MfaType(props.MfaType) == mfaType {
+			return props, nil
+		}
+	}
+
+	return nil, &MfaError{Err: ErrMfaNotEnabled, Msg: string(mfaType)}
+}
+
+// LoginWithPassword signs the user in with their password at Casdoor's sign-in API, like the
+// sign-in page, and returns an authorization code of the application, to be exchanged with
+// GetOAuthToken() like the code of the sign-in page. When the user has MFA enabled, it returns
+// the MfaChallenge of the second factor instead, and VerifyMfaChallenge() returns the code.
+// Unlike GetOAuthTokenByPassword(), it supports the users with MFA.
+func (c *Client) LoginWithPassword(username string, password string, redirectUri string, state string) (string, *MfaChallenge, error) {
+	response, session, err := c.doMfaLogin(map[string]string{
+		"username":     username,
+		"password":     password,
+		"signinMethod": "Password",
+	}, redirectUri, state, "")
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch response.Data {
+	case "NextMfa":
+		if session == "" {
+			return "", nil, errors.New("LoginWithPassword() error: Casdoor returned no session for the MFA")
+		}
+
+		challenge := &MfaChallenge{RedirectUri: redirectUri, State: state, Session: session, CreatedTime: time.Now()}
+		dataBytes, err := json.Marshal(response.Data2)
+		if err != nil {
+			return "", nil, err
+		}
+		err = json.Unmarshal(dataBytes, &challenge.MfaTypes)
+		if err != nil {
+			return "", nil, err
+		}
+
+		return "", challenge, nil
+	case "RequiredMfa":
+		return "", nil, fmt.Errorf("LoginWithPassword() error: the organization requires the user %s to set up MFA first", username)
+	}
+
+	code, err := getLoginCode(response)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return code, nil, nil
+}
+
+// doMfaLogin sends a request of the sign-in at Casdoor's "login" API, for an authorization code.
+func (c *Client) doMfaLogin(form map[string]string, redirectUri string, state string, session string) (*Response, string, error) {
+	queryMap := map[string]string{
+		"responseType": "code",
+		"clientId":     c.ClientId,
+		"redirectUri":  redirectUri,
+		"state":        state,
+	}
+
+	form["application"] = c.ApplicationName
+	form["organization"] = c.OrganizationName
+	form["type"] = "code"
+	postBytes, err := json.Marshal(form)
+	if err != nil {
+		return nil, "", err
+	}
+
+	respBytes, session, err := c.withEnglishMessages().doSessionRequest("POST", "login", queryMap, "application/json", bytes.NewReader(postBytes), session)
+	if err != nil {
+		return nil, "", err
+	}
+
+	response, err := parseSessionResponse(respBytes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return response, session, nil
+}
+
+func getLoginCode(response *Response) (string, error) {
+	code, ok := response.Data.(string)
+	if !ok || code == "" {
+		return "", errors.New("Casdoor returned no authorization code for the sign-in")
+	}
+
+	return code, nil
+}
+
+// SendMfaChallengeCode sends the verification code of the "email" or "sms" MFA of the sign-in
+// to the user, whose email or phone number Casdoor finds with the session.
+func (c *Client) SendMfaChallengeCode(challenge *MfaChallenge, mfaType MfaType) error {
+	props, err := challenge.getMfaProps(mfaType)
+	if err != nil {
+		return err
+	}
+
+	return c.sendMfaCode("SendMfaChallengeCode", "mfaAuth", mfaType, props.Secret, props.CountryCode, challenge.Session)
+}
+
+// VerifyMfaChallenge checks the passcode of the user signing in with the MFA type, one of the
+// challenge's MfaTypes, and returns the authorization code of the sign-in, see
+// LoginWithPassword(). A wrong passcode can be retried.
+func (c *Client) VerifyMfaChallenge(challenge *MfaChallenge, mfaType MfaType, passcode string) (string, error) {
+	_, err := challenge.getMfaProps(mfaType)
+	if err != nil {
+		return "", err
+	}
+
+	response, _, err := c.doMfaLogin(map[string]string{
+		"mfaType":  string(mfaType),
+		"passcode": passcode,
+	}, challenge.RedirectUri, challenge.State, challenge.Session)
+	if err != nil {
+		return "", newMfaError(err)
+	}
+
+	return getLoginCode(response)
+}
@@ -0,0 +1,43 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+func InitiateMfaEnrollment(owner string, name string, mfaType MfaType) (*MfaEnrollment, error) {
+	return globalClient.InitiateMfaEnrollment(owner, name, mfaType)
+}
+
+func SendMfaEnrollmentCode(enrollment *MfaEnrollment) error {
+	return globalClient.SendMfaEnrollmentCode(enrollment)
+}
+
+func VerifyMfaEnrollment(enrollment *MfaEnrollment, passcode string) error {
+	return globalClient.VerifyMfaEnrollment(enrollment, passcode)
+}
+
+func EnableMfaEnrollment(enrollment *MfaEnrollment) error {
+	return globalClient.EnableMfaEnrollment(enrollment)
+}
+
+func LoginWithPassword(username string, password string, redirectUri string, state string) (string, *MfaChallenge, error) {
+	return globalClient.LoginWithPassword(username, password, redirectUri, state)
+}
+
+func SendMfaChallengeCode(challenge *MfaChallenge, mfaType MfaType) error {
+	return globalClient.SendMfaChallengeCode(challenge, mfaType)
+}
+
+func VerifyMfaChallenge(challenge *MfaChallenge, mfaType MfaType, passcode string) (string, error) {
+	return globalClient.VerifyMfaChallenge(challenge, mfaType, passcode)
+}
@@ -0,0 +1,426 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testMfaServer answers the MFA APIs like Casdoor, where "123456" is the right passcode of the
+// authenticator apps and of the verification codes once sent, and "999999" an expired one.
+type testMfaServer struct {
+	*httptest.Server
+	user                *User
+	enabledRecoveryCode string
+	// sentCodes are the methods of the verification codes sent, by destination
+	sentCodes map[string]string
+}
+
+func newTestMfaServer(t *testing.T, user *User) *testMfaServer {
+	server := &testMfaServer{user: user, sentCodes: map[string]string{}}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/api/get-user" && r.Header.Get("Accept-Language") != "en" {
+			t.Errorf("Expected the English messages for %s, got Accept-Language %q", r.URL.Path, r.Header.Get("Accept-Language"))
+		}
+
+		response := Response{Status: "ok"}
+		switch r.URL.Path {
+		case "/api/get-user":
+			if r.URL.Query().Get("id") != "built-in/alice" {
+				t.Errorf("Unexpected user ID: %s", r.URL.Query().Get("id"))
+			}
+			response.Data = user
+		case "/api/mfa/setup/initiate":
+			if r.FormValue("mfaType") == "app" {
+				response.Data = map[string]interface{}{
+					"mfaType":       "app",
+					"secret":        "GEZDGNBVGY3TQOJQ",
+					"url":           "otpauth://totp/Casdoor:alice?secret=GEZDGNBVGY3TQOJQ",
+					"recoveryCodes": []string{"recovery-code"},
+				}
+			} else {
+				response.Data = map[string]interface{}{"mfaType": r.FormValue("mfaType"), "recoveryCodes": []string{"recovery-code"}}
+			}
+		case "/api/mfa/setup/verify":
+			response = server.checkPasscode(MfaType(r.FormValue("mfaType")), r.FormValue("dest"), "mfaSetup", r.FormValue("passcode"))
+		case "/api/mfa/setup/enable":
+			if r.FormValue("mfaType") != "app" && r.FormValue("dest") == "" {
+				t.Errorf("Expected the destination of the %s MFA", r.FormValue("mfaType"))
+			}
+			server.enabledRecoveryCode = r.FormValue("recoveryCodes")
+		case "/api/send-verification-code":
+			response = server.sendCode(t, r)
+		case "/api/login":
+			response = server.login(t, w, r)
+		default:
+			t.Errorf("Unexpected request: %s", r.URL.Path)
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+
+	return server
+}
+
+// getMfaSecret returns the email, phone number or TOTP secret of the user for the MFA type.
+func (server *testMfaServer) getMfaSecret(mfaType MfaType) string {
+	switch mfaType {
+	case MfaTypeEmail:
+		return server.user.Email
+	case MfaTypeSms:
+		return server.user.Phone
+	default:
+		return server.user.TotpSecret
+	}
+}
+
+func (server *testMfaServer) checkPasscode(mfaType MfaType, dest string, method string, passcode string) Response {
+	if mfaType != MfaTypeApp && server.sentCodes[dest] != method {
+		return Response{Status: "error", Msg: "The verification code has not been sent yet!"}
+	}
+
+	switch passcode {
+	case "123456":
+		return Response{Status: "ok"}
+	case "999999":
+		return Response{Status: "error", Msg: "You should verify your code in 10 min!"}
+	default:
+		return Response{Status: "error", Msg: "totp passcode error"}
+	}
+}
+
+func (server *testMfaServer) sendCode(t *testing.T, r *http.Request) Response {
+	method, dest := r.FormValue("method"), r.FormValue("dest")
+	if r.FormValue("captchaType") != "none" || r.FormValue("applicationId") != "admin/app-built-in" {
+		t.Errorf("Unexpected verification code form: %v", r.Form)
+	}
+
+	expectedType := map[string]string{server.user.Email: "email", server.user.Phone: "phone"}
+	if method == "mfaAuth" {
+		if cookie, err := r.Cookie("casdoor_session_id"); err != nil || cookie.Value != "mfa-session" {
+			return Response{Status: "error", Msg: "Please sign in first"}
+		}
+
+		// Casdoor finds the masked destination of the session's user
+		for _, props := range server.user.MultiFactorAuths {
+			if props.Enabled && maskSecret(server.getMfaSecret(MfaType(props.MfaType))) == dest {
+				dest = server.getMfaSecret(MfaType(props.MfaType))
+			}
+		}
+	} else if method != "mfaSetup" {
+		t.Errorf("Unexpected method: %s", method)
+	}
+
+	if expectedType[dest] == "" || expectedType[dest] != r.FormValue("type") {
+		return Response{Status: "error", Msg: "Invalid destination"}
+	}
+
+	server.sentCodes[dest] = method
+	return Response{Status: "ok"}
+}
+
+func (server *testMfaServer) login(t *testing.T, w http.ResponseWriter, r *http.Request) Response {
+	query := r.URL.Query()
+	if query.Get("responseType") != "code" || query.Get("clientId") != "client-id" || query.Get("redirectUri") != "https://app.example.com/callback" {
+		t.Errorf("Unexpected sign-in query: %s", r.URL.RawQuery)
+	}
+
+	var form map[string]string
+	err := json.NewDecoder(r.Body).Decode(&form)
+	if err != nil || form["application"] != "app-built-in" || form["organization"] != "built-in" || form["type"] != "code" {
+		t.Errorf("Unexpected sign-in form: %v, %v", form, err)
+	}
+
+	if form["username"] != "" {
+		if form["username"] != "alice" || form["password"] != "password" {
+			return Response{Status: "error", Msg: "password or code is incorrect"}
+		}
+		if !IsMfaRequired(server.user) {
+			return Response{Status: "ok", Data: "code-of-" + query.Get("state")}
+		}
+
+		var mfaTypes []*MfaProps
+		for _, props := range GetMfaChallenges(server.user) {
+			mfaTypes = append(mfaTypes, &MfaProps{Enabled: true, MfaType: props.MfaType, Secret: maskSecret(server.getMfaSecret(MfaType(props.MfaType)))})
+		}
+		http.SetCookie(w, &http.Cookie{Name: "casdoor_session_id", Value: "mfa-session"})
+		return Response{Status: "ok", Data: "NextMfa", Data2: mfaTypes}
+	}
+
+	if cookie, err := r.Cookie("casdoor_session_id"); err != nil || cookie.Value != "mfa-session" {
+		return Response{Status: "error", Msg: "Unknown authentication type"}
+	}
+
+	mfaType := MfaType(form["mfaType"])
+	response := server.checkPasscode(mfaType, server.getMfaSecret(mfaType), "mfaAuth", form["passcode"])
+	if response.Status == "ok" {
+		response.Data = "code-of-" + query.Get("state")
+	}
+	return response
+}
+
+// maskSecret masks the email or phone number like Casdoor.
+func maskSecret(secret string) string {
+	if len(secret) < 4 {
+		return "***"
+	}
+
+	return secret[:1] + "***" + secret[len(secret)-3:]
+}
+
+func TestMfaEnrollment(t *testing.T) {
+	user := &User{Owner: "built-in", Name: "alice", Email: "alice@example.com", Phone: "5550100", CountryCode: "US"}
+	server := newTestMfaServer(t, user)
+	defer server.Close()
+	client := NewClient(server.URL, "client-id", "client-secret", "", "built-in", "app-built-in")
+
+	enrollment, err := client.InitiateMfaEnrollment("built-in", "alice", MfaTypeApp)
+	if err != nil {
+		t.Fatalf("Failed to initiate the enrollment: %v", err)
+	}
+	if enrollment.Secret != "GEZDGNBVGY3TQOJQ" || enrollment.Url == "" || len(enrollment.RecoveryCodes) != 1 || enrollment.State != MfaEnrollmentInitiated {
+		t.Errorf("Unexpected enrollment: %+v", enrollment)
+	}
+
+	// The steps are enforced, and a wrong passcode can be retried
+	err = client.EnableMfaEnrollment(enrollment)
+	if !errors.Is(err, ErrMfaStepOutOfOrder) {
+		t.Errorf("Expected ErrMfaStepOutOfOrder, got %v", err)
+	}
+	err = client.VerifyMfaEnrollment(enrollment, "000000")
+	var mfaErr *MfaError
+	if !errors.Is(err, ErrMfaWrongCode) || !errors.As(err, &mfaErr) || mfaErr.Msg != "totp passcode error" {
+		t.Errorf("Expected ErrMfaWrongCode, got %v", err)
+	}
+	err = client.VerifyMfaEnrollment(enrollment, "999999")
+	if !errors.Is(err, ErrMfaExpired) {
+		t.Errorf("Expected ErrMfaExpired, got %v", err)
+	}
+
+	// The enrollment is kept in a session between the steps
+	data, err := json.Marshal(enrollment)
+	if err != nil {
+		t.Fatalf("Failed to marshal the enrollment: %v", err)
+	}
+	enrollment = &MfaEnrollment{}
+	err = json.Unmarshal(data, enrollment)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal the enrollment: %v", err)
+	}
+
+	err = client.VerifyMfaEnrollment(enrollment, "123456")
+	if err != nil {
+		t.Fatalf("Failed to verify the enrollment: %v", err)
+	}
+	recoveryCodes := enrollment.RecoveryCodes
+	enrollment.RecoveryCodes = append(enrollment.RecoveryCodes, "other-code")
+	err = client.EnableMfaEnrollment(enrollment)
+	if err == nil {
+		t.Errorf("Expected an error for several recovery codes")
+	}
+	enrollment.RecoveryCodes = recoveryCodes
+	err = client.EnableMfaEnrollment(enrollment)
+	if err != nil {
+		t.Fatalf("Failed to enable the enrollment: %v", err)
+	}
+	if enrollment.State != MfaEnrollmentEnabled || server.enabledRecoveryCode != "recovery-code" {
+		t.Errorf("Unexpected enabled enrollment: %+v, %q", enrollment, server.enabledRecoveryCode)
+	}
+	err = client.VerifyMfaEnrollment(enrollment, "123456")
+	if !errors.Is(err, ErrMfaAlreadyEnabled) {
+		t.Errorf("Expected ErrMfaAlreadyEnabled, got %v", err)
+	}
+
+	err = client.SendMfaEnrollmentCode(enrollment)
+	if !errors.Is(err, ErrMfaAlreadyEnabled) {
+		t.Errorf("Expected ErrMfaAlreadyEnabled, got %v", err)
+	}
+
+	// The email and SMS codes are sent to the user's email and phone number, and then verified
+	for _, mfaType := range []MfaType{MfaTypeEmail, MfaTypeSms} {
+		enrollment, err = client.InitiateMfaEnrollment("built-in", "alice", mfaType)
+		if err != nil {
+			t.Fatalf("Failed to initiate the %s enrollment: %v", mfaType, err)
+		}
+		err = client.VerifyMfaEnrollment(enrollment, "123456")
+		if !errors.Is(err, ErrMfaExpired) {
+			t.Errorf("Expected ErrMfaExpired for the %s code not sent, got %v", mfaType, err)
+		}
+
+		err = client.SendMfaEnrollmentCode(enrollment)
+		if err != nil {
+			t.Fatalf("Failed to send the %s code: %v", mfaType, err)
+		}
+		err = client.VerifyMfaEnrollment(enrollment, "123456")
+		if err != nil {
+			t.Fatalf("Failed to verify the %s enrollment: %v", mfaType, err)
+		}
+		err = client.EnableMfaEnrollment(enrollment)
+		if err != nil {
+			t.Fatalf("Failed to enable the %s enrollment: %v", mfaType, err)
+		}
+	}
+	if server.sentCodes["alice@example.com"] != "mfaSetup" || server.sentCodes["5550100"] != "mfaSetup" || enrollment.CountryCode != "US" {
+		t.Errorf("Expected the codes to be sent to the user, got %v", server.sentCodes)
+	}
+
+	enrollment, err = client.InitiateMfaEnrollment("built-in", "alice", MfaTypeApp)
+	if err != nil {
+		t.Fatalf("Failed to initiate the enrollment: %v", err)
+	}
+	err = client.SendMfaEnrollmentCode(enrollment)
+	if err == nil {
+		t.Errorf("Expected an error for sending an app code")
+	}
+
+	// An enrollment expires
+	enrollment.CreatedTime = time.Now().Add(-time.Hour)
+	err = client.VerifyMfaEnrollment(enrollment, "123456")
+	if !errors.Is(err, ErrMfaExpired) {
+		t.Errorf("Expected ErrMfaExpired for an old enrollment, got %v", err)
+	}
+
+	_, err = client.InitiateMfaEnrollment("built-in", "alice", "push")
+	if err == nil {
+		t.Errorf("Expected an error for an unsupported MFA type")
+	}
+
+	user.MultiFactorAuths = []*MfaProps{{Enabled: true, MfaType: "app"}}
+	_, err = client.InitiateMfaEnrollment("built-in", "alice", MfaTypeApp)
+	if !errors.Is(err, ErrMfaAlreadyEnabled) {
+		t.Errorf("Expected ErrMfaAlreadyEnabled, got %v", err)
+	}
+}
+
+func TestMfaChallenge(t *testing.T) {
+	user := &User{
+		Owner:            "built-in",
+		Name:             "alice",
+		Email:            "alice@example.com",
+		Phone:            "12345678",
+		TotpSecret:       "GEZDGNBVGY3TQOJQ",
+		PreferredMfaType: "app",
+		MultiFactorAuths: []*MfaProps{
+			{Enabled: true, MfaType: "email"},
+			{Enabled: false, MfaType: "sms"},
+			{Enabled: true, MfaType: "app"},
+		},
+	}
+	server := newTestMfaServer(t, user)
+	defer server.Close()
+	client := NewClient(server.URL, "client-id", "client-secret", "", "built-in", "app-built-in")
+	redirectUri := "https://app.example.com/callback"
+
+	challenges := GetMfaChallenges(user)
+	if len(challenges) != 2 || challenges[0].MfaType != "app" || challenges[1].MfaType != "email" || !IsMfaRequired(user) {
+		t.Errorf("Unexpected challenges: %+v", challenges)
+	}
+	if IsMfaRequired(&User{}) {
+		t.Errorf("Expected no MFA for a user without MultiFactorAuths")
+	}
+
+	_, _, err := client.LoginWithPassword("alice", "wrong", redirectUri, "state")
+	if err == nil {
+		t.Errorf("Expected an error for a wrong password")
+	}
+
+	// The password is the first factor
+	code, challenge, err := client.LoginWithPassword("alice", "password", redirectUri, "state")
+	if err != nil || code != "" || challenge == nil {
+		t.Fatalf("Expected an MFA challenge, got %q, %+v, %v", code, challenge, err)
+	}
+	if len(challenge.MfaTypes) != 2 || challenge.MfaTypes[1].Secret != "a***com" || challenge.Session != "casdoor_session_id=mfa-session" {
+		t.Errorf("Unexpected challenge: %+v", challenge)
+	}
+
+	// The challenge is kept in a session between the steps
+	data, err := json.Marshal(challenge)
+	if err != nil {
+		t.Fatalf("Failed to marshal the challenge: %v", err)
+	}
+	challenge = &MfaChallenge{}
+	err = json.Unmarshal(data, challenge)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal the challenge: %v", err)
+	}
+
+	// The email code is sent with the session, and then verified
+	_, err = client.VerifyMfaChallenge(challenge, MfaTypeEmail, "123456")
+	if !errors.Is(err, ErrMfaExpired) {
+		t.Errorf("Expected ErrMfaExpired for a code not sent, got %v", err)
+	}
+	err = client.SendMfaChallengeCode(challenge, MfaTypeEmail)
+	if err != nil {
+		t.Fatalf("Failed to send the email code: %v", err)
+	}
+	if server.sentCodes["alice@example.com"] != "mfaAuth" {
+		t.Errorf("Expected the code to be sent to the user's email, got %v", server.sentCodes)
+	}
+	_, err = client.VerifyMfaChallenge(challenge, MfaTypeEmail, "000000")
+	if !errors.Is(err, ErrMfaWrongCode) {
+		t.Errorf("Expected ErrMfaWrongCode, got %v", err)
+	}
+	code, err = client.VerifyMfaChallenge(challenge, MfaTypeEmail, "123456")
+	if err != nil || code != "code-of-state" {
+		t.Errorf("Expected the authorization code, got %q, %v", code, err)
+	}
+
+	code, err = client.VerifyMfaChallenge(challenge, MfaTypeApp, "123456")
+	if err != nil || code != "code-of-state" {
+		t.Errorf("Expected the authorization code, got %q, %v", code, err)
+	}
+	err = client.SendMfaChallengeCode(challenge, MfaTypeApp)
+	if err == nil {
+		t.Errorf("Expected an error for sending an app code")
+	}
+	_, err = client.VerifyMfaChallenge(challenge, MfaTypeSms, "123456")
+	if !errors.Is(err, ErrMfaNotEnabled) {
+		t.Errorf("Expected ErrMfaNotEnabled, got %v", err)
+	}
+
+	// A challenge expires
+	challenge.CreatedTime = time.Now().Add(-time.Hour)
+	err = client.SendMfaChallengeCode(challenge, MfaTypeEmail)
+	if !errors.Is(err, ErrMfaExpired) {
+		t.Errorf("Expected ErrMfaExpired for an old challenge, got %v", err)
+	}
+
+	// The SMS codes are sent to the user's phone number
+	user.MultiFactorAuths[1].Enabled = true
+	_, challenge, err = client.LoginWithPassword("alice", "password", redirectUri, "state")
+	if err != nil {
+		t.Fatalf("Failed to sign in: %v", err)
+	}
+	err = client.SendMfaChallengeCode(challenge, MfaTypeSms)
+	if err != nil {
+		t.Fatalf("Failed to send the SMS code: %v", err)
+	}
+	code, err = client.VerifyMfaChallenge(challenge, MfaTypeSms, "123456")
+	if err != nil || code != "code-of-state" || server.sentCodes["12345678"] != "mfaAuth" {
+		t.Errorf("Expected the authorization code, got %q, %v", code, err)
+	}
+
+	// A user without MFA is signed in with their password
+	user.MultiFactorAuths = nil
+	code, challenge, err = client.LoginWithPassword("alice", "password", redirectUri, "state")
+	if err != nil || code != "code-of-state" || challenge != nil {
+		t.Errorf("Expected the authorization code, got %q, %+v, %v", code, challenge, err)
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package middleware provides a net/http middleware that authenticates the requests with
+// the access tokens issued by Casdoor:
+//
+//	mux.Handle("/api/", middleware.RequireAuth(client,
+//		middleware.WithRequiredScopes("read"),
+//		middleware.WithAnyRole("admin", "editor"))(apiHandler))
+//
+// The handlers get the token's claims with ClaimsFromContext(r.Context()).
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+)
+
+type claimsContextKey struct{}
+
+// NewContext returns a copy of ctx carrying the claims, it is mainly useful in tests.
+func NewContext(ctx context.Context, claims *casdoorsdk.Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims of the request's access token, set by RequireAuth().
+// The second result is false when the request is not authenticated, which is only possible
+// on the routes using WithOptionalAuth().
+func ClaimsFromContext(ctx context.Context) (*casdoorsdk.Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*casdoorsdk.Claims)
+	return claims, ok && claims != nil
+}
+
+// Option is a function type for configuring RequireAuth().
+type Option func(*options)
+
+// options holds the configuration of RequireAuth().
+type options struct {
+	cookieName     string
+	realm          string
+	requiredScopes []string
+	roles          []string
+	optionalAuth   bool
+	validationOpts []casdoorsdk.ValidationOption
+}
+
+// WithCookie also reads the access token from the named cookie when the request has no
+// "Authorization" header, e.g., for the browser requests of a web application.
+func WithCookie(name string) Option {
+	return func(opts *options) {
+		opts.cookieName = name
+	}
+}
+
+// WithRealm sets the "realm" of the "WWW-Authenticate" header.
+func WithRealm(realm string) Option {
+	return func(opts *options) {
+		opts.realm = realm
+	}
+}
+
+// WithRequiredScopes requires the token's "scope" claim to contain all the given scopes,
+// otherwise the request is rejected with an "insufficient_scope" error.
+func WithRequiredScopes(scopes ...string) Option {
+	return func(opts *options) {
+		opts.requiredScopes = append(opts.requiredScopes, scopes...)
+	}
+}
+
+// WithAnyRole requires the user to have at least one of the given roles (Claims.User.Roles),
+// identified by their name ("admin") or by their owner and name ("built-in/admin").
+func WithAnyRole(roles ...string) Option {
+	return func(opts *options) {
+		opts.roles = append(opts.roles, roles...)
+	}
+}
+
+// WithOptionalAuth lets the requests without any token through, without claims in their
+// context. The requests with an invalid token are still rejected.
+func WithOptionalAuth() Option {
+	return func(opts *options) {
+		opts.optionalAuth = true
+	}
+}
+
+// WithValidationOptions adds claim checks to the token validation, see
+// casdoorsdk.ParseJwtTokenWithOptions(). The refresh tokens are always rejected.
+func WithValidationOptions(opts ...casdoorsdk.ValidationOption) Option {
+	return func(o *options) {
+		o.validationOpts = append(o.validationOpts, opts...)
+	}
+}
+
+// RequireAuth returns a middleware that authenticates the requests with the Bearer access
+// token of their "Authorization" header (RFC 6750), validated by
+// client.ParseJwtTokenWithOptions(). The rejected requests get a 401 or 403 response with a
+// "WWW-Authenticate" header describing the error, as defined in RFC 6750, section 3.
+func RequireAuth(client *casdoorsdk.Client, opts ...Option) func(http.Handler) http.Handler {
+	options := &options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	validationOpts := append([]casdoorsdk.ValidationOption{casdoorsdk.WithRejectRefreshTokens()}, options.validationOpts...)
+	if len(options.requiredScopes) > 0 {
+		validationOpts = append(validationOpts, casdoorsdk.WithRequiredScopes(options.requiredScopes...))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, err := getToken(r, options.cookieName)
+			if err != nil {
+				options.writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+				return
+			}
+
+			if token == "" {
+				if options.optionalAuth {
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				options.writeError(w, http.StatusUnauthorized, "", "")
+				return
+			}
+
+			claims, err := client.ParseJwtTokenWithOptions(token, validationOpts...)
+			if err != nil {
+				if errors.Is(err, casdoorsdk.ErrMissingScope) {
+					options.writeError(w, http.StatusForbidden, "insufficient_scope", err.Error())
+					return
+				}
+
+				options.writeError(w, http.StatusUnauthorized, "invalid_token", err.Error())
+				return
+			}
+
+			if len(options.roles) > 0 && !hasAnyRole(claims, options.roles) {
+				options.writeError(w, http.StatusForbidden, "insufficient_scope", "the user has none of the required roles")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), claims)))
+		})
+	}
+}
+
+// getToken returns the Bearer token of the "Authorization" header, or the one of the cookie
+// when there is no such header.
+func getToken(r *http.Request, cookieName string) (string, error) {
+	authorizations := r.Header.Values("Authorization")
+	if len(authorizations) > 1 {
+		return "", errors.New("multiple Authorization headers")
+	}
+
+	if len(authorizations) == 1 {
+		scheme, token, found := strings.Cut(authorizations[0], " ")
+		if !strings.EqualFold(scheme, "Bearer") {
+			return "", nil
+		}
+
+		token = strings.TrimSpace(token)
+		if !found || token == "" {
+			return "", errors.New("the Bearer token is empty")
+		}
+
+		return token, nil
+	}
+
+	if cookieName != "" {
+		cookie, err := r.Cookie(cookieName)
+		if err == nil {
+			return cookie.Value, nil
+		}
+	}
+
+	return "", nil
+}
+
+func hasAnyRole(claims *casdoorsdk.Claims, roles []string) bool {
+	for _, role := range claims.Roles {
+		if role == nil {
+			continue
+		}
+
+		for _, requiredRole := range roles {
+			if requiredRole == role.Name || requiredRole == role.Owner+"/"+role.Name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// writeError writes an error response with the "WWW-Authenticate" header of RFC 6750, section 3.
+func (opts *options) writeError(w http.ResponseWriter, statusCode int, errorCode string, errorDescription string) {
+	params := []string{}
+	if opts.realm != "" {
+		params = append(params, fmt.Sprintf("realm=%q", sanitizeParam(opts.realm)))
+	}
+	if errorCode != "" {
+		params = append(params, fmt.Sprintf("error=%q", errorCode))
+	}
+	if errorDescription != "" {
+		params = append(params, fmt.Sprintf("error_description=%q", sanitizeParam(errorDescription)))
+	}
+	if errorCode == "insufficient_scope" && len(opts.requiredScopes) > 0 {
+		params = append(params, fmt.Sprintf("scope=%q", sanitizeParam(strings.Join(opts.requiredScopes, " "))))
+	}
+
+	challenge := "Bearer"
+	if len(params) > 0 {
+		challenge += " " + strings.Join(params, ", ")
+	}
+
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, http.StatusText(statusCode), statusCode)
+}
+
+// sanitizeParam keeps the characters allowed in the "WWW-Authenticate" parameters by RFC 6750,
+// so that %q doesn't need to escape anything.
+func sanitizeParam(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '\''
+		}
+		return r
+	}, s)
+}
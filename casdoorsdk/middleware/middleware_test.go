@@ -0,0 +1,152 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const testEndpoint = "https://door.casdoor.com"
+
+// newTestClient returns a client whose certificate verifies the tokens signed by the returned sign function.
+func newTestClient(t *testing.T) (*casdoorsdk.Client, func(claims *casdoorsdk.Claims) string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Casdoor Cert"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	certificate := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	client := casdoorsdk.NewClient(testEndpoint, "client-id", "client-secret", certificate, "built-in", "app-built-in")
+	return client, func(claims *casdoorsdk.Claims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key)
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		return token
+	}
+}
+
+func newTestClaims(scope string, roles ...string) *casdoorsdk.Claims {
+	claims := &casdoorsdk.Claims{
+		User:      casdoorsdk.User{Owner: "built-in", Name: "alice"},
+		TokenType: "access-token",
+		Scope:     scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    testEndpoint,
+			Subject:   "alice-id",
+			Audience:  []string{"client-id"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	for _, role := range roles {
+		claims.Roles = append(claims.Roles, &casdoorsdk.Role{Owner: "built-in", Name: role})
+	}
+
+	return claims
+}
+
+func TestRequireAuth(t *testing.T) {
+	client, sign := newTestClient(t)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := ClaimsFromContext(r.Context())
+		if !ok {
+			_, _ = w.Write([]byte("anonymous"))
+			return
+		}
+		_, _ = w.Write([]byte(claims.Name))
+	})
+
+	expiredClaims := newTestClaims("read")
+	expiredClaims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	refreshClaims := newTestClaims("read")
+	refreshClaims.TokenType = "refresh-token"
+
+	tests := []struct {
+		name          string
+		opts          []Option
+		authorization string
+		cookie        string
+		statusCode    int
+		body          string
+		challenge     string
+	}{
+		{name: "valid token", authorization: "Bearer " + sign(newTestClaims("read")), statusCode: http.StatusOK, body: "alice"},
+		{name: "lowercase scheme", authorization: "bearer " + sign(newTestClaims("read")), statusCode: http.StatusOK, body: "alice"},
+		{name: "no token", statusCode: http.StatusUnauthorized, challenge: `Bearer realm="api"`},
+		{name: "other scheme", authorization: "Basic YWxpY2U6MTIz", statusCode: http.StatusUnauthorized, challenge: `Bearer realm="api"`},
+		{name: "empty token", authorization: "Bearer ", statusCode: http.StatusBadRequest, challenge: `error="invalid_request"`},
+		{name: "invalid token", authorization: "Bearer invalid", statusCode: http.StatusUnauthorized, challenge: `error="invalid_token"`},
+		{name: "expired token", authorization: "Bearer " + sign(expiredClaims), statusCode: http.StatusUnauthorized, challenge: `error="invalid_token"`},
+		{name: "refresh token", authorization: "Bearer " + sign(refreshClaims), statusCode: http.StatusUnauthorized, challenge: `error="invalid_token"`},
+		{name: "cookie", opts: []Option{WithCookie("access_token")}, cookie: sign(newTestClaims("read")), statusCode: http.StatusOK, body: "alice"},
+		{name: "missing scope", opts: []Option{WithRequiredScopes("write")}, authorization: "Bearer " + sign(newTestClaims("read")), statusCode: http.StatusForbidden, challenge: `error="insufficient_scope"`},
+		{name: "required scope", opts: []Option{WithRequiredScopes("read")}, authorization: "Bearer " + sign(newTestClaims("openid read")), statusCode: http.StatusOK, body: "alice"},
+		{name: "missing role", opts: []Option{WithAnyRole("admin")}, authorization: "Bearer " + sign(newTestClaims("read", "user")), statusCode: http.StatusForbidden, challenge: `error="insufficient_scope"`},
+		{name: "role", opts: []Option{WithAnyRole("admin")}, authorization: "Bearer " + sign(newTestClaims("read", "admin")), statusCode: http.StatusOK, body: "alice"},
+		{name: "role with owner", opts: []Option{WithAnyRole("built-in/admin")}, authorization: "Bearer " + sign(newTestClaims("read", "admin")), statusCode: http.StatusOK, body: "alice"},
+		{name: "optional auth without token", opts: []Option{WithOptionalAuth()}, statusCode: http.StatusOK, body: "anonymous"},
+		{name: "optional auth with invalid token", opts: []Option{WithOptionalAuth()}, authorization: "Bearer invalid", statusCode: http.StatusUnauthorized, challenge: `error="invalid_token"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/resource", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "access_token", Value: tt.cookie})
+			}
+
+			recorder := httptest.NewRecorder()
+			opts := append([]Option{WithRealm("api")}, tt.opts...)
+			RequireAuth(client, opts...)(handler).ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.statusCode {
+				t.Errorf("Expected status %d, got %d", tt.statusCode, recorder.Code)
+			}
+			if tt.body != "" && recorder.Body.String() != tt.body {
+				t.Errorf("Expected body %q, got %q", tt.body, recorder.Body.String())
+			}
+			if challenge := recorder.Header().Get("WWW-Authenticate"); !strings.Contains(challenge, tt.challenge) {
+				t.Errorf("Expected WWW-Authenticate to contain %q, got %q", tt.challenge, challenge)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidRequest       = errors.New("invalid_request")
+	ErrInvalidClient        = errors.New("invalid_client")
+	ErrInvalidGrant         = errors.New("invalid_grant")
+	ErrUnsupportedTokenType = errors.New("unsupported_token_type")
+)
+
+var oauthErrors = map[string]error{
+	ErrInvalidRequest.Error():       ErrInvalidRequest,
+	ErrInvalidClient.Error():        ErrInvalidClient,
+	ErrInvalidGrant.Error():         ErrInvalidGrant,
+	ErrUnsupportedTokenType.Error(): ErrUnsupportedTokenType,
+}
+
+// OAuthError is the error response of the Casdoor OAuth endpoints, as defined in
+// RFC 6749, section 5.2. The well-known error codes can be checked with errors.Is(),
+// like errors.Is(err, casdoorsdk.ErrUnsupportedTokenType).
+type OAuthError struct {
+	StatusCode       int    `json:"-"`
+	ErrorCode        string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (e *OAuthError) Error() string {
+	if e.ErrorDescription == "" {
+		return fmt.Sprintf("oauth error: %s", e.ErrorCode)
+	}
+
+	return fmt.Sprintf("oauth error: %s: %s", e.ErrorCode, e.ErrorDescription)
+}
+
+func (e *OAuthError) Unwrap() error {
+	return oauthErrors[e.ErrorCode]
+}
+
+// parseOAuthError returns the error carried by the response of an OAuth endpoint, or nil
+// if the request succeeded. Both the RFC 6749 error response and the Casdoor
+// {"status": "error", "msg": "..."} response are recognized.
+func parseOAuthError(statusCode int, respBytes []byte) error {
+	oauthErr := &OAuthError{StatusCode: statusCode}
+	if json.Unmarshal(respBytes, oauthErr) == nil && oauthErr.ErrorCode != "" {
+		return oauthErr
+	}
+
+	var response Response
+	if json.Unmarshal(respBytes, &response) == nil && response.Status == "error" {
+		return errors.New(response.Msg)
+	}
+
+	if statusCode < 200 || statusCode > 299 {
+		return fmt.Errorf("status code: %d, body: %s", statusCode, string(respBytes))
+	}
+
+	return nil
+}
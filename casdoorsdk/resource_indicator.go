@@ -0,0 +1,117 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+)
+
+// WithResources sets the resource indicators of RFC 8707: the absolute URIs of the APIs the
+// token is meant for, so that Casdoor issues a token whose audience is restricted to them.
+// It applies to the sign-in URL (GetSigninUrlWithOptions()) and to all the token grants
+// (GetOAuthToken(), RefreshOAuthToken(), GetOAuthTokenByPassword(), ...), which check that the
+// audience of the returned access token contains the requested resources.
+// The API receiving the token should check its audience too, with
+// ParseJwtTokenWithOptions(token, WithExpectedAudiences("https://api.example.com")).
+func WithResources(resources ...string) OAuthOption {
+	return func(opts *oauthOptions) {
+		opts.resources = append(opts.resources, resources...)
+	}
+}
+
+// checkResourceIndicators checks that the resources are absolute URIs without a fragment,
+// as required by RFC 8707.
+func checkResourceIndicators(resources []string) error {
+	for _, resource := range resources {
+		u, err := url.Parse(resource)
+		if err != nil || !u.IsAbs() || u.Fragment != "" {
+			return fmt.Errorf("invalid resource indicator %q: it should be an absolute URI without a fragment", resource)
+		}
+	}
+
+	return nil
+}
+
+// checkTokenResources checks that the audience of the access token contains all the requested
+// resources, so that a server ignoring the resource indicators doesn't go unnoticed. The token
+// was just received from the token endpoint, so its signature is not checked here.
+func checkTokenResources(token *oauth2.Token, resources []string) (*oauth2.Token, error) {
+	if len(resources) == 0 {
+		return token, nil
+	}
+
+	claims := &Claims{}
+	_, _, err := jwt.NewParser().ParseUnverified(token.AccessToken, claims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the access token to check its audience: %w", err)
+	}
+
+	for _, resource := range resources {
+		if !containsString(claims.Audience, resource) {
+			return nil, newValidationError(ErrInvalidAudience, "the requested resource %q is not in the token's audience %v", resource, []string(claims.Audience))
+		}
+	}
+
+	return token, nil
+}
+
+// resourceTransport adds the "resource" parameters to the form requests sent to the token
+// endpoint. The oauth2 package can only set one value per parameter, while RFC 8707 allows
+// several resources.
+type resourceTransport struct {
+	resources []string
+	base      http.RoundTripper
+}
+
+func (t *resourceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if req.Body == nil || mediaType != "application/x-www-form-urlencoded" {
+		return t.base.RoundTrip(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	err = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, err
+	}
+	for _, resource := range t.resources {
+		form.Add("resource", resource)
+	}
+	body = []byte(form.Encode())
+
+	resourceReq := req.Clone(req.Context())
+	resourceReq.Body = io.NopCloser(bytes.NewReader(body))
+	resourceReq.ContentLength = int64(len(body))
+	resourceReq.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return t.base.RoundTrip(resourceReq)
+}
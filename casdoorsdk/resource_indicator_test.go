@@ -0,0 +1,126 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestResourceIndicators(t *testing.T) {
+	signer := newTestSigner(t)
+	var serverUrl string
+	var gotResources []string
+	// ignoreResources simulates a server that doesn't support the resource indicators
+	ignoreResources := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := r.ParseForm()
+		if err != nil {
+			t.Errorf("Failed to parse form: %v", err)
+		}
+		gotResources = r.PostForm["resource"]
+
+		claims := newTestClaims(serverUrl, "client-id")
+		if !ignoreResources {
+			claims.Audience = append(claims.Audience, gotResources...)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  signer.sign(t, claims),
+			"refresh_token": "refresh-token",
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+		})
+	}))
+	defer server.Close()
+	serverUrl = server.URL
+
+	client := NewClient(server.URL, "client-id", "client-secret", signer.Certificate, "built-in", "app-built-in")
+	resources := []string{"https://api.example.com", "https://billing.example.com/v1"}
+
+	grants := map[string]func(opts ...OAuthOption) error{
+		"authorization_code": func(opts ...OAuthOption) error {
+			_, err := client.GetOAuthToken("code", "state", opts...)
+			return err
+		},
+		"refresh_token": func(opts ...OAuthOption) error {
+			_, err := client.RefreshOAuthToken("refresh-token", opts...)
+			return err
+		},
+		"password": func(opts ...OAuthOption) error {
+			_, err := client.GetOAuthTokenByPassword("alice", "123", opts...)
+			return err
+		},
+		"client_credentials": func(opts ...OAuthOption) error {
+			_, err := client.GetOAuthTokenByClientCredentialsWithOptions(context.Background(), nil, opts...)
+			return err
+		},
+	}
+
+	for name, grant := range grants {
+		t.Run(name, func(t *testing.T) {
+			ignoreResources = false
+			err := grant(WithResources(resources...))
+			if err != nil {
+				t.Fatalf("Failed to get token: %v", err)
+			}
+			if !reflect.DeepEqual(gotResources, resources) {
+				t.Errorf("Expected resources %v, got %v", resources, gotResources)
+			}
+
+			ignoreResources = true
+			err = grant(WithResources(resources...))
+			if !errors.Is(err, ErrInvalidAudience) {
+				t.Errorf("Expected ErrInvalidAudience, got %v", err)
+			}
+
+			err = grant(WithResources("api.example.com"))
+			if err == nil {
+				t.Errorf("Expected an error for a relative resource")
+			}
+		})
+	}
+}
+
+func TestGetSigninUrlWithOptions(t *testing.T) {
+	client := NewClient("https://door.casdoor.com", "client-id", "client-secret", "", "built-in", "app-built-in")
+
+	signinUrl, err := client.GetSigninUrlWithOptions("https://app.example.com/callback",
+		WithResources("https://api.example.com", "https://billing.example.com/v1"))
+	if err != nil {
+		t.Fatalf("Failed to get signin url: %v", err)
+	}
+
+	u, err := url.Parse(signinUrl)
+	if err != nil {
+		t.Fatalf("Failed to parse signin url: %v", err)
+	}
+	expected := []string{"https://api.example.com", "https://billing.example.com/v1"}
+	if got := u.Query()["resource"]; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected resources %v, got %v", expected, got)
+	}
+
+	_, err = client.GetSigninUrlWithOptions("https://app.example.com/callback", WithResources("https://api.example.com#fragment"))
+	if err == nil {
+		t.Errorf("Expected an error for a resource with a fragment")
+	}
+}
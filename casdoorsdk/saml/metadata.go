@@ -0,0 +1,138 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package saml
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+)
+
+const (
+	BindingHttpRedirect = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	BindingHttpPost     = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+)
+
+// IdpMetadata is what a ServiceProvider needs to know about Casdoor's SAML IdP, usually
+// parsed from its metadata by ParseIdpMetadata() or FetchIdpMetadata().
+type IdpMetadata struct {
+	// EntityId is the expected Issuer of the SAML Responses.
+	EntityId string
+	// SsoRedirectUrl and SsoPostUrl are the sign-in URLs of the HTTP-Redirect and HTTP-POST bindings.
+	SsoRedirectUrl string
+	SsoPostUrl     string
+	// Certificates are the certificates whose keys may sign the SAML Responses.
+	Certificates []*x509.Certificate
+}
+
+type entityDescriptor struct {
+	XMLName          xml.Name          `xml:"EntityDescriptor"`
+	EntityId         string            `xml:"entityID,attr"`
+	IdpSsoDescriptor *idpSsoDescriptor `xml:"IDPSSODescriptor"`
+}
+
+type idpSsoDescriptor struct {
+	KeyDescriptors       []keyDescriptor `xml:"KeyDescriptor"`
+	SingleSignOnServices []endpoint      `xml:"SingleSignOnService"`
+}
+
+type keyDescriptor struct {
+	Use          string   `xml:"use,attr"`
+	Certificates []string `xml:"KeyInfo>X509Data>X509Certificate"`
+}
+
+type endpoint struct {
+	Binding  string `xml:"Binding,attr"`
+	Location string `xml:"Location,attr"`
+}
+
+// ParseIdpMetadata parses the SAML 2.0 metadata of an IdP, which should contain an
+// IDPSSODescriptor with a signing certificate and a sign-in URL.
+func ParseIdpMetadata(data []byte) (*IdpMetadata, error) {
+	var descriptor entityDescriptor
+	err := xml.Unmarshal(data, &descriptor)
+	if err != nil {
+		return nil, err
+	}
+
+	if descriptor.IdpSsoDescriptor == nil {
+		return nil, errors.New("the metadata has no IDPSSODescriptor")
+	}
+
+	metadata := &IdpMetadata{EntityId: descriptor.EntityId}
+	for _, service := range descriptor.IdpSsoDescriptor.SingleSignOnServices {
+		switch service.Binding {
+		case BindingHttpRedirect:
+			metadata.SsoRedirectUrl = service.Location
+		case BindingHttpPost:
+			metadata.SsoPostUrl = service.Location
+		}
+	}
+
+	for _, key := range descriptor.IdpSsoDescriptor.KeyDescriptors {
+		if key.Use != "" && key.Use != "signing" {
+			continue
+		}
+
+		for _, certificate := range key.Certificates {
+			cert, err := parseCertificate(certificate)
+			if err != nil {
+				return nil, err
+			}
+			metadata.Certificates = append(metadata.Certificates, cert)
+		}
+	}
+
+	if metadata.SsoRedirectUrl == "" && metadata.SsoPostUrl == "" {
+		return nil, errors.New("the metadata has no SingleSignOnService")
+	}
+	if len(metadata.Certificates) == 0 {
+		return nil, errors.New("the metadata has no signing certificate")
+	}
+
+	return metadata, nil
+}
+
+// FetchIdpMetadata gets the SAML IdP metadata of the client's application from Casdoor, see
+// casdoorsdk.Client.GetSamlMetadata(), and parses it.
+func FetchIdpMetadata(client *casdoorsdk.Client) (*IdpMetadata, error) {
+	data, err := client.GetSamlMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseIdpMetadata(data)
+}
+
+// parseCertificate parses a certificate of the metadata, in base64 DER or in PEM.
+func parseCertificate(certificate string) (*x509.Certificate, error) {
+	certificate = strings.TrimSpace(certificate)
+	if block, _ := pem.Decode([]byte(certificate)); block != nil {
+		return x509.ParseCertificate(block.Bytes)
+	}
+
+	der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(certificate), ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate in the metadata: %w", err)
+	}
+
+	return x509.ParseCertificate(der)
+}
@@ -0,0 +1,306 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package saml implements a SAML 2.0 service provider (SP) signing the users in with the SAML
+// IdP of a Casdoor application:
+//
+//	metadata, err := saml.FetchIdpMetadata(client)
+//	sp, err := saml.NewServiceProvider("https://app.example.com/saml/metadata",
+//		"https://app.example.com/saml/acs", metadata, saml.WithSigningKey(key, cert))
+//
+//	// the sign-in handler
+//	redirectUrl, requestId, err := sp.MakeRedirectAuthnRequest(relayState)
+//	// keep the requestId in the user's session, then redirect to redirectUrl
+//
+//	// the assertion consumer service (ACS) handler, at the application's "SAML reply URL"
+//	principal, err := sp.ParseResponseForm(r, requestId)
+//
+// The ACS URL should be the "SAML reply URL" of the application in Casdoor.
+//
+// It is a separate module, so that the SDK itself doesn't depend on the XML signature libraries:
+//
+//	go get github.com/casdoor/casdoor-go-sdk/casdoorsdk/saml
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+const (
+	protocolNamespace  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	assertionNamespace = "urn:oasis:names:tc:SAML:2.0:assertion"
+	timeFormat         = "2006-01-02T15:04:05.000Z"
+)
+
+// Option is a function type for configuring a ServiceProvider.
+type Option func(*ServiceProvider)
+
+// WithSigningKey signs the AuthnRequests with the key, the cert is sent in the signatures
+// and should be the one of the SP in Casdoor.
+func WithSigningKey(key *rsa.PrivateKey, cert *x509.Certificate) Option {
+	return func(sp *ServiceProvider) {
+		sp.key = key
+		sp.cert = cert
+	}
+}
+
+// WithLeeway sets the clock skew allowed when checking the validity periods of the SAML
+// Responses, the default is 1 minute.
+func WithLeeway(leeway time.Duration) Option {
+	return func(sp *ServiceProvider) {
+		sp.leeway = leeway
+	}
+}
+
+// WithIdpInitiated accepts the SAML Responses without InResponseTo, i.e., the sign-ins
+// started at Casdoor instead of by an AuthnRequest. They can't be bound to the browser that
+// receives them, so they should only be allowed when needed.
+func WithIdpInitiated() Option {
+	return func(sp *ServiceProvider) {
+		sp.allowIdpInitiated = true
+	}
+}
+
+// ServiceProvider makes the AuthnRequests sent to Casdoor's SAML IdP and verifies its SAML
+// Responses, see NewServiceProvider().
+type ServiceProvider struct {
+	entityId          string
+	acsUrl            string
+	idp               *IdpMetadata
+	key               *rsa.PrivateKey
+	cert              *x509.Certificate
+	leeway            time.Duration
+	allowIdpInitiated bool
+	now               func() time.Time
+}
+
+// NewServiceProvider returns a ServiceProvider identified by the entityId, the Issuer of its
+// AuthnRequests and the expected Audience of the assertions, whose assertion consumer
+// service is at acsUrl.
+func NewServiceProvider(entityId string, acsUrl string, idp *IdpMetadata, opts ...Option) (*ServiceProvider, error) {
+	if entityId == "" || acsUrl == "" {
+		return nil, errors.New("NewServiceProvider() error: the entityId and acsUrl should not be empty")
+	}
+	if idp == nil || len(idp.Certificates) == 0 {
+		return nil, errors.New("NewServiceProvider() error: the IdP metadata should have a certificate")
+	}
+
+	sp := &ServiceProvider{
+		entityId: entityId,
+		acsUrl:   acsUrl,
+		idp:      idp,
+		leeway:   time.Minute,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(sp)
+	}
+
+	if sp.key != nil && sp.cert == nil {
+		return nil, errors.New("NewServiceProvider() error: the signing key needs a certificate")
+	}
+
+	return sp, nil
+}
+
+func (sp *ServiceProvider) newAuthnRequest(destination string) (*etree.Element, string) {
+	id := generateId()
+	request := etree.NewElement("samlp:AuthnRequest")
+	request.CreateAttr("xmlns:samlp", protocolNamespace)
+	request.CreateAttr("xmlns:saml", assertionNamespace)
+	request.CreateAttr("ID", id)
+	request.CreateAttr("Version", "2.0")
+	request.CreateAttr("IssueInstant", sp.now().UTC().Format(timeFormat))
+	request.CreateAttr("Destination", destination)
+	request.CreateAttr("AssertionConsumerServiceURL", sp.acsUrl)
+	request.CreateAttr("ProtocolBinding", BindingHttpPost)
+	request.CreateElement("saml:Issuer").SetText(sp.entityId)
+	nameIdPolicy := request.CreateElement("samlp:NameIDPolicy")
+	nameIdPolicy.CreateAttr("Format", "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified")
+	nameIdPolicy.CreateAttr("AllowCreate", "true")
+
+	return request, id
+}
+
+func (sp *ServiceProvider) newSigningContext() (*dsig.SigningContext, error) {
+	signingContext, err := dsig.NewSigningContext(sp.key, [][]byte{sp.cert.Raw})
+	if err != nil {
+		return nil, err
+	}
+
+	signingContext.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+	return signingContext, nil
+}
+
+// MakeRedirectAuthnRequest returns the URL of an AuthnRequest with the HTTP-Redirect binding,
+// signed in the query when a signing key is set, and the ID of the request, which should be
+// kept in the user's session and passed to ParseResponse(). The relayState is returned as is
+// with the SAML Response, typically the page the user was on.
+func (sp *ServiceProvider) MakeRedirectAuthnRequest(relayState string) (string, string, error) {
+	if sp.idp.SsoRedirectUrl == "" {
+		return "", "", errors.New("the IdP has no HTTP-Redirect sign-in URL")
+	}
+
+	request, id := sp.newAuthnRequest(sp.idp.SsoRedirectUrl)
+	doc := etree.NewDocument()
+	doc.SetRoot(request)
+	requestBytes, err := doc.WriteToBytes()
+	if err != nil {
+		return "", "", err
+	}
+
+	var deflated bytes.Buffer
+	writer, err := flate.NewWriter(&deflated, flate.DefaultCompression)
+	if err != nil {
+		return "", "", err
+	}
+	_, err = writer.Write(requestBytes)
+	if err != nil {
+		return "", "", err
+	}
+	err = writer.Close()
+	if err != nil {
+		return "", "", err
+	}
+
+	// the signature covers the query parameters in this order, see section 3.4.4.1 of
+	// the SAML 2.0 bindings
+	query := "SAMLRequest=" + url.QueryEscape(base64.StdEncoding.EncodeToString(deflated.Bytes()))
+	if relayState != "" {
+		query += "&RelayState=" + url.QueryEscape(relayState)
+	}
+
+	if sp.key != nil {
+		signingContext, err := sp.newSigningContext()
+		if err != nil {
+			return "", "", err
+		}
+
+		query += "&SigAlg=" + url.QueryEscape(signingContext.GetSignatureMethodIdentifier())
+		signature, err := signingContext.SignString(query)
+		if err != nil {
+			return "", "", err
+		}
+		query += "&Signature=" + url.QueryEscape(base64.StdEncoding.EncodeToString(signature))
+	}
+
+	separator := "?"
+	if strings.Contains(sp.idp.SsoRedirectUrl, "?") {
+		separator = "&"
+	}
+
+	return sp.idp.SsoRedirectUrl + separator + query, id, nil
+}
+
+// PostRequest is an AuthnRequest with the HTTP-POST binding, see MakePostAuthnRequest().
+type PostRequest struct {
+	// Id is the ID of the request, which should be kept in the user's session and passed to ParseResponse().
+	Id string
+	// Url is where the form is posted.
+	Url string
+	// SAMLRequest and RelayState are the values of the form.
+	SAMLRequest string
+	RelayState  string
+}
+
+// MakePostAuthnRequest returns an AuthnRequest with the HTTP-POST binding, with an enveloped
+// XML signature when a signing key is set. It is sent by the browser with the form written by
+// PostRequest.WriteForm().
+func (sp *ServiceProvider) MakePostAuthnRequest(relayState string) (*PostRequest, error) {
+	if sp.idp.SsoPostUrl == "" {
+		return nil, errors.New("the IdP has no HTTP-POST sign-in URL")
+	}
+
+	request, id := sp.newAuthnRequest(sp.idp.SsoPostUrl)
+	if sp.key != nil {
+		signingContext, err := sp.newSigningContext()
+		if err != nil {
+			return nil, err
+		}
+
+		request, err = signingContext.SignEnveloped(request)
+		if err != nil {
+			return nil, err
+		}
+
+		moveSignature(request)
+	}
+
+	doc := etree.NewDocument()
+	doc.SetRoot(request)
+	requestBytes, err := doc.WriteToBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostRequest{
+		Id:          id,
+		Url:         sp.idp.SsoPostUrl,
+		SAMLRequest: base64.StdEncoding.EncodeToString(requestBytes),
+		RelayState:  relayState,
+	}, nil
+}
+
+// moveSignature moves the signature appended by dsig.SigningContext.SignEnveloped() right
+// after the Issuer, as required by the SAML schemas.
+func moveSignature(el *etree.Element) {
+	// the appended signature has no parent for etree, so it is removed by its index
+	signature := el.RemoveChildAt(len(el.Child) - 1)
+	el.InsertChildAt(1, signature)
+}
+
+var postFormTemplate = template.Must(template.New("").Parse(`<!DOCTYPE html>
+<html>
+<body onload="document.forms[0].submit()">
+<form method="post" action="{{.Url}}">
+<input type="hidden" name="SAMLRequest" value="{{.SAMLRequest}}">
+{{if .RelayState}}<input type="hidden" name="RelayState" value="{{.RelayState}}">{{end}}
+<noscript><button type="submit">Continue</button></noscript>
+</form>
+</body>
+</html>
+`))
+
+// WriteForm writes an HTML page posting the request to Casdoor as soon as it is loaded.
+func (r *PostRequest) WriteForm(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	return postFormTemplate.Execute(w, r)
+}
+
+// generateId returns a random ID, which must not start with a digit in XML.
+func generateId() string {
+	b := make([]byte, 20)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+
+	return "_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,421 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+)
+
+var (
+	ErrMissingSignature    = errors.New("the SAML assertion is not signed")
+	ErrInvalidSignature    = errors.New("the SAML signature is invalid")
+	ErrStatusNotSuccess    = errors.New("the SAML Response status is not success")
+	ErrInvalidInResponseTo = errors.New("the SAML Response doesn't answer a pending AuthnRequest")
+	ErrInvalidDestination  = errors.New("the SAML Response is not for this service provider")
+)
+
+// maxResponseSize bounds the decoded SAML Responses, inflated ones included.
+const maxResponseSize = 1 << 20
+
+const statusSuccess = "urn:oasis:names:tc:SAML:2.0:status:Success"
+
+// Principal is the user signed in by a SAML Response.
+type Principal struct {
+	NameId       string
+	NameIdFormat string
+	SessionIndex string
+
+	// Email, Name, DisplayName and Roles are the attributes Casdoor adds to its assertions.
+	Email       string
+	Name        string
+	DisplayName string
+	Roles       []string
+
+	// Attributes are all the attributes of the assertion by name, including the "SAML
+	// attributes" configured in the application.
+	Attributes map[string][]string
+
+	// ExpiresAt is the end of the validity of the assertion.
+	ExpiresAt time.Time
+}
+
+func newValidationError(err error, format string, a ...interface{}) error {
+	return &casdoorsdk.ValidationError{Err: err, Detail: fmt.Sprintf(format, a...)}
+}
+
+// ParseResponseForm is ParseResponse() for the "SAMLResponse" parameter of the request
+// received by the assertion consumer service.
+func (sp *ServiceProvider) ParseResponseForm(r *http.Request, requestIds ...string) (*Principal, error) {
+	if r.Method != http.MethodPost {
+		return nil, errors.New("the SAML Response should be posted")
+	}
+
+	samlResponse := r.PostFormValue("SAMLResponse")
+	if samlResponse == "" {
+		return nil, errors.New("the SAMLResponse parameter is missing")
+	}
+
+	return sp.ParseResponse(samlResponse, requestIds...)
+}
+
+// ParseResponse verifies a base64 SAML Response of Casdoor, deflated or not (see the "Enable
+// SAML compression" option of the application), and returns the user it signs in. The
+// Response or its assertion must be signed by one of the IdP certificates, with the C14N 1.0
+// or exclusive C14N canonicalization. Then the status, destination, issuer, subject
+// confirmation, conditions and audience are checked. The InResponseTo must be one of the
+// requestIds, the IDs of the AuthnRequests of the user's session, unless WithIdpInitiated() is
+// set and it is empty.
+//
+// The errors about the claims are casdoorsdk.ValidationError values, so that they can be
+// checked with errors.Is(err, casdoorsdk.ErrInvalidAudience) as with the tokens.
+func (sp *ServiceProvider) ParseResponse(samlResponse string, requestIds ...string) (*Principal, error) {
+	data, err := decodeResponse(samlResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := etree.NewDocument()
+	err = doc.ReadFromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	response := doc.Root()
+	if response == nil || response.Tag != "Response" || response.NamespaceURI() != protocolNamespace {
+		return nil, errors.New("the document is not a SAML Response")
+	}
+
+	// only the signed elements are used from then on, so that unsigned elements added to
+	// the document can't be mistaken for them
+	responseSigned := findChild(response, dsig.Namespace, dsig.SignatureTag) != nil
+	if responseSigned {
+		response, err = sp.verifySignature(response)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = sp.validateResponse(response, requestIds)
+	if err != nil {
+		return nil, err
+	}
+
+	if findChild(response, assertionNamespace, "EncryptedAssertion") != nil {
+		return nil, errors.New("the encrypted SAML assertions are not supported")
+	}
+
+	assertions := findChildren(response, assertionNamespace, "Assertion")
+	if len(assertions) != 1 {
+		return nil, fmt.Errorf("the SAML Response should have 1 assertion, got %d", len(assertions))
+	}
+
+	assertion := assertions[0]
+	if findChild(assertion, dsig.Namespace, dsig.SignatureTag) != nil {
+		assertion, err = sp.verifySignature(assertion)
+		if err != nil {
+			return nil, err
+		}
+	} else if !responseSigned {
+		return nil, ErrMissingSignature
+	}
+
+	return sp.parseAssertion(assertion, response.SelectAttrValue("InResponseTo", ""))
+}
+
+func decodeResponse(samlResponse string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(samlResponse), ""))
+	if err != nil {
+		return nil, fmt.Errorf("the SAML Response is not base64: %w", err)
+	}
+
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		data, err = io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(data)), maxResponseSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("the SAML Response can't be inflated: %w", err)
+		}
+	}
+
+	if len(data) > maxResponseSize {
+		return nil, errors.New("the SAML Response is too large")
+	}
+	if bytes.Contains(data, []byte("<!DOCTYPE")) {
+		return nil, errors.New("the SAML Response should not have a DTD")
+	}
+
+	return data, nil
+}
+
+// verifySignature returns the element signed by its enveloped signature, without the signature.
+func (sp *ServiceProvider) verifySignature(el *etree.Element) (*etree.Element, error) {
+	// the signed element is detached with the namespaces of its ancestors, which the
+	// canonicalization can depend on
+	nsContext, err := etreeutils.NSBuildParentContext(el)
+	if err != nil {
+		return nil, err
+	}
+	detached, err := etreeutils.NSDetatch(nsContext, el)
+	if err != nil {
+		return nil, err
+	}
+
+	validationContext := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{Roots: sp.idp.Certificates})
+	signed, err := validationContext.Validate(detached)
+	if err != nil {
+		return nil, newValidationError(ErrInvalidSignature, "%s: %s", el.Tag, err.Error())
+	}
+
+	return signed, nil
+}
+
+func (sp *ServiceProvider) validateResponse(response *etree.Element, requestIds []string) error {
+	status := findChild(response, protocolNamespace, "Status")
+	statusCode := findChild(status, protocolNamespace, "StatusCode")
+	if statusCode == nil {
+		return newValidationError(casdoorsdk.ErrMissingClaim, "StatusCode")
+	}
+	if value := statusCode.SelectAttrValue("Value", ""); value != statusSuccess {
+		var message string
+		if statusMessage := findChild(status, protocolNamespace, "StatusMessage"); statusMessage != nil {
+			message = statusMessage.Text()
+		}
+		return newValidationError(ErrStatusNotSuccess, "%s %s", value, message)
+	}
+
+	destination := response.SelectAttrValue("Destination", "")
+	if destination != "" && destination != sp.acsUrl {
+		return newValidationError(ErrInvalidDestination, "expected %q, got %q", sp.acsUrl, destination)
+	}
+
+	err := sp.validateInResponseTo(response.SelectAttrValue("InResponseTo", ""), requestIds)
+	if err != nil {
+		return err
+	}
+
+	issuer := findChild(response, assertionNamespace, "Issuer")
+	if issuer != nil {
+		return sp.validateIssuer(issuer.Text())
+	}
+
+	return nil
+}
+
+func (sp *ServiceProvider) validateInResponseTo(inResponseTo string, requestIds []string) error {
+	if inResponseTo == "" {
+		if sp.allowIdpInitiated {
+			return nil
+		}
+
+		return newValidationError(ErrInvalidInResponseTo, "the IdP-initiated sign-ins are not allowed")
+	}
+
+	for _, requestId := range requestIds {
+		if requestId != "" && inResponseTo == requestId {
+			return nil
+		}
+	}
+
+	return newValidationError(ErrInvalidInResponseTo, "unknown request %q", inResponseTo)
+}
+
+func (sp *ServiceProvider) validateIssuer(issuer string) error {
+	issuer = strings.TrimSpace(issuer)
+	if sp.idp.EntityId != "" && issuer != sp.idp.EntityId {
+		return newValidationError(casdoorsdk.ErrInvalidIssuer, "expected %q, got %q", sp.idp.EntityId, issuer)
+	}
+
+	return nil
+}
+
+// validateTime checks that now is in [notBefore - leeway, notOnOrAfter + leeway).
+func (sp *ServiceProvider) validateTime(el *etree.Element, now time.Time) (time.Time, error) {
+	var notOnOrAfter time.Time
+	if value := el.SelectAttrValue("NotBefore", ""); value != "" {
+		notBefore, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return notOnOrAfter, err
+		}
+		if now.Add(sp.leeway).Before(notBefore) {
+			return notOnOrAfter, newValidationError(casdoorsdk.ErrTokenNotValidYet, "%s is valid from %s", el.Tag, value)
+		}
+	}
+
+	if value := el.SelectAttrValue("NotOnOrAfter", ""); value != "" {
+		var err error
+		notOnOrAfter, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return notOnOrAfter, err
+		}
+		if !now.Add(-sp.leeway).Before(notOnOrAfter) {
+			return notOnOrAfter, newValidationError(casdoorsdk.ErrTokenExpired, "%s expired at %s", el.Tag, value)
+		}
+	}
+
+	return notOnOrAfter, nil
+}
+
+func (sp *ServiceProvider) parseAssertion(assertion *etree.Element, inResponseTo string) (*Principal, error) {
+	now := sp.now()
+	issuer := findChild(assertion, assertionNamespace, "Issuer")
+	if issuer == nil {
+		return nil, newValidationError(casdoorsdk.ErrMissingClaim, "Issuer")
+	}
+	err := sp.validateIssuer(issuer.Text())
+	if err != nil {
+		return nil, err
+	}
+
+	subject := findChild(assertion, assertionNamespace, "Subject")
+	nameId := findChild(subject, assertionNamespace, "NameID")
+	if nameId == nil || strings.TrimSpace(nameId.Text()) == "" {
+		return nil, newValidationError(casdoorsdk.ErrMissingClaim, "NameID")
+	}
+
+	// a bearer subject confirmation is required, for this SP and this request
+	var confirmed bool
+	var expiresAt time.Time
+	for _, confirmation := range findChildren(subject, assertionNamespace, "SubjectConfirmation") {
+		if confirmation.SelectAttrValue("Method", "") != "urn:oasis:names:tc:SAML:2.0:cm:bearer" {
+			continue
+		}
+
+		data := findChild(confirmation, assertionNamespace, "SubjectConfirmationData")
+		if data == nil {
+			continue
+		}
+
+		if recipient := data.SelectAttrValue("Recipient", ""); recipient != sp.acsUrl {
+			return nil, newValidationError(ErrInvalidDestination, "the subject confirmation is for %q", recipient)
+		}
+		if value := data.SelectAttrValue("InResponseTo", ""); value != inResponseTo {
+			return nil, newValidationError(ErrInvalidInResponseTo, "the subject confirmation answers %q", value)
+		}
+
+		expiresAt, err = sp.validateTime(data, now)
+		if err != nil {
+			return nil, err
+		}
+		confirmed = true
+		break
+	}
+	if !confirmed {
+		return nil, newValidationError(casdoorsdk.ErrMissingClaim, "bearer SubjectConfirmation")
+	}
+
+	conditions := findChild(assertion, assertionNamespace, "Conditions")
+	if conditions == nil {
+		return nil, newValidationError(casdoorsdk.ErrMissingClaim, "Conditions")
+	}
+	conditionsExpiry, err := sp.validateTime(conditions, now)
+	if err != nil {
+		return nil, err
+	}
+	if !conditionsExpiry.IsZero() && (expiresAt.IsZero() || conditionsExpiry.Before(expiresAt)) {
+		expiresAt = conditionsExpiry
+	}
+
+	// the SP must be in each AudienceRestriction
+	for _, restriction := range findChildren(conditions, assertionNamespace, "AudienceRestriction") {
+		var audiences []string
+		for _, audience := range findChildren(restriction, assertionNamespace, "Audience") {
+			audiences = append(audiences, strings.TrimSpace(audience.Text()))
+		}
+		if !containsString(audiences, sp.entityId) {
+			return nil, newValidationError(casdoorsdk.ErrInvalidAudience, "expected %q, got %q", sp.entityId, audiences)
+		}
+	}
+
+	principal := &Principal{
+		NameId:       strings.TrimSpace(nameId.Text()),
+		NameIdFormat: nameId.SelectAttrValue("Format", ""),
+		Attributes:   map[string][]string{},
+		ExpiresAt:    expiresAt,
+	}
+
+	if authnStatement := findChild(assertion, assertionNamespace, "AuthnStatement"); authnStatement != nil {
+		principal.SessionIndex = authnStatement.SelectAttrValue("SessionIndex", "")
+	}
+
+	for _, statement := range findChildren(assertion, assertionNamespace, "AttributeStatement") {
+		for _, attribute := range findChildren(statement, assertionNamespace, "Attribute") {
+			name := attribute.SelectAttrValue("Name", "")
+			for _, value := range findChildren(attribute, assertionNamespace, "AttributeValue") {
+				principal.Attributes[name] = append(principal.Attributes[name], value.Text())
+			}
+		}
+	}
+
+	principal.Email = getFirst(principal.Attributes["Email"])
+	principal.Name = getFirst(principal.Attributes["Name"])
+	principal.DisplayName = getFirst(principal.Attributes["DisplayName"])
+	principal.Roles = principal.Attributes["Roles"]
+
+	return principal, nil
+}
+
+// findChild returns the first child of el with the namespace and tag, el can be nil.
+func findChild(el *etree.Element, namespace string, tag string) *etree.Element {
+	children := findChildren(el, namespace, tag)
+	if len(children) == 0 {
+		return nil
+	}
+
+	return children[0]
+}
+
+func findChildren(el *etree.Element, namespace string, tag string) []*etree.Element {
+	if el == nil {
+		return nil
+	}
+
+	var children []*etree.Element
+	for _, child := range el.ChildElements() {
+		if child.Tag == tag && child.NamespaceURI() == namespace {
+			children = append(children, child)
+		}
+	}
+
+	return children
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getFirst(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
@@ -0,0 +1,418 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+)
+
+const (
+	testEntityId = "https://app.example.com/saml/metadata"
+	testAcsUrl   = "https://app.example.com/saml/acs"
+	testIdpId    = "https://door.example.com"
+)
+
+func newTestKey(t *testing.T) (*rsa.PrivateKey, *x509.Certificate) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Casdoor Cert"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+
+	return key, cert
+}
+
+// testIdp signs SAML Responses shaped like the ones of Casdoor.
+type testIdp struct {
+	key  *rsa.PrivateKey
+	cert *x509.Certificate
+}
+
+type testResponse struct {
+	inResponseTo  string
+	audience      string
+	notOnOrAfter  time.Time
+	canonicalizer dsig.Canonicalizer
+	signResponse  bool
+	signAssertion bool
+}
+
+func newTestResponse(inResponseTo string) *testResponse {
+	return &testResponse{
+		inResponseTo:  inResponseTo,
+		audience:      testEntityId,
+		notOnOrAfter:  time.Now().Add(5 * time.Minute),
+		canonicalizer: dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList(""),
+		signResponse:  true,
+	}
+}
+
+func (idp *testIdp) sign(t *testing.T, el *etree.Element, canonicalizer dsig.Canonicalizer) *etree.Element {
+	signingContext, err := dsig.NewSigningContext(idp.key, [][]byte{idp.cert.Raw})
+	if err != nil {
+		t.Fatalf("Failed to create signing context: %v", err)
+	}
+	signingContext.Canonicalizer = canonicalizer
+
+	signed, err := signingContext.SignEnveloped(el)
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+
+	moveSignature(signed)
+	return signed
+}
+
+func (idp *testIdp) makeResponse(t *testing.T, r *testResponse) *etree.Document {
+	now := time.Now().UTC()
+	response := etree.NewElement("samlp:Response")
+	response.CreateAttr("xmlns:samlp", protocolNamespace)
+	response.CreateAttr("xmlns:saml", assertionNamespace)
+	response.CreateAttr("ID", generateId())
+	response.CreateAttr("Version", "2.0")
+	response.CreateAttr("IssueInstant", now.Format(timeFormat))
+	response.CreateAttr("Destination", testAcsUrl)
+	if r.inResponseTo != "" {
+		response.CreateAttr("InResponseTo", r.inResponseTo)
+	}
+	response.CreateElement("saml:Issuer").SetText(testIdpId)
+	response.CreateElement("samlp:Status").CreateElement("samlp:StatusCode").CreateAttr("Value", statusSuccess)
+
+	assertion := response.CreateElement("saml:Assertion")
+	assertion.CreateAttr("ID", generateId())
+	assertion.CreateAttr("Version", "2.0")
+	assertion.CreateAttr("IssueInstant", now.Format(timeFormat))
+	assertion.CreateElement("saml:Issuer").SetText(testIdpId)
+
+	subject := assertion.CreateElement("saml:Subject")
+	nameId := subject.CreateElement("saml:NameID")
+	nameId.CreateAttr("Format", "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified")
+	nameId.SetText("alice")
+	confirmation := subject.CreateElement("saml:SubjectConfirmation")
+	confirmation.CreateAttr("Method", "urn:oasis:names:tc:SAML:2.0:cm:bearer")
+	confirmationData := confirmation.CreateElement("saml:SubjectConfirmationData")
+	if r.inResponseTo != "" {
+		confirmationData.CreateAttr("InResponseTo", r.inResponseTo)
+	}
+	confirmationData.CreateAttr("Recipient", testAcsUrl)
+	confirmationData.CreateAttr("NotOnOrAfter", r.notOnOrAfter.UTC().Format(timeFormat))
+
+	conditions := assertion.CreateElement("saml:Conditions")
+	conditions.CreateAttr("NotBefore", now.Add(-time.Minute).Format(timeFormat))
+	conditions.CreateAttr("NotOnOrAfter", r.notOnOrAfter.UTC().Format(timeFormat))
+	conditions.CreateElement("saml:AudienceRestriction").CreateElement("saml:Audience").SetText(r.audience)
+
+	authnStatement := assertion.CreateElement("saml:AuthnStatement")
+	authnStatement.CreateAttr("AuthnInstant", now.Format(timeFormat))
+	authnStatement.CreateAttr("SessionIndex", "session-index")
+
+	attributes := assertion.CreateElement("saml:AttributeStatement")
+	for _, attribute := range [][]string{{"Email", "alice@example.com"}, {"Name", "alice"}, {"DisplayName", "Alice"}, {"Roles", "admin"}, {"Roles", "dev"}} {
+		el := attributes.CreateElement("saml:Attribute")
+		el.CreateAttr("Name", attribute[0])
+		el.CreateAttr("NameFormat", "urn:oasis:names:tc:SAML:2.0:attrname-format:basic")
+		el.CreateElement("saml:AttributeValue").SetText(attribute[1])
+	}
+
+	if r.signAssertion {
+		// the assertion is signed with the namespaces of the Response, which its C14N 1.0
+		// canonicalization includes
+		nsContext, err := etreeutils.NSBuildParentContext(assertion)
+		if err != nil {
+			t.Fatalf("Failed to build namespace context: %v", err)
+		}
+		detached, err := etreeutils.NSDetatch(nsContext, assertion)
+		if err != nil {
+			t.Fatalf("Failed to detach assertion: %v", err)
+		}
+		signed := idp.sign(t, detached, r.canonicalizer)
+		index := assertion.Index()
+		response.RemoveChild(assertion)
+		response.InsertChildAt(index, signed)
+	}
+	if r.signResponse {
+		response = idp.sign(t, response, r.canonicalizer)
+	}
+
+	doc := etree.NewDocument()
+	doc.SetRoot(response)
+	return doc
+}
+
+func encodeDocument(t *testing.T, doc *etree.Document) string {
+	data, err := doc.WriteToBytes()
+	if err != nil {
+		t.Fatalf("Failed to write document: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func newTestServiceProvider(t *testing.T, opts ...Option) (*ServiceProvider, *testIdp) {
+	key, cert := newTestKey(t)
+	idp := &testIdp{key: key, cert: cert}
+	metadata := &IdpMetadata{
+		EntityId:       testIdpId,
+		SsoRedirectUrl: testIdpId + "/login/saml/authorize/admin/app-built-in",
+		SsoPostUrl:     testIdpId + "/login/saml/authorize/admin/app-built-in",
+		Certificates:   []*x509.Certificate{cert},
+	}
+
+	sp, err := NewServiceProvider(testEntityId, testAcsUrl, metadata, opts...)
+	if err != nil {
+		t.Fatalf("Failed to create service provider: %v", err)
+	}
+	return sp, idp
+}
+
+func TestParseResponse(t *testing.T) {
+	sp, idp := newTestServiceProvider(t)
+
+	canonicalizers := map[string]dsig.Canonicalizer{
+		"exclusive C14N": dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList(""),
+		"C14N 1.0":       dsig.MakeC14N10RecCanonicalizer(),
+	}
+	for name, canonicalizer := range canonicalizers {
+		for _, signed := range []string{"response", "assertion", "both"} {
+			t.Run(name+" "+signed, func(t *testing.T) {
+				r := newTestResponse("request-id")
+				r.canonicalizer = canonicalizer
+				r.signResponse = signed != "assertion"
+				r.signAssertion = signed != "response"
+
+				principal, err := sp.ParseResponse(encodeDocument(t, idp.makeResponse(t, r)), "other-id", "request-id")
+				if err != nil {
+					t.Fatalf("Failed to parse response: %v", err)
+				}
+				if principal.NameId != "alice" || principal.Email != "alice@example.com" || principal.DisplayName != "Alice" ||
+					principal.SessionIndex != "session-index" || strings.Join(principal.Roles, ",") != "admin,dev" {
+					t.Errorf("Unexpected principal: %+v", principal)
+				}
+			})
+		}
+	}
+}
+
+func TestParseResponseRejected(t *testing.T) {
+	sp, idp := newTestServiceProvider(t)
+
+	tests := []struct {
+		name     string
+		response *testResponse
+		modify   func(doc *etree.Document)
+		err      error
+	}{
+		{"unsigned", &testResponse{inResponseTo: "request-id", audience: testEntityId, notOnOrAfter: time.Now().Add(time.Minute)}, nil, ErrMissingSignature},
+		{"wrong audience", &testResponse{inResponseTo: "request-id", audience: "https://other.example.com", notOnOrAfter: time.Now().Add(time.Minute),
+			canonicalizer: dsig.MakeC14N10RecCanonicalizer(), signResponse: true}, nil, casdoorsdk.ErrInvalidAudience},
+		{"expired", &testResponse{inResponseTo: "request-id", audience: testEntityId, notOnOrAfter: time.Now().Add(-time.Hour),
+			canonicalizer: dsig.MakeC14N10RecCanonicalizer(), signResponse: true}, nil, casdoorsdk.ErrTokenExpired},
+		{"unknown request", newTestResponse("forged-id"), nil, ErrInvalidInResponseTo},
+		{"IdP-initiated", newTestResponse(""), nil, ErrInvalidInResponseTo},
+		{"tampered", newTestResponse("request-id"), func(doc *etree.Document) {
+			doc.FindElement("//NameID").SetText("admin")
+		}, ErrInvalidSignature},
+		{"wrapped assertion", newTestResponse("request-id"), func(doc *etree.Document) {
+			// an unsigned assertion added next to the signed one
+			assertion := doc.FindElement("//Assertion").Copy()
+			assertion.FindElement("//NameID").SetText("admin")
+			doc.Root().AddChild(assertion)
+		}, ErrInvalidSignature},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc := idp.makeResponse(t, test.response)
+			if test.modify != nil {
+				test.modify(doc)
+			}
+
+			_, err := sp.ParseResponse(encodeDocument(t, doc), "request-id")
+			if !errors.Is(err, test.err) {
+				t.Errorf("Expected %v, got %v", test.err, err)
+			}
+		})
+	}
+
+	// A response signed by another key is rejected
+	otherKey, otherCert := newTestKey(t)
+	otherIdp := &testIdp{key: otherKey, cert: otherCert}
+	_, err := sp.ParseResponse(encodeDocument(t, otherIdp.makeResponse(t, newTestResponse("request-id"))), "request-id")
+	if !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected %v, got %v", ErrInvalidSignature, err)
+	}
+}
+
+func TestParseResponseCompressed(t *testing.T) {
+	sp, idp := newTestServiceProvider(t, WithIdpInitiated())
+
+	data, err := idp.makeResponse(t, newTestResponse("")).WriteToBytes()
+	if err != nil {
+		t.Fatalf("Failed to write document: %v", err)
+	}
+	var deflated bytes.Buffer
+	writer, _ := flate.NewWriter(&deflated, flate.DefaultCompression)
+	_, _ = writer.Write(data)
+	_ = writer.Close()
+
+	form := url.Values{"SAMLResponse": {base64.StdEncoding.EncodeToString(deflated.Bytes())}}
+	req := httptest.NewRequest("POST", "/saml/acs", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	principal, err := sp.ParseResponseForm(req)
+	if err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if principal.Name != "alice" {
+		t.Errorf("Expected alice, got %q", principal.Name)
+	}
+}
+
+func TestMakeRedirectAuthnRequest(t *testing.T) {
+	key, cert := newTestKey(t)
+	sp, _ := newTestServiceProvider(t, WithSigningKey(key, cert))
+
+	redirectUrl, requestId, err := sp.MakeRedirectAuthnRequest("/private")
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+
+	u, err := url.Parse(redirectUrl)
+	if err != nil {
+		t.Fatalf("Failed to parse URL: %v", err)
+	}
+	query := u.Query()
+	if query.Get("RelayState") != "/private" || query.Get("SigAlg") != dsig.RSASHA256SignatureMethod {
+		t.Errorf("Unexpected URL: %s", redirectUrl)
+	}
+
+	// the signature covers the raw query before the Signature parameter
+	signedQuery := u.RawQuery[:strings.Index(u.RawQuery, "&Signature=")]
+	signature, _ := base64.StdEncoding.DecodeString(query.Get("Signature"))
+	digest := sha256.Sum256([]byte(signedQuery))
+	err = rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature)
+	if err != nil {
+		t.Errorf("Invalid signature: %v", err)
+	}
+
+	deflated, _ := base64.StdEncoding.DecodeString(query.Get("SAMLRequest"))
+	request, err := io.ReadAll(flate.NewReader(bytes.NewReader(deflated)))
+	if err != nil {
+		t.Fatalf("Failed to inflate request: %v", err)
+	}
+	doc := etree.NewDocument()
+	_ = doc.ReadFromBytes(request)
+	root := doc.Root()
+	if root.Tag != "AuthnRequest" || root.SelectAttrValue("ID", "") != requestId ||
+		root.SelectAttrValue("AssertionConsumerServiceURL", "") != testAcsUrl || doc.FindElement("//Issuer").Text() != testEntityId {
+		t.Errorf("Unexpected request: %s", request)
+	}
+}
+
+func TestMakePostAuthnRequest(t *testing.T) {
+	key, cert := newTestKey(t)
+	sp, _ := newTestServiceProvider(t, WithSigningKey(key, cert))
+
+	postRequest, err := sp.MakePostAuthnRequest("/private")
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+
+	request, _ := base64.StdEncoding.DecodeString(postRequest.SAMLRequest)
+	doc := etree.NewDocument()
+	_ = doc.ReadFromBytes(request)
+	if doc.Root().ChildElements()[1].Tag != "Signature" {
+		t.Errorf("Expected the signature after the Issuer: %s", request)
+	}
+
+	validationContext := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{Roots: []*x509.Certificate{cert}})
+	_, err = validationContext.Validate(doc.Root())
+	if err != nil {
+		t.Errorf("Invalid signature: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	err = postRequest.WriteForm(recorder)
+	if err != nil {
+		t.Fatalf("Failed to write form: %v", err)
+	}
+	if !strings.Contains(recorder.Body.String(), `action="`+postRequest.Url+`"`) {
+		t.Errorf("Unexpected form: %s", recorder.Body.String())
+	}
+}
+
+func TestFetchIdpMetadata(t *testing.T) {
+	_, cert := newTestKey(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/saml/metadata" || r.URL.Query().Get("application") != "admin/app-built-in" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = fmt.Fprintf(w, `<EntityDescriptor xmlns="urn:oasis:names:tc:SAML:2.0:metadata" xmlns:ds="http://www.w3.org/2000/09/xmldsig#" entityID="%s">
+  <IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <KeyDescriptor use="signing">
+      <ds:KeyInfo><ds:X509Data><ds:X509Certificate>%s</ds:X509Certificate></ds:X509Data></ds:KeyInfo>
+    </KeyDescriptor>
+    <SingleSignOnService Binding="%s" Location="%s/login/saml/authorize/admin/app-built-in"></SingleSignOnService>
+  </IDPSSODescriptor>
+</EntityDescriptor>`, testIdpId, base64.StdEncoding.EncodeToString(cert.Raw), BindingHttpRedirect, testIdpId)
+	}))
+	defer server.Close()
+
+	client := casdoorsdk.NewClient(server.URL, "client-id", "client-secret", "", "built-in", "app-built-in")
+	metadata, err := FetchIdpMetadata(client)
+	if err != nil {
+		t.Fatalf("Failed to fetch metadata: %v", err)
+	}
+	if metadata.EntityId != testIdpId || metadata.SsoRedirectUrl != testIdpId+"/login/saml/authorize/admin/app-built-in" ||
+		len(metadata.Certificates) != 1 || !metadata.Certificates[0].Equal(cert) {
+		t.Errorf("Unexpected metadata: %+v", metadata)
+	}
+}
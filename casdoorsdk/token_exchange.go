@@ -0,0 +1,150 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const GrantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+// The token type identifiers of RFC 8693, section 3.
+const (
+	TokenTypeAccessToken  = "urn:ietf:params:oauth:token-type:access_token"
+	TokenTypeRefreshToken = "urn:ietf:params:oauth:token-type:refresh_token"
+	TokenTypeIdToken      = "urn:ietf:params:oauth:token-type:id_token"
+	TokenTypeJwt          = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// TokenExchangeOptions holds the optional parameters of an RFC 8693 token exchange request.
+type TokenExchangeOptions struct {
+	// Audience is the logical names of the services the new token is meant for.
+	Audience []string
+	// Resource is the URIs of the services the new token is meant for.
+	Resource []string
+	// Scope narrows the scope of the new token, it can't be wider than the subject token's.
+	Scope []string
+	// ActorToken and ActorTokenType identify the party acting on behalf of the subject,
+	// e.g., the API gateway's own token got by GetOAuthTokenByClientCredentials().
+	ActorToken     string
+	ActorTokenType string
+	// RequestedTokenType is the type of the new token, it defaults to an access token.
+	RequestedTokenType string
+}
+
+type tokenExchangeResponse struct {
+	AccessToken     string `json:"access_token"`
+	IssuedTokenType string `json:"issued_token_type"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int64  `json:"expires_in"`
+	RefreshToken    string `json:"refresh_token"`
+	Scope           string `json:"scope"`
+}
+
+// ExchangeToken exchanges the subjectToken, usually a user's access token received by an API
+// gateway, for a new token via the OAuth 2.0 "Token Exchange" grant type of RFC 8693. The new
+// token can be restricted to a narrower audience, resource and scope, so that it is safe to
+// forward it to a downstream service. Unlike ImpersonateUser(), it doesn't need any password,
+// only the application's client ID and client secret.
+// The subjectTokenType is one of the TokenType* constants, like TokenTypeAccessToken. The
+// returned string is the "issued_token_type" of the new token. The token exchange grant type
+// must be enabled in the application's "Grant types" in Casdoor.
+func (c *Client) ExchangeToken(ctx context.Context, subjectToken string, subjectTokenType string, opts *TokenExchangeOptions) (*oauth2.Token, string, error) {
+	if subjectToken == "" || subjectTokenType == "" {
+		return nil, "", errors.New("ExchangeToken() error: the subjectToken and subjectTokenType should not be empty")
+	}
+
+	if opts == nil {
+		opts = &TokenExchangeOptions{}
+	}
+
+	if opts.ActorToken != "" && opts.ActorTokenType == "" {
+		return nil, "", errors.New("ExchangeToken() error: the ActorTokenType is required with the ActorToken")
+	}
+
+	err := checkResourceIndicators(opts.Resource)
+	if err != nil {
+		return nil, "", err
+	}
+
+	form := url.Values{
+		"grant_type":         {GrantTypeTokenExchange},
+		"subject_token":      {subjectToken},
+		"subject_token_type": {subjectTokenType},
+	}
+	for _, audience := range opts.Audience {
+		form.Add("audience", audience)
+	}
+	for _, resource := range opts.Resource {
+		form.Add("resource", resource)
+	}
+	if len(opts.Scope) > 0 {
+		form.Set("scope", strings.Join(opts.Scope, " "))
+	}
+	if opts.ActorToken != "" {
+		form.Set("actor_token", opts.ActorToken)
+		form.Set("actor_token_type", opts.ActorTokenType)
+	}
+	if opts.RequestedTokenType != "" {
+		form.Set("requested_token_type", opts.RequestedTokenType)
+	}
+
+	respBytes, err := c.postOAuthForm(ctx, "login/oauth/access_token", form)
+	if err != nil {
+		return nil, "", checkGrantType(GrantTypeTokenExchange, err)
+	}
+
+	var resp tokenExchangeResponse
+	err = json.Unmarshal(respBytes, &resp)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var raw map[string]interface{}
+	err = json.Unmarshal(respBytes, &raw)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  resp.AccessToken,
+		TokenType:    resp.TokenType,
+		RefreshToken: resp.RefreshToken,
+	}
+	if resp.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+
+	token, err = checkOAuthToken(token.WithExtra(raw), nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if resp.IssuedTokenType == "" || resp.IssuedTokenType == TokenTypeAccessToken {
+		token, err = checkTokenResources(token, opts.Resource)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	return token, resp.IssuedTokenType, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExchangeToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = r.ParseForm()
+		if r.Form.Get("subject_token") == "disabled" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "unsupported_grant_type"})
+			return
+		}
+
+		if r.Form.Get("grant_type") != GrantTypeTokenExchange || r.Form.Get("subject_token_type") != TokenTypeAccessToken ||
+			r.Form.Get("actor_token") != "gateway-token" || r.Form.Get("actor_token_type") != TokenTypeAccessToken {
+			t.Errorf("Unexpected token exchange request: %v", r.Form)
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":      "narrow-token",
+			"issued_token_type": TokenTypeAccessToken,
+			"token_type":        "Bearer",
+			"expires_in":        600,
+			"scope":             r.Form.Get("scope"),
+			"audience":          strings.Join(r.Form["audience"], ","),
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "client-id", "client-secret", "", "built-in", "app-built-in")
+	ctx := context.Background()
+
+	token, issuedTokenType, err := client.ExchangeToken(ctx, "user-token", TokenTypeAccessToken, &TokenExchangeOptions{
+		Audience:       []string{"orders", "billing"},
+		Scope:          []string{"orders:read"},
+		ActorToken:     "gateway-token",
+		ActorTokenType: TokenTypeAccessToken,
+	})
+	if err != nil {
+		t.Fatalf("Failed to exchange token: %v", err)
+	}
+	if token.AccessToken != "narrow-token" || issuedTokenType != TokenTypeAccessToken || token.Expiry.IsZero() {
+		t.Fatalf("Exchanged token mismatch: %v, %s", token, issuedTokenType)
+	}
+	if token.Extra("scope") != "orders:read" || token.Extra("audience") != "orders,billing" {
+		t.Fatalf("Exchange request mismatch: %v, %v", token.Extra("scope"), token.Extra("audience"))
+	}
+
+	_, _, err = client.ExchangeToken(ctx, "disabled", TokenTypeAccessToken, nil)
+	if !errors.Is(err, ErrGrantTypeNotEnabled) {
+		t.Fatalf("Expected ErrGrantTypeNotEnabled, got %v", err)
+	}
+}
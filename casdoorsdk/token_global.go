@@ -14,6 +14,8 @@
 
 package casdoorsdk
 
+import "context"
+
 func GetTokens() ([]*Token, error) {
 	return globalClient.GetTokens()
 }
@@ -41,3 +43,7 @@ func AddToken(token *Token) (bool, error) {
 func DeleteToken(token *Token) (bool, error) {
 	return globalClient.DeleteToken(token)
 }
+
+func RevokeToken(ctx context.Context, token string, tokenTypeHint string) error {
+	return globalClient.RevokeToken(ctx, token, tokenTypeHint)
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRevokeToken(t *testing.T) {
+	var revoked []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/login/oauth/revoke" {
+			http.NotFound(w, r)
+			return
+		}
+
+		clientId, clientSecret, ok := r.BasicAuth()
+		if !ok || clientId != "client-id" || clientSecret != "client-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+
+		if r.FormValue("token_type_hint") == "id_token" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "unsupported_token_type"})
+			return
+		}
+
+		revoked = append(revoked, r.FormValue("token_type_hint")+":"+r.FormValue("token"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "client-id", "client-secret", "", "built-in", "app-built-in")
+	ctx := context.Background()
+
+	err := client.RevokeToken(ctx, "refresh-token", TokenTypeHintRefreshToken)
+	if err != nil {
+		t.Fatalf("Failed to revoke token: %v", err)
+	}
+	err = client.RevokeToken(ctx, "access-token", "")
+	if err != nil {
+		t.Fatalf("Failed to revoke token: %v", err)
+	}
+	if len(revoked) != 2 || revoked[0] != "refresh_token:refresh-token" || revoked[1] != ":access-token" {
+		t.Fatalf("Revoked tokens mismatch: %v", revoked)
+	}
+
+	// The unknown hints are left to the server
+	err = client.RevokeToken(ctx, "id-token", "id_token")
+	var oauthErr *OAuthError
+	if !errors.Is(err, ErrUnsupportedTokenType) || !errors.As(err, &oauthErr) || oauthErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected ErrUnsupportedTokenType from the server, got %v", err)
+	}
+
+	client.ClientSecret = "wrong-secret"
+	err = client.RevokeToken(ctx, "access-token", TokenTypeHintAccessToken)
+	if !errors.Is(err, ErrInvalidClient) || !errors.As(err, &oauthErr) || oauthErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected ErrInvalidClient, got %v", err)
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// defaultExpiryDelta is how long before its expiry a token is refreshed by RefreshingTokenSource.
+const defaultExpiryDelta = time.Minute
+
+// RefreshingTokenSource is an oauth2.TokenSource that holds a user's OAuth token and refreshes
+// it with RefreshOAuthToken() shortly before it expires. It is safe for concurrent use: when
+// several goroutines need a new token at the same time, only one refresh request is sent and
+// all of them get its result.
+// Use NewRefreshingTokenSource() to get one and WithTokenSource() to bind it to a client.
+type RefreshingTokenSource struct {
+	client      *Client
+	expiryDelta time.Duration
+	onRefresh   func(token *oauth2.Token) error
+	oauthOpts   []OAuthOption
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+// TokenSourceOption is a function type for configuring a RefreshingTokenSource.
+type TokenSourceOption func(*RefreshingTokenSource)
+
+// WithExpiryDelta sets how long before its expiry the token is refreshed, the default is one minute.
+func WithExpiryDelta(expiryDelta time.Duration) TokenSourceOption {
+	return func(s *RefreshingTokenSource) {
+		s.expiryDelta = expiryDelta
+	}
+}
+
+// WithTokenRefreshCallback sets a function called with every refreshed token, before it is
+// handed out. It is the place to persist the token, as Casdoor may rotate the refresh token
+// on every refresh. If the callback returns an error, the refreshed token is discarded and
+// the error is returned to the caller.
+func WithTokenRefreshCallback(onRefresh func(token *oauth2.Token) error) TokenSourceOption {
+	return func(s *RefreshingTokenSource) {
+		s.onRefresh = onRefresh
+	}
+}
+
+// WithRefreshOAuthOptions sets the OAuthOption passed to RefreshOAuthToken(), like WithHTTPClient().
+func WithRefreshOAuthOptions(opts ...OAuthOption) TokenSourceOption {
+	return func(s *RefreshingTokenSource) {
+		s.oauthOpts = opts
+	}
+}
+
+// NewRefreshingTokenSource returns a RefreshingTokenSource that starts from the given token,
+// usually the one returned by GetOAuthToken() or loaded from the app's own storage.
+// The token must have a refresh token, otherwise it is only usable until it expires.
+func (c *Client) NewRefreshingTokenSource(token *oauth2.Token, opts ...TokenSourceOption) *RefreshingTokenSource {
+	s := &RefreshingTokenSource{
+		client:      c,
+		expiryDelta: defaultExpiryDelta,
+		token:       token,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Token returns the current token, refreshing it first when it expires within the expiry delta.
+func (s *RefreshingTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && s.token.AccessToken != "" && !s.isExpiring(s.token) {
+		return s.token, nil
+	}
+
+	return s.refresh()
+}
+
+// RefreshStaleToken refreshes the token unless the current access token is no longer
+// staleAccessToken, which means that another goroutine has already refreshed it. It is used
+// after the server rejected staleAccessToken, e.g., because it has been revoked.
+func (s *RefreshingTokenSource) RefreshStaleToken(staleAccessToken string) (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && s.token.AccessToken != staleAccessToken {
+		return s.token, nil
+	}
+
+	return s.refresh()
+}
+
+func (s *RefreshingTokenSource) isExpiring(token *oauth2.Token) bool {
+	if token.Expiry.IsZero() {
+		return false
+	}
+
+	return !time.Now().Add(s.expiryDelta).Before(token.Expiry)
+}
+
+// refresh must be called with s.mu held.
+func (s *RefreshingTokenSource) refresh() (*oauth2.Token, error) {
+	if s.token == nil || s.token.RefreshToken == "" {
+		return nil, errors.New("RefreshingTokenSource error: the token is expired and has no refresh token")
+	}
+
+	token, err := s.client.RefreshOAuthToken(s.token.RefreshToken, s.oauthOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.RefreshToken == "" {
+		token.RefreshToken = s.token.RefreshToken
+	}
+
+	if s.onRefresh != nil {
+		err = s.onRefresh(token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.token = token
+	return token, nil
+}
+
+// WithTokenSource returns a copy of the client that calls all the Casdoor APIs as the user whose
+// access token is returned by the tokenSource, see WithAccessToken(). The access token is asked
+// for on every request, so an expired token is refreshed transparently when the tokenSource is
+// a RefreshingTokenSource:
+//
+//	token, err := client.GetOAuthToken(code, state)
+//	tokenSource := client.NewRefreshingTokenSource(token,
+//		casdoorsdk.WithTokenRefreshCallback(saveToken))
+//	user, err := client.WithTokenSource(tokenSource).GetAccount()
+//
+// When the server answers 401 Unauthorized and the tokenSource is a RefreshingTokenSource,
+// the token is refreshed and the request is retried once.
+func (c *Client) WithTokenSource(tokenSource oauth2.TokenSource) *Client {
+	userClient := c.WithAccessToken("")
+	userClient.tokenSource = tokenSource
+	return userClient
+}
+
+// staleTokenRefresher is implemented by the token sources that can replace a rejected token.
+type staleTokenRefresher interface {
+	RefreshStaleToken(staleAccessToken string) (*oauth2.Token, error)
+}
+
+// retryUnauthorized retries the request once when the server answered 401 Unauthorized to a
+// request made with the user's access token: with a new DPoP proof when the server asks for a
+// DPoP nonce, or with a refreshed access token when the token comes from a token source that
+// can refresh it. It returns the original response when there is nothing to retry.
+func (c *Client) retryUnauthorized(req *http.Request, resp *http.Response) (*http.Response, error) {
+	accessToken := getAccessToken(req)
+	if resp.StatusCode != http.StatusUnauthorized || accessToken == "" {
+		if c.dpopSigner != nil {
+			c.dpopSigner.saveNonce(req.URL.String(), resp.Header)
+		}
+		return resp, nil
+	}
+
+	isNonceChallenge := c.dpopSigner != nil && c.dpopSigner.isNonceChallenge(req, resp)
+	if !isNonceChallenge && !c.refreshStaleToken(accessToken) {
+		return resp, nil
+	}
+
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	retryReq := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retryReq.Body = body
+	}
+
+	err := c.setAuthHeader(retryReq)
+	if err != nil {
+		return resp, nil
+	}
+
+	err = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Do(retryReq)
+}
+
+// refreshStaleToken refreshes the access token rejected by the server, and reports whether
+// the client's token source could do it.
+func (c *Client) refreshStaleToken(staleAccessToken string) bool {
+	refresher, ok := c.tokenSource.(staleTokenRefresher)
+	if !ok {
+		return false
+	}
+
+	_, err := refresher.RefreshStaleToken(staleAccessToken)
+	return err == nil
+}
+
+// getAccessToken returns the access token of the "Authorization: Bearer" or "Authorization: DPoP"
+// header of the request.
+func getAccessToken(req *http.Request) string {
+	authorization := req.Header.Get("Authorization")
+	for _, scheme := range []string{"Bearer ", "DPoP "} {
+		if len(authorization) > len(scheme) && strings.EqualFold(authorization[:len(scheme)], scheme) {
+			return authorization[len(scheme):]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestRefreshingTokenSource(t *testing.T) {
+	var refreshCount int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/login/oauth/refresh_token":
+			n := atomic.AddInt32(&refreshCount, 1)
+			time.Sleep(50 * time.Millisecond)
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token":  fmt.Sprintf("access-%d", n),
+				"refresh_token": fmt.Sprintf("refresh-%d", n),
+				"token_type":    "Bearer",
+				"expires_in":    3600,
+			})
+		case "/api/get-account":
+			if r.Header.Get("Authorization") == "Bearer revoked" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"status": "ok",
+				"data":   map[string]string{"name": r.Header.Get("Authorization")},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "client-id", "client-secret", "", "built-in", "app-built-in")
+
+	var saved []*oauth2.Token
+	tokenSource := client.NewRefreshingTokenSource(
+		&oauth2.Token{AccessToken: "expired", RefreshToken: "refresh-0", Expiry: time.Now().Add(-time.Minute)},
+		WithTokenRefreshCallback(func(token *oauth2.Token) error {
+			saved = append(saved, token)
+			return nil
+		}))
+
+	// Concurrent callers share a single refresh
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := tokenSource.Token()
+			if err != nil {
+				t.Errorf("Failed to get token: %v", err)
+				return
+			}
+			if token.AccessToken != "access-1" {
+				t.Errorf("Token mismatch: %s != access-1", token.AccessToken)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if refreshCount != 1 {
+		t.Fatalf("Expected a single refresh, got %d", refreshCount)
+	}
+	if len(saved) != 1 || saved[0].RefreshToken != "refresh-1" {
+		t.Fatalf("Refreshed token not passed to the callback: %v", saved)
+	}
+
+	// The bound client uses the current token
+	user, err := client.WithTokenSource(tokenSource).GetAccount()
+	if err != nil {
+		t.Fatalf("Failed to get account: %v", err)
+	}
+	if user.Name != "Bearer access-1" {
+		t.Fatalf("Request not authenticated with the token: %s", user.Name)
+	}
+
+	// A rejected token is refreshed and the request is retried once
+	tokenSource = client.NewRefreshingTokenSource(
+		&oauth2.Token{AccessToken: "revoked", RefreshToken: "refresh-1", Expiry: time.Now().Add(time.Hour)})
+	user, err = client.WithTokenSource(tokenSource).GetAccount()
+	if err != nil {
+		t.Fatalf("Failed to get account: %v", err)
+	}
+	if user.Name != "Bearer access-2" {
+		t.Fatalf("Request not retried with the refreshed token: %s", user.Name)
+	}
+}
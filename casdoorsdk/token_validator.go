@@ -0,0 +1,354 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// ErrTokenInactive is returned by TokenValidator.Validate() when the introspection endpoint
+// reports the token as inactive: it is unknown, expired or revoked.
+var ErrTokenInactive = errors.New("token is not active")
+
+// IntrospectionPolicy tells a TokenValidator when to call the introspection endpoint.
+type IntrospectionPolicy int
+
+const (
+	// IntrospectOpaqueTokens verifies the JWTs locally and introspects the other tokens. It is the default.
+	IntrospectOpaqueTokens IntrospectionPolicy = iota
+	// IntrospectAlways also introspects the JWTs after verifying them locally, so that the
+	// revoked tokens are rejected before they expire.
+	IntrospectAlways
+	// IntrospectNever only accepts the JWTs verified locally.
+	IntrospectNever
+)
+
+const (
+	defaultIntrospectionCacheTTL         = time.Minute
+	defaultIntrospectionNegativeCacheTTL = 10 * time.Second
+	defaultIntrospectionCacheSize        = 10000
+)
+
+// Principal is the authenticated caller of a resource server, whether its token was verified
+// locally or via introspection.
+type Principal struct {
+	Subject   string
+	Username  string
+	ClientId  string
+	Scopes    []string
+	Audience  []string
+	ExpiresAt time.Time
+	// Claims are the claims of the token when it is a JWT verified locally, nil otherwise.
+	Claims *Claims
+	// Introspected reports whether the token was checked by the introspection endpoint.
+	Introspected bool
+}
+
+// HasScope reports whether the token was granted the scope.
+func (p *Principal) HasScope(scope string) bool {
+	return containsString(p.Scopes, scope)
+}
+
+// TokenValidatorOption is a function type for configuring a TokenValidator.
+type TokenValidatorOption func(*TokenValidator)
+
+// WithValidationOptions sets the claim checks applied to all the tokens, whether they are
+// verified locally or via introspection, see ParseJwtTokenWithOptions().
+func WithValidationOptions(opts ...ValidationOption) TokenValidatorOption {
+	return func(v *TokenValidator) {
+		v.validationOpts = append(v.validationOpts, opts...)
+	}
+}
+
+// WithIntrospectionPolicy sets when the introspection endpoint is called, the default is IntrospectOpaqueTokens.
+func WithIntrospectionPolicy(policy IntrospectionPolicy) TokenValidatorOption {
+	return func(v *TokenValidator) {
+		v.policy = policy
+	}
+}
+
+// WithIntrospectionCacheTTL sets how long the introspection results are cached: ttl for the
+// active tokens, bounded by their expiry, and negativeTTL for the inactive ones. The defaults
+// are one minute and 10 seconds, a zero duration disables the caching of such results.
+func WithIntrospectionCacheTTL(ttl time.Duration, negativeTTL time.Duration) TokenValidatorOption {
+	return func(v *TokenValidator) {
+		v.ttl = ttl
+		v.negativeTTL = negativeTTL
+	}
+}
+
+// WithIntrospectionCacheSize sets the maximum number of cached introspection results, the default is 10000.
+func WithIntrospectionCacheSize(size int) TokenValidatorOption {
+	return func(v *TokenValidator) {
+		v.cacheSize = size
+	}
+}
+
+// TokenValidator validates the access tokens received by a resource server, whatever the
+// Application.TokenFormat of the application that issued them: the JWTs are verified locally
+// with ParseJwtTokenWithOptions(), and the opaque tokens are checked with the introspection
+// endpoint, whose results are cached. It is safe for concurrent use.
+type TokenValidator struct {
+	client         *Client
+	validationOpts []ValidationOption
+	policy         IntrospectionPolicy
+	ttl            time.Duration
+	negativeTTL    time.Duration
+	cacheSize      int
+
+	mu    sync.Mutex
+	cache map[string]*introspectionCacheEntry
+	calls map[string]*introspectionCall
+}
+
+type introspectionCacheEntry struct {
+	result *IntrospectTokenResult
+	expiry time.Time
+}
+
+// introspectionCall is an introspection request in flight, shared by the concurrent
+// validations of the same token.
+type introspectionCall struct {
+	done   chan struct{}
+	result *IntrospectTokenResult
+	err    error
+}
+
+// NewTokenValidator returns a TokenValidator using the client's certificate and credentials:
+//
+//	validator := client.NewTokenValidator(
+//		casdoorsdk.WithValidationOptions(casdoorsdk.WithRequiredScopes("read")),
+//		casdoorsdk.WithIntrospectionPolicy(casdoorsdk.IntrospectAlways))
+//	principal, err := validator.Validate(r.Context(), token)
+func (c *Client) NewTokenValidator(opts ...TokenValidatorOption) *TokenValidator {
+	v := &TokenValidator{
+		client:      c,
+		ttl:         defaultIntrospectionCacheTTL,
+		negativeTTL: defaultIntrospectionNegativeCacheTTL,
+		cacheSize:   defaultIntrospectionCacheSize,
+		cache:       map[string]*introspectionCacheEntry{},
+		calls:       map[string]*introspectionCall{},
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	return v
+}
+
+// Validate validates the access token and returns its principal. A rejected claim is reported
+// as a *ValidationError, and an inactive token as ErrTokenInactive.
+func (v *TokenValidator) Validate(ctx context.Context, token string) (*Principal, error) {
+	if token == "" {
+		return nil, errors.New("Validate() error: the token should not be empty")
+	}
+
+	var principal *Principal
+	_, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+	if err == nil {
+		claims, err := v.client.ParseJwtTokenWithOptions(token, v.validationOpts...)
+		if err != nil {
+			return nil, err
+		}
+
+		principal = getClaimsPrincipal(claims)
+		if v.policy != IntrospectAlways {
+			return principal, nil
+		}
+	} else if v.policy == IntrospectNever {
+		return nil, fmt.Errorf("the token is not a JWT: %w", err)
+	}
+
+	result, err := v.introspect(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.Active {
+		return nil, &ValidationError{Err: ErrTokenInactive}
+	}
+
+	claims := getIntrospectionClaims(result)
+	err = v.client.getValidationOptions(v.validationOpts...).validate(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if principal == nil {
+		principal = getClaimsPrincipal(claims)
+		principal.Claims = nil
+		principal.Username = result.Username
+		principal.ClientId = result.ClientId
+	}
+	principal.Introspected = true
+	return principal, nil
+}
+
+// introspect calls the introspection endpoint, or returns the cached result. The concurrent
+// calls for the same token share a single request.
+func (v *TokenValidator) introspect(ctx context.Context, token string) (*IntrospectTokenResult, error) {
+	key := getTokenKey(token)
+
+	v.mu.Lock()
+	entry, ok := v.cache[key]
+	if ok && time.Now().Before(entry.expiry) {
+		v.mu.Unlock()
+		return entry.result, nil
+	}
+	call, ok := v.calls[key]
+	if !ok {
+		call = &introspectionCall{done: make(chan struct{})}
+		v.calls[key] = call
+	}
+	v.mu.Unlock()
+
+	if !ok {
+		call.result, call.err = v.doIntrospect(ctx, key, token)
+
+		v.mu.Lock()
+		delete(v.calls, key)
+		v.mu.Unlock()
+		close(call.done)
+	}
+
+	select {
+	case <-call.done:
+		return call.result, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// doIntrospect calls the introspection endpoint and caches the result.
+func (v *TokenValidator) doIntrospect(ctx context.Context, key string, token string) (*IntrospectTokenResult, error) {
+	respBytes, err := v.client.postOAuthForm(ctx, "login/oauth/introspect", url.Values{
+		"token":           {token},
+		"token_type_hint": {TokenTypeHintAccessToken},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result IntrospectTokenResult
+	err = json.Unmarshal(respBytes, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiry := now.Add(v.negativeTTL)
+	if result.Active {
+		expiry = now.Add(v.ttl)
+		if result.Exp != 0 && time.Unix(int64(result.Exp), 0).Before(expiry) {
+			expiry = time.Unix(int64(result.Exp), 0)
+		}
+	}
+	if now.Before(expiry) {
+		v.saveResult(key, &result, expiry, now)
+	}
+
+	return &result, nil
+}
+
+func (v *TokenValidator) saveResult(key string, result *IntrospectTokenResult, expiry time.Time, now time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if len(v.cache) >= v.cacheSize {
+		for cacheKey, entry := range v.cache {
+			if !now.Before(entry.expiry) {
+				delete(v.cache, cacheKey)
+			}
+		}
+	}
+
+	// evict arbitrary entries if the cache is still full
+	for cacheKey := range v.cache {
+		if len(v.cache) < v.cacheSize {
+			break
+		}
+		delete(v.cache, cacheKey)
+	}
+
+	if v.cacheSize > 0 {
+		v.cache[key] = &introspectionCacheEntry{result: result, expiry: expiry}
+	}
+}
+
+func getClaimsPrincipal(claims *Claims) *Principal {
+	principal := &Principal{
+		Subject:  claims.Subject,
+		Username: claims.Name,
+		ClientId: claims.Azp,
+		Scopes:   strings.Fields(claims.Scope),
+		Audience: claims.Audience,
+		Claims:   claims,
+	}
+	if claims.ExpiresAt != nil {
+		principal.ExpiresAt = claims.ExpiresAt.Time
+	}
+
+	return principal
+}
+
+// getIntrospectionClaims converts an introspection result into claims, so that they are
+// validated like the claims of a JWT. The "token_type" of a refresh token is mapped to the
+// TokenType of the JWTs, so that WithRejectRefreshTokens() also applies to it.
+func getIntrospectionClaims(result *IntrospectTokenResult) *Claims {
+	claims := &Claims{
+		TokenType: getIntrospectionTokenType(result.TokenType),
+		Scope:     result.Scope,
+		Azp:       result.ClientId,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:   result.Iss,
+			Subject:  result.Sub,
+			Audience: result.Aud,
+			ID:       result.Jti,
+		},
+	}
+	if result.Exp != 0 {
+		claims.ExpiresAt = jwt.NewNumericDate(time.Unix(int64(result.Exp), 0))
+	}
+	if result.Nbf != 0 {
+		claims.NotBefore = jwt.NewNumericDate(time.Unix(int64(result.Nbf), 0))
+	}
+	if result.Iat != 0 {
+		claims.IssuedAt = jwt.NewNumericDate(time.Unix(int64(result.Iat), 0))
+	}
+
+	return claims
+}
+
+// getIntrospectionTokenType returns the TokenType claim of a token from its introspected
+// "token_type", which is either a token type hint or the type of the JWT claim.
+func getIntrospectionTokenType(tokenType string) string {
+	switch strings.ToLower(tokenType) {
+	case TokenTypeHintRefreshToken, "refresh-token":
+		return "refresh-token"
+	case TokenTypeHintAccessToken, "access-token":
+		return "access-token"
+	default:
+		return ""
+	}
+}
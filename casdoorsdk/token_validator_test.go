@@ -0,0 +1,175 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenValidator(t *testing.T) {
+	signer := newTestSigner(t)
+	var serverUrl string
+	var introspectCount int32
+	var revoked int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/login/oauth/introspect" {
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(&introspectCount, 1)
+
+		clientId, clientSecret, ok := r.BasicAuth()
+		if !ok || clientId != "client-id" || clientSecret != "client-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+
+		result := IntrospectTokenResult{}
+		if r.PostFormValue("token") != "unknown-token" && atomic.LoadInt32(&revoked) == 0 {
+			result = IntrospectTokenResult{
+				Active:   true,
+				ClientId: "client-id",
+				Username: "alice",
+				Exp:      uint(time.Now().Add(time.Hour).Unix()),
+				Sub:      "alice-id",
+				Aud:      []string{"client-id"},
+				Iss:      serverUrl,
+				Scope:    "openid read",
+			}
+			if r.PostFormValue("token") == "opaque-refresh-token" {
+				result.TokenType = TokenTypeHintRefreshToken
+			}
+			if r.PostFormValue("token") == "slow-token" {
+				time.Sleep(100 * time.Millisecond)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(result)
+	}))
+	defer server.Close()
+	serverUrl = server.URL
+
+	client := NewClient(server.URL, "client-id", "client-secret", signer.Certificate, "built-in", "app-built-in")
+	jwtToken := signer.sign(t, newTestClaims(server.URL, "client-id"))
+	ctx := context.Background()
+
+	t.Run("local JWT", func(t *testing.T) {
+		atomic.StoreInt32(&introspectCount, 0)
+		principal, err := client.NewTokenValidator().Validate(ctx, jwtToken)
+		if err != nil {
+			t.Fatalf("Failed to validate token: %v", err)
+		}
+		if principal.Subject != "admin-id" || principal.Claims == nil || principal.Introspected {
+			t.Errorf("Unexpected principal: %+v", principal)
+		}
+		if introspectCount != 0 {
+			t.Errorf("Expected no introspection, got %d", introspectCount)
+		}
+	})
+
+	t.Run("opaque token", func(t *testing.T) {
+		atomic.StoreInt32(&introspectCount, 0)
+		validator := client.NewTokenValidator()
+		for i := 0; i < 3; i++ {
+			principal, err := validator.Validate(ctx, "opaque-token")
+			if err != nil {
+				t.Fatalf("Failed to validate token: %v", err)
+			}
+			if principal.Subject != "alice-id" || principal.Username != "alice" || !principal.HasScope("read") || !principal.Introspected {
+				t.Errorf("Unexpected principal: %+v", principal)
+			}
+		}
+
+		for i := 0; i < 3; i++ {
+			_, err := validator.Validate(ctx, "unknown-token")
+			if !errors.Is(err, ErrTokenInactive) {
+				t.Errorf("Expected ErrTokenInactive, got %v", err)
+			}
+		}
+
+		if introspectCount != 2 {
+			t.Errorf("Expected the positive and negative results to be cached, got %d introspections", introspectCount)
+		}
+	})
+
+	t.Run("forced introspection", func(t *testing.T) {
+		validator := client.NewTokenValidator(
+			WithIntrospectionPolicy(IntrospectAlways),
+			WithIntrospectionCacheTTL(0, 0))
+		principal, err := validator.Validate(ctx, jwtToken)
+		if err != nil {
+			t.Fatalf("Failed to validate token: %v", err)
+		}
+		if principal.Claims == nil || !principal.Introspected {
+			t.Errorf("Unexpected principal: %+v", principal)
+		}
+
+		atomic.StoreInt32(&revoked, 1)
+		defer atomic.StoreInt32(&revoked, 0)
+		_, err = validator.Validate(ctx, jwtToken)
+		if !errors.Is(err, ErrTokenInactive) {
+			t.Errorf("Expected ErrTokenInactive for a revoked token, got %v", err)
+		}
+	})
+
+	t.Run("validation options", func(t *testing.T) {
+		validator := client.NewTokenValidator(WithValidationOptions(WithRequiredScopes("write")))
+		_, err := validator.Validate(ctx, "opaque-token")
+		if !errors.Is(err, ErrMissingScope) {
+			t.Errorf("Expected ErrMissingScope, got %v", err)
+		}
+
+		validator = client.NewTokenValidator(WithValidationOptions(WithRejectRefreshTokens()))
+		_, err = validator.Validate(ctx, "opaque-refresh-token")
+		if !errors.Is(err, ErrRefreshTokenNotAllowed) {
+			t.Errorf("Expected ErrRefreshTokenNotAllowed for an introspected refresh token, got %v", err)
+		}
+
+		_, err = client.NewTokenValidator(WithIntrospectionPolicy(IntrospectNever)).Validate(ctx, "opaque-token")
+		if err == nil {
+			t.Errorf("Expected an error for an opaque token without introspection")
+		}
+	})
+
+	t.Run("concurrent introspections", func(t *testing.T) {
+		atomic.StoreInt32(&introspectCount, 0)
+		validator := client.NewTokenValidator()
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := validator.Validate(ctx, "slow-token")
+				if err != nil {
+					t.Errorf("Failed to validate token: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if introspectCount != 1 {
+			t.Errorf("Expected the concurrent validations to share one introspection, got %d", introspectCount)
+		}
+	})
+}
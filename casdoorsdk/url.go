@@ -15,9 +15,12 @@
 package casdoorsdk
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
+
+	"golang.org/x/oauth2"
 )
 
 func (c *Client) GetSignupUrl(enablePassword bool, redirectUri string) string {
@@ -38,6 +41,159 @@ func (c *Client) GetSigninUrl(redirectUri string) string {
 		c.Endpoint, c.ClientId, url.QueryEscape(redirectUri), scope, state)
 }
 
+// GetSigninUrlWithOptions is like GetSigninUrl() but accepts the OAuthOption functions:
+// WithScopes(), WithState(), WithNonce(), WithPKCE(), and WithResources() to request a token
+// restricted to the given APIs.
+func (c *Client) GetSigninUrlWithOptions(redirectUri string, opts ...OAuthOption) (string, error) {
+	options := getOAuthOptions(opts...)
+	err := checkResourceIndicators(options.resources)
+	if err != nil {
+		return "", err
+	}
+
+	scope := "read"
+	if len(options.scopes) > 0 {
+		scope = strings.Join(options.scopes, " ")
+	}
+	state := c.ApplicationName
+	if options.state != "" {
+		state = options.state
+	}
+
+	query := url.Values{
+		"client_id":     {c.ClientId},
+		"response_type": {"code"},
+		"redirect_uri":  {redirectUri},
+		"scope":         {scope},
+		"state":         {state},
+	}
+	if options.nonce != "" {
+		query.Set("nonce", options.nonce)
+	}
+	if options.codeVerifier != "" {
+		query.Set("code_challenge", oauth2.S256ChallengeFromVerifier(options.codeVerifier))
+		query.Set("code_challenge_method", "S256")
+	}
+	for _, resource := range options.resources {
+		query.Add("resource", resource)
+	}
+
+	return fmt.Sprintf("%s/login/oauth/authorize?%s", c.Endpoint, query.Encode()), nil
+}
+
+// GetEndSessionUrl returns the URL of Casdoor's OpenID Connect RP-Initiated Logout endpoint
+// ("/api/logout"), to which the browser is redirected to end the user's Casdoor session, see
+// https://openid.net/specs/openid-connect-rpinitiated-1_0.html. The idTokenHint is the user's
+// ID token. Casdoor redirects the browser to the postLogoutRedirectUri afterwards with the
+// state, which should be a random value checked by that page, see the webauth package.
+//
+// The postLogoutRedirectUri is checked against the "Redirect URLs" of the client's application,
+// which is fetched from Casdoor on every call, use GetEndSessionUrlWithRedirectUris() to check
+// it against the redirect URLs fetched before.
+func (c *Client) GetEndSessionUrl(idTokenHint string, postLogoutRedirectUri string, state string) (string, error) {
+	var redirectUris []string
+	if idTokenHint != "" && postLogoutRedirectUri != "" {
+		application, err := c.GetApplication(c.ApplicationName)
+		if err != nil {
+			return "", err
+		}
+		if application == nil {
+			return "", fmt.Errorf("GetEndSessionUrl() error: the application %q doesn't exist", c.ApplicationName)
+		}
+
+		redirectUris = application.RedirectUris
+	}
+
+	return c.GetEndSessionUrlWithRedirectUris(idTokenHint, postLogoutRedirectUri, state, redirectUris)
+}
+
+// GetEndSessionUrlWithRedirectUris is like GetEndSessionUrl() but checks the
+// postLogoutRedirectUri against the given redirectUris, the RedirectUris of the client's
+// application, instead of fetching them. The postLogoutRedirectUri should be one of them, or
+// have the same origin and a path under the path of one of them.
+func (c *Client) GetEndSessionUrlWithRedirectUris(idTokenHint string, postLogoutRedirectUri string, state string, redirectUris []string) (string, error) {
+	if idTokenHint == "" {
+		return "", errors.New("GetEndSessionUrl() error: the idTokenHint should not be empty")
+	}
+
+	query := url.Values{
+		"id_token_hint": {idTokenHint},
+	}
+	if postLogoutRedirectUri != "" {
+		if !isRedirectUriValid(redirectUris, postLogoutRedirectUri) {
+			return "", fmt.Errorf("GetEndSessionUrl() error: the postLogoutRedirectUri %q is not one of the application's redirect URLs", postLogoutRedirectUri)
+		}
+
+		query.Set("post_logout_redirect_uri", postLogoutRedirectUri)
+		if state != "" {
+			query.Set("state", state)
+		}
+	}
+
+	return fmt.Sprintf("%s/api/logout?%s", c.Endpoint, query.Encode()), nil
+}
+
+// isRedirectUriValid checks that the redirectUri is one of the redirectUris, or that it has
+// the same scheme and host as one of them and a path under its path. It is stricter than
+// Casdoor, which matches the redirectUris as regular expressions anywhere in the redirectUri,
+// so that a URL of another site that contains a redirect URL is not accepted.
+func isRedirectUriValid(redirectUris []string, redirectUri string) bool {
+	u, err := url.Parse(redirectUri)
+	if err != nil || !u.IsAbs() || u.Host == "" || u.User != nil || u.Fragment != "" {
+		return false
+	}
+
+	for _, targetUri := range redirectUris {
+		if targetUri == "" {
+			continue
+		}
+
+		if targetUri == redirectUri {
+			return true
+		}
+
+		target, err := url.Parse(targetUri)
+		if err != nil || !target.IsAbs() || target.Host == "" {
+			continue
+		}
+
+		if !strings.EqualFold(u.Scheme, target.Scheme) || !strings.EqualFold(u.Host, target.Host) {
+			continue
+		}
+
+		targetPath := strings.TrimSuffix(target.EscapedPath(), "/")
+		path := u.EscapedPath()
+		if path == targetPath || strings.HasPrefix(path, targetPath+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetFrontChannelLogoutUrl returns the URL of an OpenID Connect Front-Channel Logout iframe,
+// see https://openid.net/specs/openid-connect-frontchannel-1_0.html: the frontChannelLogoutUri
+// of an application, with the "iss" and "sid" parameters identifying the session to end.
+// A logout page loading it in a hidden iframe signs the user out of that application too.
+func (c *Client) GetFrontChannelLogoutUrl(frontChannelLogoutUri string, sid string) (string, error) {
+	u, err := url.Parse(frontChannelLogoutUri)
+	if err != nil {
+		return "", err
+	}
+	if !u.IsAbs() || u.Fragment != "" {
+		return "", fmt.Errorf("GetFrontChannelLogoutUrl() error: the frontChannelLogoutUri %q should be an absolute URI without fragment", frontChannelLogoutUri)
+	}
+
+	query := u.Query()
+	query.Set("iss", c.Endpoint)
+	if sid != "" {
+		query.Set("sid", sid)
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
 func (c *Client) GetUserProfileUrl(userName string, accessToken string) string {
 	param := ""
 	if accessToken != "" {
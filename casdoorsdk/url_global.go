@@ -22,6 +22,18 @@ func GetSigninUrl(redirectUri string) string {
 	return globalClient.GetSigninUrl(redirectUri)
 }
 
+func GetSigninUrlWithOptions(redirectUri string, opts ...OAuthOption) (string, error) {
+	return globalClient.GetSigninUrlWithOptions(redirectUri, opts...)
+}
+
+func GetEndSessionUrl(idTokenHint string, postLogoutRedirectUri string, state string) (string, error) {
+	return globalClient.GetEndSessionUrl(idTokenHint, postLogoutRedirectUri, state)
+}
+
+func GetFrontChannelLogoutUrl(frontChannelLogoutUri string, sid string) (string, error) {
+	return globalClient.GetFrontChannelLogoutUrl(frontChannelLogoutUri, sid)
+}
+
 func GetUserProfileUrl(userName string, accessToken string) string {
 	return globalClient.GetUserProfileUrl(userName, accessToken)
 }
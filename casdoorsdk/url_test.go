@@ -0,0 +1,107 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestGetEndSessionUrl(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/get-application" || r.URL.Query().Get("id") != "admin/app-built-in" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Response{
+			Status: "ok",
+			Data:   Application{Owner: "admin", Name: "app-built-in", RedirectUris: []string{"https://app.example.com/"}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "client-id", "client-secret", "", "built-in", "app-built-in")
+	endSessionUrl, err := client.GetEndSessionUrl("id-token", "https://app.example.com/logout/callback", "state")
+	if err != nil {
+		t.Fatalf("Failed to get the end-session URL: %v", err)
+	}
+
+	u, err := url.Parse(endSessionUrl)
+	if err != nil {
+		t.Fatalf("Failed to parse the end-session URL: %v", err)
+	}
+	query := u.Query()
+	if u.Path != "/api/logout" || query.Get("id_token_hint") != "id-token" ||
+		query.Get("post_logout_redirect_uri") != "https://app.example.com/logout/callback" || query.Get("state") != "state" {
+		t.Errorf("Unexpected end-session URL: %s", endSessionUrl)
+	}
+
+	_, err = client.GetEndSessionUrl("id-token", "https://evil.com/", "state")
+	if err == nil {
+		t.Errorf("Expected an error for a postLogoutRedirectUri that isn't a redirect URL")
+	}
+
+	_, err = client.GetEndSessionUrl("", "", "")
+	if err == nil {
+		t.Errorf("Expected an error for an empty idTokenHint")
+	}
+}
+
+func TestIsRedirectUriValid(t *testing.T) {
+	redirectUris := []string{"https://app.example.com/auth/", "http://localhost:8080", "https://.*\\.example\\.org"}
+	tests := map[string]bool{
+		"https://app.example.com/auth/":                        true,
+		"https://app.example.com/auth":                         true,
+		"https://app.example.com/auth/logout?next=1":           true,
+		"https://APP.example.com/auth/logout":                  true,
+		"http://localhost:8080/logout":                         true,
+		"https://app.example.com/authz":                        false,
+		"https://app.example.com/":                             false,
+		"http://app.example.com/auth/":                         false,
+		"https://app.example.com.evil.com/auth/":               false,
+		"https://evil.com/?https://app.example.com/auth/":      false,
+		"https://app.example.com@evil.com/auth/":               false,
+		"https://evil.com/https://app.example.com/auth/":       false,
+		"https://www.example.org/":                             false,
+		"/auth/logout":                                         false,
+		"https://user@app.example.com/auth/logout":             false,
+		"https://app.example.com/auth/logout#https://evil.com": false,
+	}
+
+	for redirectUri, expected := range tests {
+		if isRedirectUriValid(redirectUris, redirectUri) != expected {
+			t.Errorf("Expected %v for %s", expected, redirectUri)
+		}
+	}
+}
+
+func TestGetFrontChannelLogoutUrl(t *testing.T) {
+	client := NewClient("https://door.example.com", "client-id", "client-secret", "", "built-in", "app-built-in")
+	logoutUrl, err := client.GetFrontChannelLogoutUrl("https://app.example.com/frontchannel-logout?app=1", "session-id")
+	if err != nil {
+		t.Fatalf("Failed to get the front-channel logout URL: %v", err)
+	}
+	if logoutUrl != "https://app.example.com/frontchannel-logout?app=1&iss=https%3A%2F%2Fdoor.example.com&sid=session-id" {
+		t.Errorf("Unexpected front-channel logout URL: %s", logoutUrl)
+	}
+
+	_, err = client.GetFrontChannelLogoutUrl("/frontchannel-logout", "session-id")
+	if err == nil {
+		t.Errorf("Expected an error for a relative URI")
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// ErrUserinfoSubjectMismatch is returned by GetUserinfo() when the "sub" of the userinfo
+// response differs from the expected one, i.e., the response is not about the ID token's user.
+var ErrUserinfoSubjectMismatch = errors.New("the userinfo subject doesn't match the ID token")
+
+// UserinfoOption is a function type for configuring GetUserinfo().
+type UserinfoOption func(*userinfoOptions)
+
+// userinfoOptions holds configuration options for GetUserinfo().
+type userinfoOptions struct {
+	expectedSubject string
+	cache           *UserinfoCache
+}
+
+// WithExpectedSubject requires the "sub" of the userinfo response to be the given subject,
+// which should be the "sub" of the user's ID token, as required by OpenID Connect Core 1.0,
+// section 5.3.4.
+func WithExpectedSubject(subject string) UserinfoOption {
+	return func(opts *userinfoOptions) {
+		opts.expectedSubject = subject
+	}
+}
+
+// WithUserinfoCache caches the userinfo responses in the cache, see UserinfoCache.
+func WithUserinfoCache(cache *UserinfoCache) UserinfoOption {
+	return func(opts *userinfoOptions) {
+		opts.cache = cache
+	}
+}
+
+// UserinfoCache caches the userinfo of the access tokens until they expire, so that a
+// resource server calling GetUserinfo() on every request only calls Casdoor once per token.
+// The tokens are stored as SHA-256 hashes. It is safe for concurrent use.
+type UserinfoCache struct {
+	maxAge time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*userinfoCacheEntry
+}
+
+type userinfoCacheEntry struct {
+	userinfo *Userinfo
+	expiry   time.Time
+}
+
+// NewUserinfoCache returns a UserinfoCache that keeps a userinfo until its access token
+// expires, or for maxAge if it comes first. A zero maxAge means no limit other than the
+// token's expiry, the tokens without an "exp" claim are then not cached.
+func NewUserinfoCache(maxAge time.Duration) *UserinfoCache {
+	return &UserinfoCache{
+		maxAge:  maxAge,
+		entries: map[string]*userinfoCacheEntry{},
+	}
+}
+
+func (cache *UserinfoCache) get(accessToken string) *Userinfo {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	entry, ok := cache.entries[getTokenKey(accessToken)]
+	if !ok || !time.Now().Before(entry.expiry) {
+		return nil
+	}
+
+	return entry.userinfo
+}
+
+func (cache *UserinfoCache) put(accessToken string, userinfo *Userinfo) {
+	now := time.Now()
+	var expiry time.Time
+	claims := &jwt.RegisteredClaims{}
+	_, _, err := jwt.NewParser().ParseUnverified(accessToken, claims)
+	if err == nil && claims.ExpiresAt != nil {
+		expiry = claims.ExpiresAt.Time
+	}
+	if cache.maxAge > 0 && (expiry.IsZero() || now.Add(cache.maxAge).Before(expiry)) {
+		expiry = now.Add(cache.maxAge)
+	}
+	if !now.Before(expiry) {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	for key, entry := range cache.entries {
+		if !now.Before(entry.expiry) {
+			delete(cache.entries, key)
+		}
+	}
+
+	cache.entries[getTokenKey(accessToken)] = &userinfoCacheEntry{userinfo: userinfo, expiry: expiry}
+}
+
+// getTokenKey returns the key of a token in a cache, so that the tokens themselves are not kept in memory.
+func getTokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetUserinfo gets the claims about the user who owns the access token from Casdoor's OpenID
+// Connect userinfo endpoint ("/api/userinfo"). Both the JSON and the signed JWT
+// ("application/jwt") responses are supported, the signature of the latter is checked with
+// the client's Certificate. The returned claims depend on the scopes of the token:
+//
+//	idToken, err := client.VerifyIDToken(ctx, rawIDToken, token.AccessToken, "", nonce)
+//	userinfo, err := client.GetUserinfo(ctx, token.AccessToken,
+//		casdoorsdk.WithExpectedSubject(idToken.Subject))
+//
+// The access token is sent with the client's DPoP signer, if any.
+func (c *Client) GetUserinfo(ctx context.Context, accessToken string, opts ...UserinfoOption) (*Userinfo, error) {
+	if accessToken == "" {
+		return nil, errors.New("GetUserinfo() error: the accessToken should not be empty")
+	}
+
+	options := &userinfoOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var userinfo *Userinfo
+	if options.cache != nil {
+		userinfo = options.cache.get(accessToken)
+	}
+
+	if userinfo == nil {
+		var err error
+		userinfo, err = c.requestUserinfo(ctx, accessToken)
+		if err != nil {
+			return nil, err
+		}
+
+		if options.cache != nil {
+			options.cache.put(accessToken, userinfo)
+		}
+	}
+
+	if userinfo.Sub == "" {
+		return nil, errors.New("the userinfo response has no \"sub\" claim")
+	}
+
+	if options.expectedSubject != "" && userinfo.Sub != options.expectedSubject {
+		return nil, newValidationError(ErrUserinfoSubjectMismatch, "expected %q, got %q", options.expectedSubject, userinfo.Sub)
+	}
+
+	return userinfo, nil
+}
+
+func (c *Client) requestUserinfo(ctx context.Context, accessToken string) (*Userinfo, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.GetUrl("userinfo", nil), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenClient := c.WithAccessToken(accessToken)
+	err = tokenClient.setAuthHeader(req)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json, application/jwt")
+
+	// Add custom headers
+	for key, value := range c.CustomHeaders {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err = tokenClient.retryUnauthorized(req, resp)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			return
+		}
+	}(resp.Body)
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "application/jwt" && resp.StatusCode == http.StatusOK {
+		return c.parseUserinfoJwt(strings.TrimSpace(string(respBytes)))
+	}
+
+	err = parseOAuthError(resp.StatusCode, respBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var userinfo Userinfo
+	err = json.Unmarshal(respBytes, &userinfo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userinfo, nil
+}
+
+// parseUserinfoJwt checks the signature, issuer and audience of a signed userinfo response,
+// as required by OpenID Connect Core 1.0, section 5.3.2.
+func (c *Client) parseUserinfoJwt(token string) (*Userinfo, error) {
+	mapClaims := jwt.MapClaims{}
+	_, err := c.parseJwtTokenSignature(token, mapClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	claimBytes, err := json.Marshal(mapClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	var registeredClaims jwt.RegisteredClaims
+	err = json.Unmarshal(claimBytes, &registeredClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	options := c.getValidationOptions()
+	if !IsSameIssuer(registeredClaims.Issuer, options.issuer) {
+		return nil, newValidationError(ErrInvalidIssuer, "expected %q, got %q", options.issuer, registeredClaims.Issuer)
+	}
+
+	err = options.validateAudience(registeredClaims.Audience, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// the "aud" claim can be an array, while Userinfo.Aud is a string
+	delete(mapClaims, "aud")
+	claimBytes, err = json.Marshal(mapClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	userinfo := &Userinfo{}
+	err = json.Unmarshal(claimBytes, userinfo)
+	if err != nil {
+		return nil, err
+	}
+	userinfo.Aud = c.ClientId
+
+	return userinfo, nil
+}
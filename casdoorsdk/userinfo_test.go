@@ -0,0 +1,128 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestGetUserinfo(t *testing.T) {
+	signer := newTestSigner(t)
+	var serverUrl string
+	var requestCount int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/userinfo" {
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(&requestCount, 1)
+
+		switch r.Header.Get("Authorization") {
+		case "Bearer json-token":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(Userinfo{Sub: "admin-id", Iss: serverUrl, Aud: "client-id", Name: "admin"})
+		case "Bearer jwt-token":
+			w.Header().Set("Content-Type", "application/jwt")
+			_, _ = w.Write([]byte(signer.sign(t, jwt.MapClaims{
+				"sub":                "admin-id",
+				"iss":                serverUrl,
+				"aud":                []string{"client-id"},
+				"preferred_username": "admin",
+				"groups":             []string{"built-in/admins"},
+			})))
+		case "Bearer other-audience-token":
+			w.Header().Set("Content-Type", "application/jwt")
+			_, _ = w.Write([]byte(signer.sign(t, jwt.MapClaims{"sub": "admin-id", "iss": serverUrl, "aud": "other-client"})))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(Response{Status: "error", Msg: "Access token doesn't exist"})
+		}
+	}))
+	defer server.Close()
+	serverUrl = server.URL
+
+	client := NewClient(server.URL, "client-id", "client-secret", signer.Certificate, "built-in", "app-built-in")
+	ctx := context.Background()
+
+	userinfo, err := client.GetUserinfo(ctx, "json-token", WithExpectedSubject("admin-id"))
+	if err != nil {
+		t.Fatalf("Failed to get userinfo: %v", err)
+	}
+	if userinfo.Name != "admin" {
+		t.Errorf("Expected name admin, got %s", userinfo.Name)
+	}
+
+	userinfo, err = client.GetUserinfo(ctx, "jwt-token", WithExpectedSubject("admin-id"))
+	if err != nil {
+		t.Fatalf("Failed to get signed userinfo: %v", err)
+	}
+	if userinfo.Name != "admin" || len(userinfo.Groups) != 1 || userinfo.Aud != "client-id" {
+		t.Errorf("Unexpected signed userinfo: %+v", userinfo)
+	}
+
+	_, err = client.GetUserinfo(ctx, "json-token", WithExpectedSubject("another-id"))
+	if !errors.Is(err, ErrUserinfoSubjectMismatch) {
+		t.Errorf("Expected ErrUserinfoSubjectMismatch, got %v", err)
+	}
+
+	_, err = client.GetUserinfo(ctx, "other-audience-token")
+	if !errors.Is(err, ErrInvalidAudience) {
+		t.Errorf("Expected ErrInvalidAudience, got %v", err)
+	}
+
+	_, err = client.GetUserinfo(ctx, "unknown-token")
+	if err == nil {
+		t.Errorf("Expected an error for an unknown token")
+	}
+
+	// The cache keeps the userinfo until the token expires
+	cache := NewUserinfoCache(0)
+	cachedToken := signer.sign(t, jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))})
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requestCount, 1)
+		_ = json.NewEncoder(w).Encode(Userinfo{Sub: "admin-id"})
+	})
+	atomic.StoreInt32(&requestCount, 0)
+	for i := 0; i < 3; i++ {
+		_, err = client.GetUserinfo(ctx, cachedToken, WithUserinfoCache(cache))
+		if err != nil {
+			t.Fatalf("Failed to get userinfo: %v", err)
+		}
+	}
+	if requestCount != 1 {
+		t.Errorf("Expected 1 userinfo request, got %d", requestCount)
+	}
+
+	// A token without "exp" is not cached without a maxAge
+	for i := 0; i < 2; i++ {
+		_, err = client.GetUserinfo(ctx, "opaque-token", WithUserinfoCache(cache))
+		if err != nil {
+			t.Fatalf("Failed to get userinfo: %v", err)
+		}
+	}
+	if requestCount != 3 {
+		t.Errorf("Expected 3 userinfo requests, got %d", requestCount)
+	}
+}
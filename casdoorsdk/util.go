@@ -118,15 +118,30 @@ func GetCurrentTime() string {
 }
 
 // setAuthHeader sets the "Authorization" header of the request. The user's access token is
-// used when the client has one, so that the API is called as the user instead of as the
-// application. Otherwise the application's client ID and client secret are used.
-func (c *Client) setAuthHeader(req *http.Request) {
-	if c.AccessToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.AccessToken)
-		return
+// used when the client has one or has a token source, so that the API is called as the user
+// instead of as the application. Otherwise the application's client ID and client secret are used.
+func (c *Client) setAuthHeader(req *http.Request) error {
+	accessToken := c.AccessToken
+	if c.tokenSource != nil {
+		token, err := c.tokenSource.Token()
+		if err != nil {
+			return err
+		}
+
+		accessToken = token.AccessToken
+	}
+
+	if accessToken == "" {
+		req.SetBasicAuth(c.ClientId, c.ClientSecret)
+		return nil
 	}
 
-	req.SetBasicAuth(c.ClientId, c.ClientSecret)
+	if c.dpopSigner != nil {
+		return c.dpopSigner.setHeaders(req, accessToken)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	return nil
 }
 
 // DoGetResponse is a general function to get response from param url through HTTP Get method.
@@ -240,7 +255,10 @@ func (c *Client) DoPostBytesRaw(url string, contentType string, body io.Reader)
 		return nil, err
 	}
 
-	c.setAuthHeader(req)
+	err = c.setAuthHeader(req)
+	if err != nil {
+		return nil, err
+	}
 	req.Header.Set("Content-Type", contentType)
 
 	// Add custom headers
@@ -252,6 +270,11 @@ func (c *Client) DoPostBytesRaw(url string, contentType string, body io.Reader)
 	if err != nil {
 		return nil, err
 	}
+
+	resp, err = c.retryUnauthorized(req, resp)
+	if err != nil {
+		return nil, err
+	}
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
 		if err != nil {
@@ -278,7 +301,10 @@ func (c *Client) doGetBytesRawWithoutCheck(url string) ([]byte, error) {
 		return nil, err
 	}
 
-	c.setAuthHeader(req)
+	err = c.setAuthHeader(req)
+	if err != nil {
+		return nil, err
+	}
 
 	// Add custom headers
 	for key, value := range c.CustomHeaders {
@@ -289,6 +315,11 @@ func (c *Client) doGetBytesRawWithoutCheck(url string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	resp, err = c.retryUnauthorized(req, resp)
+	if err != nil {
+		return nil, err
+	}
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
 		if err != nil {
@@ -307,3 +338,79 @@ func (c *Client) doGetBytesRawWithoutCheck(url string) ([]byte, error) {
 
 	return respBytes, nil
 }
+
+// doSessionRequest sends a request of the flows that Casdoor keeps in a session, like the
+// WebAuthn ceremonies and the MFA sign-ins, with the cookie of the session, and returns the
+// response and the session cookie.
+func (c *Client) doSessionRequest(method string, action string, queryMap map[string]string, contentType string, body io.Reader, session string) ([]byte, string, error) {
+	req, err := http.NewRequest(method, c.GetUrl(action, queryMap), body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = c.setAuthHeader(req)
+	if err != nil {
+		return nil, "", err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if session != "" {
+		req.Header.Set("Cookie", session)
+	}
+
+	// Add custom headers
+	for key, value := range c.CustomHeaders {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	resp, err = c.retryUnauthorized(req, resp)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			return
+		}
+	}(resp.Body)
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("status code: %d, status: %s, body: %s", resp.StatusCode, resp.Status, string(respBytes))
+	}
+
+	var cookies []string
+	for _, cookie := range resp.Cookies() {
+		cookies = append(cookies, cookie.Name+"="+cookie.Value)
+	}
+	if len(cookies) != 0 {
+		session = strings.Join(cookies, "; ")
+	}
+
+	return respBytes, session, nil
+}
+
+// parseSessionResponse returns the error of a Casdoor response, if any.
+func parseSessionResponse(respBytes []byte) (*Response, error) {
+	var response Response
+	err := json.Unmarshal(respBytes, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Status == "error" {
+		return nil, errors.New(response.Msg)
+	}
+
+	return &response, nil
+}
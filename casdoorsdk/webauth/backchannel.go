@@ -0,0 +1,235 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+)
+
+// maxLogoutTokenSize bounds the body of the back-channel logout requests.
+const maxLogoutTokenSize = 64 << 10
+
+// jtiRetention is how long the "jti" of the logout tokens are remembered against replays,
+// it covers the 5 minutes a logout token is accepted by casdoorsdk.Client.ParseLogoutToken().
+const jtiRetention = 10 * time.Minute
+
+// logoutRetention is how long the back-channel logouts are remembered by a Handler whose
+// store can't revoke the sessions itself, it should exceed the lifetime of the sessions.
+const logoutRetention = 30 * 24 * time.Hour
+
+// SessionRevoker is implemented by the server-side SessionStores that can delete the sessions
+// of a user without their browser. RevokeSessions() deletes the session whose Sid is sid, or
+// all the sessions whose Subject is sub when sid is empty.
+type SessionRevoker interface {
+	RevokeSessions(ctx context.Context, sub string, sid string) error
+}
+
+// BackChannelLogoutHandler returns the handler of the OpenID Connect Back-Channel Logout
+// requests Casdoor sends when a user signs out, see
+// https://openid.net/specs/openid-connect-backchannel-1_0.html. It verifies the
+// "logout_token" with casdoorsdk.Client.ParseLogoutToken(), rejects the replayed ones by their
+// "jti", and calls onLogout with the "sub" and "sid" of the token, one of which may be empty.
+// An onLogout error is returned to Casdoor as a 500 response, so that it can retry.
+//
+// Its URL is the application's back-channel logout URL in Casdoor. A Handler provides one
+// ending its own sessions, see Handler.BackChannelLogoutHandler().
+func BackChannelLogoutHandler(client *casdoorsdk.Client, onLogout func(ctx context.Context, sub string, sid string) error, opts ...casdoorsdk.ValidationOption) http.Handler {
+	jtis := &jtiCache{entries: map[string]time.Time{}}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeLogoutError(w, http.StatusMethodNotAllowed, "invalid_request", "the logout request should be a POST")
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxLogoutTokenSize)
+		logoutToken := r.PostFormValue("logout_token")
+		if logoutToken == "" {
+			writeLogoutError(w, http.StatusBadRequest, "invalid_request", "the logout_token is missing")
+			return
+		}
+
+		claims, err := client.ParseLogoutToken(logoutToken, opts...)
+		if err != nil {
+			writeLogoutError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+
+		jti := claims.Issuer + " " + claims.ID
+		if !jtis.add(jti, time.Now().Add(jtiRetention)) {
+			writeLogoutError(w, http.StatusBadRequest, "invalid_request", "the logout_token has already been used")
+			return
+		}
+
+		err = onLogout(r.Context(), claims.Subject, claims.Sid)
+		if err != nil {
+			// let Casdoor retry the same logout token
+			jtis.remove(jti)
+			writeLogoutError(w, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func writeLogoutError(w http.ResponseWriter, statusCode int, errorCode string, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"error":             errorCode,
+		"error_description": description,
+	})
+}
+
+// BackChannelLogoutHandler returns a back-channel logout handler, see the function of the same
+// name, that ends the local sessions of the users signed out of Casdoor. The sessions are
+// deleted by the store if it implements SessionRevoker. Otherwise, as with a CookieStore, the
+// logouts are remembered in memory and the matching sessions are rejected by LoadUser(), which
+// only works when a single instance of the application serves the users.
+func (h *Handler) BackChannelLogoutHandler(opts ...casdoorsdk.ValidationOption) http.Handler {
+	return BackChannelLogoutHandler(h.client, func(ctx context.Context, sub string, sid string) error {
+		if revoker, ok := h.store.(SessionRevoker); ok {
+			return revoker.RevokeSessions(ctx, sub, sid)
+		}
+
+		h.logouts.add(sub, sid, time.Now())
+		return nil
+	}, opts...)
+}
+
+// jtiCache remembers the "jti" of the logout tokens until they expire. They all have the same
+// retention, so the order in which they are added is the order in which they expire, and the
+// expired ones are pruned from the front of the queue.
+type jtiCache struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+	queue   []jtiCacheEntry
+}
+
+type jtiCacheEntry struct {
+	jti    string
+	expiry time.Time
+}
+
+// add returns false if the jti is already in the cache.
+func (cache *jtiCache) add(jti string, expiry time.Time) bool {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	now := time.Now()
+	for len(cache.queue) > 0 && !now.Before(cache.queue[0].expiry) {
+		entry := cache.queue[0]
+		cache.queue = cache.queue[1:]
+		// the jti may have been removed and added again since
+		if cache.entries[entry.jti].Equal(entry.expiry) {
+			delete(cache.entries, entry.jti)
+		}
+	}
+
+	if _, ok := cache.entries[jti]; ok {
+		return false
+	}
+
+	cache.entries[jti] = expiry
+	cache.queue = append(cache.queue, jtiCacheEntry{jti: jti, expiry: expiry})
+	return true
+}
+
+func (cache *jtiCache) remove(jti string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	delete(cache.entries, jti)
+}
+
+// logoutRegistry remembers the back-channel logouts, by "sid" when the logout token has one
+// and by "sub" otherwise, so that the sessions created before them are rejected. They are
+// added in the order of their time, which is the order in which they expire, and the expired
+// ones are pruned from the front of the queue.
+type logoutRegistry struct {
+	mu    sync.Mutex
+	bySid map[string]time.Time
+	bySub map[string]time.Time
+	queue []logoutRegistryEntry
+}
+
+type logoutRegistryEntry struct {
+	sub        string
+	sid        string
+	logoutTime time.Time
+}
+
+func newLogoutRegistry() *logoutRegistry {
+	return &logoutRegistry{
+		bySid: map[string]time.Time{},
+		bySub: map[string]time.Time{},
+	}
+}
+
+func (registry *logoutRegistry) add(sub string, sid string, logoutTime time.Time) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	for len(registry.queue) > 0 && logoutTime.Sub(registry.queue[0].logoutTime) > logoutRetention {
+		entry := registry.queue[0]
+		registry.queue = registry.queue[1:]
+		// the sid or sub may have been logged out again since
+		if entry.sid != "" {
+			if registry.bySid[entry.sid].Equal(entry.logoutTime) {
+				delete(registry.bySid, entry.sid)
+			}
+		} else if registry.bySub[entry.sub].Equal(entry.logoutTime) {
+			delete(registry.bySub, entry.sub)
+		}
+	}
+
+	if sid != "" {
+		registry.bySid[sid] = logoutTime
+		sub = ""
+	} else {
+		registry.bySub[sub] = logoutTime
+	}
+	registry.queue = append(registry.queue, logoutRegistryEntry{sub: sub, sid: sid, logoutTime: logoutTime})
+}
+
+// isLoggedOut returns true if the session was created before a logout of its "sid" or of
+// all the sessions of its user.
+func (registry *logoutRegistry) isLoggedOut(session *Session) bool {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if session.Sid != "" {
+		if logoutTime, ok := registry.bySid[session.Sid]; ok && !session.CreatedTime.After(logoutTime) {
+			return true
+		}
+	}
+
+	if session.Subject != "" {
+		if logoutTime, ok := registry.bySub[session.Subject]; ok && !session.CreatedTime.After(logoutTime) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,235 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webauth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func (casdoor *testCasdoor) signLogoutToken(t *testing.T, jti string, sub string, sid string) string {
+	claims := jwt.MapClaims{
+		"iss":    casdoor.URL,
+		"aud":    "client-id",
+		"iat":    time.Now().Unix(),
+		"jti":    jti,
+		"events": map[string]interface{}{casdoorsdk.BackChannelLogoutEvent: map[string]interface{}{}},
+	}
+	if sub != "" {
+		claims["sub"] = sub
+	}
+	if sid != "" {
+		claims["sid"] = sid
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(casdoor.key)
+	if err != nil {
+		t.Fatalf("Failed to sign logout token: %v", err)
+	}
+	return token
+}
+
+func postLogoutToken(t *testing.T, handler http.Handler, logoutToken string) int {
+	req := httptest.NewRequest("POST", "/backchannel-logout", nil)
+	req.PostForm = url.Values{"logout_token": {logoutToken}}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	if recorder.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Expected Cache-Control: no-store, got %q", recorder.Header().Get("Cache-Control"))
+	}
+	return recorder.Code
+}
+
+func TestBackChannelLogoutHandler(t *testing.T) {
+	casdoor := newTestCasdoor(t)
+	defer casdoor.Close()
+
+	client := casdoorsdk.NewClient(casdoor.URL, "client-id", "client-secret", casdoor.certificate, "built-in", "app-built-in")
+	var sub, sid string
+	handler := BackChannelLogoutHandler(client, func(ctx context.Context, logoutSub string, logoutSid string) error {
+		sub, sid = logoutSub, logoutSid
+		return nil
+	})
+
+	logoutToken := casdoor.signLogoutToken(t, "jti-1", "alice-id", "session-id")
+	if code := postLogoutToken(t, handler, logoutToken); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if sub != "alice-id" || sid != "session-id" {
+		t.Errorf("Expected alice-id and session-id, got %q and %q", sub, sid)
+	}
+
+	// A replayed logout token is rejected
+	if code := postLogoutToken(t, handler, logoutToken); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a replayed logout token, got %d", code)
+	}
+
+	// A malformed logout token is rejected
+	if code := postLogoutToken(t, handler, "not-a-jwt"); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid logout token, got %d", code)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/backchannel-logout", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for a GET, got %d", recorder.Code)
+	}
+}
+
+func TestHandlerBackChannelLogout(t *testing.T) {
+	casdoor := newTestCasdoor(t)
+	defer casdoor.Close()
+
+	client := casdoorsdk.NewClient(casdoor.URL, "client-id", "client-secret", casdoor.certificate, "built-in", "app-built-in")
+	store, err := NewCookieStore(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("Failed to create cookie store: %v", err)
+	}
+	store.Insecure = true
+
+	mux := http.NewServeMux()
+	app := httptest.NewServer(mux)
+	defer app.Close()
+
+	auth := New(client, store, app.URL+"/callback")
+	backChannelHandler := auth.BackChannelLogoutHandler()
+	mux.Handle("/login", auth.LoginHandler())
+	mux.Handle("/callback", auth.CallbackHandler())
+	mux.Handle("/private", auth.RequireLogin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("private"))
+	})))
+
+	get := newTestBrowser(t, casdoor)
+	signIn := func() {
+		resp, _ := get(app.URL + "/login?return_to=/private")
+		code, state := casdoor.authorize(t, resp.Header.Get("Location"))
+		_, body := get(app.URL + "/callback?code=" + code + "&state=" + state)
+		if body != "private" {
+			t.Fatalf("Expected the private page, got %s", body)
+		}
+	}
+	signIn()
+
+	// The logout of another user's sessions doesn't end this one
+	if code := postLogoutToken(t, backChannelHandler, casdoor.signLogoutToken(t, "jti-1", "bob-id", "")); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	_, body := get(app.URL + "/private")
+	if body != "private" {
+		t.Errorf("Expected the session to be kept, got %s", body)
+	}
+
+	// The logout of all the user's sessions ends this one
+	if code := postLogoutToken(t, backChannelHandler, casdoor.signLogoutToken(t, "jti-2", "alice-id", "")); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	resp, _ := get(app.URL + "/private")
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("Expected a redirect to Casdoor after the back-channel logout, got %d", resp.StatusCode)
+	}
+
+	// The user can sign in again
+	time.Sleep(time.Millisecond)
+	signIn()
+}
+
+type testRevokerStore struct {
+	*CookieStore
+	sub, sid string
+}
+
+func (s *testRevokerStore) RevokeSessions(ctx context.Context, sub string, sid string) error {
+	s.sub, s.sid = sub, sid
+	return nil
+}
+
+func TestHandlerBackChannelLogoutRevoker(t *testing.T) {
+	casdoor := newTestCasdoor(t)
+	defer casdoor.Close()
+
+	client := casdoorsdk.NewClient(casdoor.URL, "client-id", "client-secret", casdoor.certificate, "built-in", "app-built-in")
+	cookieStore, err := NewCookieStore(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("Failed to create cookie store: %v", err)
+	}
+	store := &testRevokerStore{CookieStore: cookieStore}
+
+	auth := New(client, store, "https://app.example.com/callback")
+	code := postLogoutToken(t, auth.BackChannelLogoutHandler(), casdoor.signLogoutToken(t, "jti-1", "alice-id", "session-id"))
+	if code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if store.sub != "alice-id" || store.sid != "session-id" {
+		t.Errorf("Expected the store to revoke alice-id and session-id, got %q and %q", store.sub, store.sid)
+	}
+}
+
+func TestLogoutRegistry(t *testing.T) {
+	registry := newLogoutRegistry()
+	signinTime := time.Now()
+	registry.add("alice-id", "session-1", signinTime.Add(time.Minute))
+
+	tests := []struct {
+		session  *Session
+		expected bool
+	}{
+		{&Session{Subject: "alice-id", Sid: "session-1", CreatedTime: signinTime}, true},
+		{&Session{Subject: "alice-id", Sid: "session-2", CreatedTime: signinTime}, false},
+		{&Session{Subject: "alice-id", Sid: "session-1", CreatedTime: signinTime.Add(time.Hour)}, false},
+	}
+	for _, test := range tests {
+		if got := registry.isLoggedOut(test.session); got != test.expected {
+			t.Errorf("isLoggedOut(%+v) = %v, expected %v", test.session, got, test.expected)
+		}
+	}
+
+	// The expired logouts are pruned, but not the ones added again since
+	registry = newLogoutRegistry()
+	registry.add("alice-id", "", signinTime.Add(-2*logoutRetention))
+	registry.add("", "session-1", signinTime.Add(-2*logoutRetention))
+	registry.add("", "session-1", signinTime.Add(-time.Hour))
+	registry.add("bob-id", "", signinTime)
+	if len(registry.bySub) != 1 || len(registry.bySid) != 1 || len(registry.queue) != 2 {
+		t.Errorf("Expected the expired logouts to be pruned, got %v, %v", registry.bySub, registry.bySid)
+	}
+}
+
+func TestJtiCache(t *testing.T) {
+	cache := &jtiCache{entries: map[string]time.Time{}}
+	now := time.Now()
+
+	if !cache.add("expired", now.Add(-time.Second)) || !cache.add("jti-1", now.Add(time.Minute)) {
+		t.Fatalf("Expected new jtis to be added")
+	}
+	if cache.add("jti-1", now.Add(time.Minute)) {
+		t.Errorf("Expected a replayed jti to be rejected")
+	}
+	if len(cache.entries) != 1 || len(cache.queue) != 1 {
+		t.Errorf("Expected the expired jti to be pruned, got %v", cache.entries)
+	}
+
+	// A removed jti can be added again
+	cache.remove("jti-1")
+	if !cache.add("jti-1", now.Add(time.Minute)) {
+		t.Errorf("Expected a removed jti to be added again")
+	}
+}
@@ -0,0 +1,612 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webauth provides the handlers that sign the users of a web application in and out
+// with Casdoor, via the authorization code flow with PKCE, and keep their tokens in a session:
+//
+//	store, err := webauth.NewCookieStore(sessionKey)
+//	auth := webauth.New(client, store, "https://app.example.com/callback")
+//	mux.Handle("/login", auth.LoginHandler())
+//	mux.Handle("/callback", auth.CallbackHandler())
+//	mux.Handle("/logout", auth.LogoutHandler())
+//	mux.Handle("/backchannel-logout", auth.BackChannelLogoutHandler())
+//	mux.Handle("/", auth.RequireLogin(appHandler))
+//
+// The handlers behind Middleware() or RequireLogin() get the user with CurrentUser(r).
+package webauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"golang.org/x/oauth2"
+)
+
+// maxLoginAge is how long the user has to sign in at Casdoor.
+const maxLoginAge = 10 * time.Minute
+
+// refreshDelta is how long before their expiry the tokens are refreshed.
+const refreshDelta = time.Minute
+
+// refreshReuse is how long the tokens refreshed for a session are reused by the other requests
+// of the browser, sent with the previous refresh token before it got the new session cookie.
+const refreshReuse = 30 * time.Second
+
+// redirectUrisTTL is how long the redirect URLs of the application are cached, to check the
+// postLogoutRedirectUri of WithEndSession().
+const redirectUrisTTL = 5 * time.Minute
+
+type claimsContextKey struct{}
+
+// CurrentUser returns the claims of the signed-in user, set by Middleware() or RequireLogin().
+// The second result is false when the user is not signed in.
+func CurrentUser(r *http.Request) (*casdoorsdk.Claims, bool) {
+	claims, ok := r.Context().Value(claimsContextKey{}).(*casdoorsdk.Claims)
+	return claims, ok && claims != nil
+}
+
+// Option is a function type for configuring a Handler.
+type Option func(*Handler)
+
+// WithScopes sets the scopes requested at sign-in, the default is "openid profile email".
+func WithScopes(scopes ...string) Option {
+	return func(h *Handler) {
+		h.scopes = scopes
+	}
+}
+
+// WithPostLogoutRedirect sets where the logout handler redirects the browser, the default is "/".
+func WithPostLogoutRedirect(url string) Option {
+	return func(h *Handler) {
+		h.postLogoutRedirect = url
+	}
+}
+
+// WithLogoutAllSessions makes the logout handler end all the user's Casdoor sessions, on all
+// their devices, instead of only the current one. See casdoorsdk.Client.Logout().
+func WithLogoutAllSessions() Option {
+	return func(h *Handler) {
+		h.logoutAll = true
+	}
+}
+
+// WithEndSession makes the logout handler redirect the browser to Casdoor's end-session
+// endpoint, see casdoorsdk.Client.GetEndSessionUrl(), so that the user's browser session at
+// Casdoor ends too. Casdoor then redirects the browser to the postLogoutRedirectUri, which is
+// the absolute URL of the PostLogoutHandler() and must be one of the application's "Redirect
+// URLs" in Casdoor. It needs the "openid" scope, the sessions without an ID token are ended
+// with the "/api/sso-logout" API as usual.
+func WithEndSession(postLogoutRedirectUri string) Option {
+	return func(h *Handler) {
+		h.postLogoutRedirectUri = postLogoutRedirectUri
+	}
+}
+
+// WithErrorHandler sets the function writing the error responses, the default writes the
+// status text of the code.
+func WithErrorHandler(errorHandler func(w http.ResponseWriter, r *http.Request, statusCode int, err error)) Option {
+	return func(h *Handler) {
+		h.errorHandler = errorHandler
+	}
+}
+
+// Handler signs the users in and out with Casdoor, see New().
+type Handler struct {
+	client             *casdoorsdk.Client
+	store              SessionStore
+	redirectUri        string
+	scopes             []string
+	postLogoutRedirect string
+	logoutAll          bool
+	// postLogoutRedirectUri is the URL of the PostLogoutHandler(), when WithEndSession() is set
+	postLogoutRedirectUri string
+	errorHandler          func(w http.ResponseWriter, r *http.Request, statusCode int, err error)
+	logouts               *logoutRegistry
+	refreshes             *refreshGroup
+
+	redirectUrisMu     sync.Mutex
+	redirectUris       []string
+	redirectUrisExpiry time.Time
+}
+
+// New returns a Handler keeping the sessions in the store. The redirectUri is the absolute URL
+// of the CallbackHandler(), it must be one of the application's "Redirect URLs" in Casdoor.
+func New(client *casdoorsdk.Client, store SessionStore, redirectUri string, opts ...Option) *Handler {
+	h := &Handler{
+		client:             client,
+		store:              store,
+		redirectUri:        redirectUri,
+		scopes:             []string{"openid", "profile", "email"},
+		postLogoutRedirect: "/",
+		errorHandler: func(w http.ResponseWriter, r *http.Request, statusCode int, err error) {
+			http.Error(w, http.StatusText(statusCode), statusCode)
+		},
+		logouts:   newLogoutRegistry(),
+		refreshes: &refreshGroup{calls: map[string]*refreshCall{}},
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// LoginHandler returns the handler that redirects the browser to Casdoor's sign-in page.
+// The "return_to" query parameter is the local path the browser is sent back to after
+// signing in, the default is "/".
+func (h *Handler) LoginHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.login(w, r, getReturnTo(r.URL.Query().Get("return_to")))
+	})
+}
+
+func (h *Handler) login(w http.ResponseWriter, r *http.Request, returnTo string) {
+	session, err := h.store.Load(r)
+	if err != nil || session == nil {
+		session = &Session{}
+	}
+
+	session.Login = &PendingLogin{
+		State:        generateRandomString(),
+		Nonce:        generateRandomString(),
+		CodeVerifier: oauth2.GenerateVerifier(),
+		ReturnTo:     returnTo,
+		CreatedTime:  time.Now(),
+	}
+
+	signinUrl, err := h.client.GetSigninUrlWithOptions(h.redirectUri,
+		casdoorsdk.WithScopes(h.scopes...),
+		casdoorsdk.WithState(session.Login.State),
+		casdoorsdk.WithNonce(session.Login.Nonce),
+		casdoorsdk.WithPKCE(session.Login.CodeVerifier))
+	if err != nil {
+		h.errorHandler(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = h.store.Save(w, r, session)
+	if err != nil {
+		h.errorHandler(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	http.Redirect(w, r, signinUrl, http.StatusFound)
+}
+
+// CallbackHandler returns the handler of the redirectUri: it checks the state, exchanges the
+// code for the tokens with the PKCE code verifier, checks the nonce of the ID token, saves the
+// tokens in the session and redirects the browser back to where it was before signing in.
+func (h *Handler) CallbackHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := h.store.Load(r)
+		if err != nil || session == nil || session.Login == nil {
+			h.errorHandler(w, r, http.StatusBadRequest, errors.New("no pending sign-in"))
+			return
+		}
+
+		login := session.Login
+		session.Login = nil
+		query := r.URL.Query()
+		if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(login.State)) != 1 {
+			h.errorHandler(w, r, http.StatusBadRequest, errors.New("the state doesn't match the pending sign-in"))
+			return
+		}
+
+		if time.Since(login.CreatedTime) > maxLoginAge {
+			h.errorHandler(w, r, http.StatusBadRequest, errors.New("the sign-in has expired"))
+			return
+		}
+
+		if errorCode := query.Get("error"); errorCode != "" {
+			h.errorHandler(w, r, http.StatusUnauthorized, &casdoorsdk.OAuthError{ErrorCode: errorCode, ErrorDescription: query.Get("error_description")})
+			return
+		}
+
+		token, err := h.client.GetOAuthToken(query.Get("code"), login.State, casdoorsdk.WithPKCE(login.CodeVerifier))
+		if err != nil {
+			h.errorHandler(w, r, http.StatusUnauthorized, err)
+			return
+		}
+
+		idToken, _ := token.Extra("id_token").(string)
+		subject, sid, err := h.checkNonce(r.Context(), token.AccessToken, idToken, login.Nonce)
+		if err != nil {
+			h.errorHandler(w, r, http.StatusUnauthorized, err)
+			return
+		}
+
+		session.AccessToken = token.AccessToken
+		session.RefreshToken = token.RefreshToken
+		session.IdToken = idToken
+		session.Expiry = token.Expiry
+		session.Subject = subject
+		session.Sid = sid
+		session.CreatedTime = time.Now()
+		err = h.store.Save(w, r, session)
+		if err != nil {
+			h.errorHandler(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		http.Redirect(w, r, login.ReturnTo, http.StatusFound)
+	})
+}
+
+// checkNonce checks the nonce of the ID token, or of the access token when there is no ID
+// token, as Casdoor puts the nonce in both. It returns the "sub" and "sid" of the token.
+func (h *Handler) checkNonce(ctx context.Context, accessToken string, idToken string, nonce string) (string, string, error) {
+	if idToken != "" {
+		idClaims, err := h.client.VerifyIDToken(ctx, idToken, accessToken, "", nonce)
+		if err != nil {
+			return "", "", err
+		}
+
+		return idClaims.Subject, idClaims.Sid, nil
+	}
+
+	claims, err := h.client.ParseJwtToken(accessToken)
+	if err != nil {
+		return "", "", err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return "", "", casdoorsdk.ErrInvalidNonce
+	}
+
+	return claims.Subject, "", nil
+}
+
+// LogoutHandler returns the handler that ends the user's Casdoor session, deletes the local
+// session and redirects the browser to the post-logout URL. It only accepts POST, so that other
+// sites can't sign the users out with a link or an image. With WithEndSession(), the browser
+// is redirected to Casdoor's end-session endpoint first.
+func (h *Handler) LogoutHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			h.errorHandler(w, r, http.StatusMethodNotAllowed, errors.New("the logout request should be a POST"))
+			return
+		}
+
+		session, err := h.store.Load(r)
+		if err != nil || session == nil {
+			session = &Session{}
+		}
+
+		if h.postLogoutRedirectUri != "" && session.IdToken != "" {
+			h.endSession(w, r, session)
+			return
+		}
+
+		if session.AccessToken != "" {
+			// the local session is deleted anyway, even if the Casdoor one is already gone
+			if h.logoutAll {
+				_ = h.client.Logout(session.AccessToken)
+			} else {
+				_ = h.client.LogoutCurrentSession(session.AccessToken)
+			}
+		}
+
+		err = h.store.Delete(w, r)
+		if err != nil {
+			h.errorHandler(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		http.Redirect(w, r, h.postLogoutRedirect, http.StatusFound)
+	})
+}
+
+// endSession replaces the session with the state of the RP-initiated logout, checked by the
+// PostLogoutHandler(), and redirects the browser to Casdoor's end-session endpoint.
+func (h *Handler) endSession(w http.ResponseWriter, r *http.Request, session *Session) {
+	if h.logoutAll {
+		_ = h.client.Logout(session.AccessToken)
+	}
+
+	redirectUris, err := h.getRedirectUris()
+	if err != nil {
+		h.errorHandler(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	logoutState := generateRandomString()
+	endSessionUrl, err := h.client.GetEndSessionUrlWithRedirectUris(session.IdToken, h.postLogoutRedirectUri, logoutState, redirectUris)
+	if err != nil {
+		h.errorHandler(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = h.store.Save(w, r, &Session{LogoutState: logoutState})
+	if err != nil {
+		h.errorHandler(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	http.Redirect(w, r, endSessionUrl, http.StatusFound)
+}
+
+// getRedirectUris returns the redirect URLs of the application, fetched at most every redirectUrisTTL.
+func (h *Handler) getRedirectUris() ([]string, error) {
+	h.redirectUrisMu.Lock()
+	defer h.redirectUrisMu.Unlock()
+
+	if time.Now().Before(h.redirectUrisExpiry) {
+		return h.redirectUris, nil
+	}
+
+	application, err := h.client.GetApplication(h.client.ApplicationName)
+	if err != nil {
+		return nil, err
+	}
+	if application == nil {
+		return nil, fmt.Errorf("the application %q doesn't exist", h.client.ApplicationName)
+	}
+
+	h.redirectUris = application.RedirectUris
+	h.redirectUrisExpiry = time.Now().Add(redirectUrisTTL)
+	return h.redirectUris, nil
+}
+
+// PostLogoutHandler returns the handler of the postLogoutRedirectUri of WithEndSession(): it
+// checks the state returned by Casdoor, deletes the session and redirects the browser to the
+// post-logout URL.
+func (h *Handler) PostLogoutHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := h.store.Load(r)
+		if err != nil || session == nil || session.LogoutState == "" {
+			h.errorHandler(w, r, http.StatusBadRequest, errors.New("no pending logout"))
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("state")), []byte(session.LogoutState)) != 1 {
+			h.errorHandler(w, r, http.StatusBadRequest, errors.New("the state doesn't match the pending logout"))
+			return
+		}
+
+		err = h.store.Delete(w, r)
+		if err != nil {
+			h.errorHandler(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		http.Redirect(w, r, h.postLogoutRedirect, http.StatusFound)
+	})
+}
+
+// FrontChannelLogoutHandler returns the handler of the OpenID Connect Front-Channel Logout
+// requests, loaded by Casdoor's logout page in an iframe, see
+// casdoorsdk.Client.GetFrontChannelLogoutUrl(). The requests are not authenticated, so only
+// the browser's own session is ended: it is deleted when the "sid" parameter is its "sid" or
+// absent, and its "sid" is rejected by LoadUser() from then on. The browsers only send the
+// session cookie of a CookieStore, which is SameSite=Lax, to an iframe of the same site, so
+// when Casdoor is on another site, end the sessions with BackChannelLogoutHandler() instead.
+func (h *Handler) FrontChannelLogoutHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-cache, no-store")
+		w.Header().Set("Pragma", "no-cache")
+
+		query := r.URL.Query()
+		issuer, sid := query.Get("iss"), query.Get("sid")
+		if issuer != "" && !casdoorsdk.IsSameIssuer(issuer, h.client.Endpoint) {
+			h.errorHandler(w, r, http.StatusBadRequest, errors.New("the issuer of the logout request is not Casdoor"))
+			return
+		}
+		if sid != "" && issuer == "" {
+			h.errorHandler(w, r, http.StatusBadRequest, errors.New("the \"iss\" parameter is required with \"sid\""))
+			return
+		}
+
+		session, err := h.store.Load(r)
+		if err == nil && session != nil && (sid == "" || session.Sid == sid) {
+			if session.Sid != "" {
+				h.logouts.add("", session.Sid, time.Now())
+			}
+
+			err = h.store.Delete(w, r)
+			if err != nil {
+				h.errorHandler(w, r, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// LoadUser returns the claims of the signed-in user and their session, refreshing the tokens
+// when they are about to expire. It returns nil claims without error when the user is not
+// signed in, when the tokens can't be refreshed anymore, or when the session was ended by a
+// back-channel logout. The concurrent requests of a browser refresh the tokens once, and
+// share the result, so that the refresh tokens rotated by Casdoor are only used once.
+func (h *Handler) LoadUser(w http.ResponseWriter, r *http.Request) (*casdoorsdk.Claims, *Session, error) {
+	session, err := h.store.Load(r)
+	if err != nil || session == nil || session.AccessToken == "" {
+		return nil, nil, nil
+	}
+
+	if h.logouts.isLoggedOut(session) {
+		return nil, nil, h.store.Delete(w, r)
+	}
+
+	if !session.Expiry.IsZero() && time.Until(session.Expiry) < refreshDelta {
+		if session.RefreshToken == "" {
+			return nil, nil, nil
+		}
+
+		token, err := h.refreshes.refresh(session.RefreshToken, func() (*oauth2.Token, error) {
+			return h.client.RefreshOAuthToken(session.RefreshToken)
+		})
+		if err != nil {
+			// the refresh token is expired or revoked, the user needs to sign in again
+			session.AccessToken, session.RefreshToken, session.IdToken = "", "", ""
+			return nil, nil, h.store.Save(w, r, session)
+		}
+
+		session.AccessToken = token.AccessToken
+		if token.RefreshToken != "" {
+			session.RefreshToken = token.RefreshToken
+		}
+		if idToken, ok := token.Extra("id_token").(string); ok && idToken != "" {
+			session.IdToken = idToken
+		}
+		session.Expiry = token.Expiry
+		err = h.store.Save(w, r, session)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	claims, err := h.client.ParseJwtToken(session.AccessToken)
+	if err != nil {
+		return nil, nil, nil
+	}
+
+	return claims, session, nil
+}
+
+// refreshGroup runs a single refresh per refresh token, whose result is shared with the
+// concurrent refreshes of the same token and reused for refreshReuse. The refreshes all have
+// the same reuse duration, so the order in which they complete is the order in which they
+// expire, and the expired ones are pruned from the front of the queue.
+type refreshGroup struct {
+	mu    sync.Mutex
+	calls map[string]*refreshCall
+	queue []refreshGroupEntry
+}
+
+type refreshCall struct {
+	done  chan struct{}
+	token *oauth2.Token
+	err   error
+}
+
+type refreshGroupEntry struct {
+	key    string
+	call   *refreshCall
+	expiry time.Time
+}
+
+// refresh returns the result of the refresh of the refreshToken in progress or completed in
+// the last refreshReuse, or else calls refresh.
+func (group *refreshGroup) refresh(refreshToken string, refresh func() (*oauth2.Token, error)) (*oauth2.Token, error) {
+	// the refresh tokens aren't kept in memory
+	sum := sha256.Sum256([]byte(refreshToken))
+	key := string(sum[:])
+
+	group.mu.Lock()
+	now := time.Now()
+	for len(group.queue) > 0 && !now.Before(group.queue[0].expiry) {
+		entry := group.queue[0]
+		group.queue = group.queue[1:]
+		if group.calls[entry.key] == entry.call {
+			delete(group.calls, entry.key)
+		}
+	}
+
+	if call, ok := group.calls[key]; ok {
+		group.mu.Unlock()
+		<-call.done
+		return call.token, call.err
+	}
+
+	call := &refreshCall{done: make(chan struct{})}
+	group.calls[key] = call
+	group.mu.Unlock()
+
+	call.token, call.err = refresh()
+
+	group.mu.Lock()
+	if call.err != nil {
+		// the next requests try again
+		delete(group.calls, key)
+	} else {
+		group.queue = append(group.queue, refreshGroupEntry{key: key, call: call, expiry: time.Now().Add(refreshReuse)})
+	}
+	group.mu.Unlock()
+	close(call.done)
+
+	return call.token, call.err
+}
+
+// Middleware returns a middleware that sets the signed-in user, if any, for CurrentUser().
+func (h *Handler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _, err := h.LoadUser(w, r)
+		if err != nil {
+			h.errorHandler(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if claims != nil {
+			r = r.WithContext(context.WithValue(r.Context(), claimsContextKey{}, claims))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireLogin is like Middleware() but redirects the browser to Casdoor's sign-in page when
+// the user is not signed in, and back to the requested page afterwards. The requests other
+// than GET and HEAD get a 401 response instead.
+func (h *Handler) RequireLogin(next http.Handler) http.Handler {
+	return h.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := CurrentUser(r); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			h.errorHandler(w, r, http.StatusUnauthorized, errors.New("the user is not signed in"))
+			return
+		}
+
+		h.login(w, r, getReturnTo(r.URL.RequestURI()))
+	}))
+}
+
+// getReturnTo only accepts the local paths, so that the login handler can't be used to
+// redirect the users to another site.
+func getReturnTo(returnTo string) string {
+	if !strings.HasPrefix(returnTo, "/") || strings.HasPrefix(returnTo, "//") || strings.HasPrefix(returnTo, "/\\") {
+		return "/"
+	}
+
+	u, err := url.Parse(returnTo)
+	if err != nil || u.IsAbs() || u.Host != "" {
+		return "/"
+	}
+
+	return returnTo
+}
+
+func generateRandomString() string {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+)
+
+// newTestApp returns an application signed in with Casdoor and the browser of the user.
+func newTestApp(t *testing.T, casdoor *testCasdoor, opts ...Option) (*httptest.Server, testBrowser) {
+	client := casdoorsdk.NewClient(casdoor.URL, "client-id", "client-secret", casdoor.certificate, "built-in", "app-built-in")
+	store, err := NewCookieStore(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("Failed to create cookie store: %v", err)
+	}
+	store.Insecure = true
+
+	mux := http.NewServeMux()
+	app := httptest.NewServer(mux)
+	casdoor.mu.Lock()
+	casdoor.redirectUris = []string{app.URL + "/callback", app.URL + "/logout/"}
+	casdoor.mu.Unlock()
+
+	auth := New(client, store, app.URL+"/callback", append([]Option{WithEndSession(app.URL + "/logout/callback")}, opts...)...)
+	mux.Handle("/login", auth.LoginHandler())
+	mux.Handle("/callback", auth.CallbackHandler())
+	mux.Handle("/logout", auth.LogoutHandler())
+	mux.Handle("/logout/callback", auth.PostLogoutHandler())
+	mux.Handle("/frontchannel-logout", auth.FrontChannelLogoutHandler())
+	mux.Handle("/private", auth.RequireLogin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("private"))
+	})))
+
+	get := newTestBrowser(t, casdoor)
+	resp, _ := get(app.URL + "/login?return_to=/private")
+	code, state := casdoor.authorize(t, resp.Header.Get("Location"))
+	_, body := get(app.URL + "/callback?code=" + code + "&state=" + state)
+	if body != "private" {
+		t.Fatalf("Expected the private page, got %s", body)
+	}
+
+	return app, get
+}
+
+func TestEndSession(t *testing.T) {
+	casdoor := newTestCasdoor(t)
+	defer casdoor.Close()
+	app, get := newTestApp(t, casdoor)
+	defer app.Close()
+
+	// The browser is sent to Casdoor's end-session endpoint
+	resp, _ := get(app.URL+"/logout", url.Values{})
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("Expected a redirect to Casdoor, got %d", resp.StatusCode)
+	}
+	endSessionUrl, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("Failed to parse the end-session URL: %v", err)
+	}
+	query := endSessionUrl.Query()
+	if endSessionUrl.Path != "/api/logout" || query.Get("id_token_hint") == "" || query.Get("post_logout_redirect_uri") != app.URL+"/logout/callback" {
+		t.Fatalf("Unexpected end-session URL: %s", endSessionUrl)
+	}
+
+	// The local session is already gone
+	resp, _ = get(app.URL + "/private")
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("Expected a redirect to Casdoor after logout, got %d", resp.StatusCode)
+	}
+
+	// A forged state is rejected
+	resp, _ = get(app.URL + "/logout/callback?state=forged")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a forged state, got %d", resp.StatusCode)
+	}
+
+	// Casdoor sends the browser back with the state
+	resp, _ = get(app.URL + "/logout/callback?state=" + url.QueryEscape(query.Get("state")))
+	if resp.Request.URL.Path != "/" {
+		t.Errorf("Expected a redirect to /, got %s", resp.Request.URL)
+	}
+	resp, _ = get(app.URL + "/logout/callback?state=" + url.QueryEscape(query.Get("state")))
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a replayed post-logout redirect, got %d", resp.StatusCode)
+	}
+
+	// The redirect URLs of the application are cached
+	resp, _ = get(app.URL + "/login?return_to=/private")
+	code, state := casdoor.authorize(t, resp.Header.Get("Location"))
+	get(app.URL + "/callback?code=" + code + "&state=" + state)
+	resp, _ = get(app.URL+"/logout", url.Values{})
+	if resp.StatusCode != http.StatusFound || atomic.LoadInt32(&casdoor.appCount) != 1 {
+		t.Errorf("Expected the redirect URLs to be fetched once, got %d fetches", casdoor.appCount)
+	}
+}
+
+func TestFrontChannelLogout(t *testing.T) {
+	casdoor := newTestCasdoor(t)
+	defer casdoor.Close()
+	app, get := newTestApp(t, casdoor)
+	defer app.Close()
+
+	// The logout from another issuer is rejected, and the one of another session is ignored
+	resp, _ := get(app.URL + "/frontchannel-logout?iss=https%3A%2F%2Fevil.com&sid=alice-session")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for another issuer, got %d", resp.StatusCode)
+	}
+	resp, _ = get(app.URL + "/frontchannel-logout?iss=" + url.QueryEscape(casdoor.URL) + "&sid=bob-session")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Cache-Control") != "no-cache, no-store" {
+		t.Errorf("Expected a non-cached 200, got %d", resp.StatusCode)
+	}
+	_, body := get(app.URL + "/private")
+	if body != "private" {
+		t.Errorf("Expected the session to be kept, got %s", body)
+	}
+
+	// The logout without the session cookie is ignored, so that knowing a sid is not enough to
+	// end the session
+	resp, err := http.Get(app.URL + "/frontchannel-logout?iss=" + url.QueryEscape(casdoor.URL) + "&sid=alice-session")
+	if err != nil {
+		t.Fatalf("Failed to send the front-channel logout: %v", err)
+	}
+	resp.Body.Close()
+	_, body = get(app.URL + "/private")
+	if body != "private" {
+		t.Errorf("Expected the session to be kept, got %s", body)
+	}
+
+	// The logout of the session's sid ends it
+	resp, _ = get(app.URL + "/frontchannel-logout?iss=" + url.QueryEscape(casdoor.URL+"/") + "&sid=alice-session")
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200, got %d", resp.StatusCode)
+	}
+	resp, _ = get(app.URL + "/private")
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("Expected a redirect to Casdoor after the front-channel logout, got %d", resp.StatusCode)
+	}
+}
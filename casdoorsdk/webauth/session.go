@@ -0,0 +1,218 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webauth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Session is the state of a browser kept by a SessionStore: the user's tokens once signed in,
+// the pending sign-in between the login and callback handlers, and the pending logout between
+// the logout and post-logout handlers.
+type Session struct {
+	AccessToken  string    `json:"accessToken,omitempty"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
+	IdToken      string    `json:"idToken,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+	// Subject and Sid are the "sub" and "sid" of the user's ID token, matched by the
+	// back-channel logouts, and CreatedTime is when the user signed in.
+	Subject     string    `json:"subject,omitempty"`
+	Sid         string    `json:"sid,omitempty"`
+	CreatedTime time.Time `json:"createdTime,omitempty"`
+
+	Login *PendingLogin `json:"login,omitempty"`
+	// LogoutState is the state of a logout at Casdoor's end-session endpoint, see WithEndSession()
+	LogoutState string `json:"logoutState,omitempty"`
+}
+
+// PendingLogin is a sign-in started by the login handler and not yet completed by the callback handler.
+type PendingLogin struct {
+	State        string    `json:"state"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"codeVerifier"`
+	ReturnTo     string    `json:"returnTo"`
+	CreatedTime  time.Time `json:"createdTime"`
+}
+
+// SessionStore keeps the sessions of the browsers. Load() returns nil without error when the
+// browser has no session.
+type SessionStore interface {
+	Load(r *http.Request) (*Session, error)
+	Save(w http.ResponseWriter, r *http.Request, session *Session) error
+	Delete(w http.ResponseWriter, r *http.Request) error
+}
+
+// maxCookieChunkSize keeps each cookie under the 4096 bytes limit of the browsers, as the
+// Casdoor tokens, which embed the user, often don't fit in a single cookie.
+const maxCookieChunkSize = 3800
+
+// maxCookieChunks bounds the number of cookies of a session.
+const maxCookieChunks = 10
+
+// CookieStore is a SessionStore keeping the sessions in the browsers, in cookies encrypted and
+// authenticated with AES-GCM, so that no server-side storage is needed. A large session is
+// split into several cookies: "name", "name.1", "name.2"...
+type CookieStore struct {
+	aead cipher.AEAD
+
+	// Name is the name of the cookie, the default is "casdoor_session".
+	Name string
+	// Path is the path of the cookie, the default is "/".
+	Path string
+	// Domain is the domain of the cookie, the default is the host of the request.
+	Domain string
+	// MaxAge is the lifetime of the cookie, the default is 0: until the browser is closed.
+	MaxAge time.Duration
+	// Insecure lets the cookie be sent over plain HTTP, it should only be set for local development.
+	Insecure bool
+}
+
+// NewCookieStore returns a CookieStore encrypting the cookies with the key, which is a
+// random AES key of 16, 24 or 32 bytes. All the instances of an application must share the key.
+func NewCookieStore(key []byte) (*CookieStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CookieStore{
+		aead: aead,
+		Name: "casdoor_session",
+		Path: "/",
+	}, nil
+}
+
+func (s *CookieStore) getChunkName(i int) string {
+	if i == 0 {
+		return s.Name
+	}
+
+	return fmt.Sprintf("%s.%d", s.Name, i)
+}
+
+func (s *CookieStore) Load(r *http.Request) (*Session, error) {
+	var chunks []string
+	for i := 0; i < maxCookieChunks; i++ {
+		cookie, err := r.Cookie(s.getChunkName(i))
+		if err != nil {
+			break
+		}
+		chunks = append(chunks, cookie.Value)
+	}
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(strings.Join(chunks, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := s.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, errors.New("the session cookie is too short")
+	}
+
+	plaintext, err := s.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(s.Name))
+	if err != nil {
+		return nil, errors.New("the session cookie can't be decrypted")
+	}
+
+	var session Session
+	err = json.Unmarshal(plaintext, &session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (s *CookieStore) Save(w http.ResponseWriter, r *http.Request, session *Session) error {
+	plaintext, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return err
+	}
+
+	value := base64.RawURLEncoding.EncodeToString(s.aead.Seal(nonce, nonce, plaintext, []byte(s.Name)))
+	chunkCount := (len(value) + maxCookieChunkSize - 1) / maxCookieChunkSize
+	if chunkCount > maxCookieChunks {
+		return fmt.Errorf("the session is too large for %d cookies", maxCookieChunks)
+	}
+
+	for i := 0; i < chunkCount; i++ {
+		end := (i + 1) * maxCookieChunkSize
+		if end > len(value) {
+			end = len(value)
+		}
+		http.SetCookie(w, s.newCookie(s.getChunkName(i), value[i*maxCookieChunkSize:end], int(s.MaxAge/time.Second)))
+	}
+
+	// delete the chunks left by a larger session
+	for i := chunkCount; i < maxCookieChunks; i++ {
+		_, err = r.Cookie(s.getChunkName(i))
+		if err != nil {
+			break
+		}
+		http.SetCookie(w, s.newCookie(s.getChunkName(i), "", -1))
+	}
+
+	return nil
+}
+
+func (s *CookieStore) Delete(w http.ResponseWriter, r *http.Request) error {
+	for i := 0; i < maxCookieChunks; i++ {
+		_, err := r.Cookie(s.getChunkName(i))
+		if err != nil {
+			break
+		}
+		http.SetCookie(w, s.newCookie(s.getChunkName(i), "", -1))
+	}
+
+	return nil
+}
+
+func (s *CookieStore) newCookie(name string, value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     s.Path,
+		Domain:   s.Domain,
+		MaxAge:   maxAge,
+		Secure:   !s.Insecure,
+		HttpOnly: true,
+		// "Lax" lets the cookie be sent when Casdoor redirects the browser to the callback
+		SameSite: http.SameSiteLaxMode,
+	}
+}
@@ -0,0 +1,424 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webauth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+)
+
+// testCasdoor is a fake Casdoor server implementing the authorization code flow with PKCE.
+type testCasdoor struct {
+	*httptest.Server
+	key         *rsa.PrivateKey
+	certificate string
+	expiresIn   int32
+
+	mu           sync.Mutex
+	challenges   map[string]string
+	nonces       map[string]string
+	redirectUris []string
+	// usedRefreshTokens are rejected, as Casdoor rotates the refresh tokens
+	usedRefreshTokens map[string]bool
+	refreshCount      int32
+	logoutCount       int32
+	appCount          int32
+}
+
+func newTestCasdoor(t *testing.T) *testCasdoor {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Casdoor Cert"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	casdoor := &testCasdoor{
+		key:         key,
+		certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		expiresIn:   3600,
+		challenges:  map[string]string{},
+		nonces:      map[string]string{},
+
+		usedRefreshTokens: map[string]bool{},
+	}
+	casdoor.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		casdoor.serveHTTP(t, w, r)
+	}))
+	return casdoor
+}
+
+// authorize simulates the user signing in at the sign-in URL, and returns the code.
+func (casdoor *testCasdoor) authorize(t *testing.T, signinUrl string) (string, string) {
+	u, err := url.Parse(signinUrl)
+	if err != nil {
+		t.Fatalf("Failed to parse the sign-in URL: %v", err)
+	}
+
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("Expected a S256 PKCE challenge, got %q", signinUrl)
+	}
+
+	casdoor.mu.Lock()
+	defer casdoor.mu.Unlock()
+	code := "code-" + query.Get("state")
+	casdoor.challenges[code] = query.Get("code_challenge")
+	casdoor.nonces[code] = query.Get("nonce")
+	return code, query.Get("state")
+}
+
+func (casdoor *testCasdoor) serveHTTP(t *testing.T, w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/api/login/oauth/access_token":
+		code := r.PostFormValue("code")
+		casdoor.mu.Lock()
+		challenge, nonce := casdoor.challenges[code], casdoor.nonces[code]
+		casdoor.mu.Unlock()
+		if challenge == "" || oauth2.S256ChallengeFromVerifier(r.PostFormValue("code_verifier")) != challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		casdoor.writeToken(t, w, nonce, "refresh-token")
+	case "/api/login/oauth/refresh_token":
+		refreshToken := r.PostFormValue("refresh_token")
+		casdoor.mu.Lock()
+		used := casdoor.usedRefreshTokens[refreshToken]
+		casdoor.usedRefreshTokens[refreshToken] = true
+		casdoor.mu.Unlock()
+		if used {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		// let the concurrent refreshes overlap
+		time.Sleep(20 * time.Millisecond)
+		count := atomic.AddInt32(&casdoor.refreshCount, 1)
+		casdoor.writeToken(t, w, "", fmt.Sprintf("refresh-token-%d", count))
+	case "/api/get-application":
+		atomic.AddInt32(&casdoor.appCount, 1)
+		casdoor.mu.Lock()
+		redirectUris := casdoor.redirectUris
+		casdoor.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(casdoorsdk.Response{
+			Status: "ok",
+			Data:   casdoorsdk.Application{Owner: "admin", Name: "app-built-in", RedirectUris: redirectUris},
+		})
+	case "/api/sso-logout":
+		atomic.AddInt32(&casdoor.logoutCount, 1)
+		_ = json.NewEncoder(w).Encode(casdoorsdk.Response{Status: "ok"})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (casdoor *testCasdoor) writeToken(t *testing.T, w http.ResponseWriter, nonce string, refreshToken string) {
+	claims := &casdoorsdk.Claims{
+		User:      casdoorsdk.User{Owner: "built-in", Name: "alice"},
+		TokenType: "access-token",
+		Nonce:     nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    casdoor.URL,
+			Subject:   "alice-id",
+			Audience:  []string{"client-id"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(casdoor.key)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	idToken, err := jwt.NewWithClaims(jwt.SigningMethodRS256, &casdoorsdk.IDTokenClaims{Claims: *claims, Sid: "alice-session"}).SignedString(casdoor.key)
+	if err != nil {
+		t.Fatalf("Failed to sign ID token: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token":  token,
+		"id_token":      idToken,
+		"refresh_token": refreshToken,
+		"token_type":    "Bearer",
+		"expires_in":    atomic.LoadInt32(&casdoor.expiresIn),
+	})
+}
+
+// testBrowser gets a URL, or posts the form to it when there is one.
+type testBrowser func(target string, form ...url.Values) (*http.Response, string)
+
+// newTestBrowser returns a browser that keeps the cookies and stops at Casdoor's sign-in page.
+func newTestBrowser(t *testing.T, casdoor *testCasdoor) testBrowser {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatalf("Failed to create cookie jar: %v", err)
+	}
+	browser := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if strings.HasPrefix(req.URL.String(), casdoor.URL) {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+
+	return func(target string, form ...url.Values) (*http.Response, string) {
+		var resp *http.Response
+		var err error
+		if len(form) > 0 {
+			resp, err = browser.PostForm(target, form[0])
+		} else {
+			resp, err = browser.Get(target)
+		}
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", target, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+}
+
+func TestWebAuth(t *testing.T) {
+	casdoor := newTestCasdoor(t)
+	defer casdoor.Close()
+
+	client := casdoorsdk.NewClient(casdoor.URL, "client-id", "client-secret", casdoor.certificate, "built-in", "app-built-in")
+	store, err := NewCookieStore(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("Failed to create cookie store: %v", err)
+	}
+	store.Insecure = true
+
+	mux := http.NewServeMux()
+	app := httptest.NewServer(mux)
+	defer app.Close()
+
+	auth := New(client, store, app.URL+"/callback")
+	mux.Handle("/login", auth.LoginHandler())
+	mux.Handle("/callback", auth.CallbackHandler())
+	mux.Handle("/logout", auth.LogoutHandler())
+	mux.Handle("/private", auth.RequireLogin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := CurrentUser(r)
+		_, _ = w.Write([]byte("hello " + claims.Name))
+	})))
+
+	get := newTestBrowser(t, casdoor)
+
+	// An anonymous user is sent to Casdoor
+	resp, _ := get(app.URL + "/private?tab=1")
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("Expected a redirect to Casdoor, got %d", resp.StatusCode)
+	}
+	code, state := casdoor.authorize(t, resp.Header.Get("Location"))
+
+	// A forged state is rejected
+	resp, _ = get(app.URL + "/callback?code=" + code + "&state=forged")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a forged state, got %d", resp.StatusCode)
+	}
+
+	// The callback signs the user in and sends them back to the page
+	resp, body := get(app.URL + "/callback?code=" + code + "&state=" + state)
+	if resp.StatusCode != http.StatusOK || body != "hello alice" {
+		t.Fatalf("Expected the private page, got %d: %s", resp.StatusCode, body)
+	}
+	if resp.Request.URL.RequestURI() != "/private?tab=1" {
+		t.Errorf("Expected to return to /private?tab=1, got %s", resp.Request.URL.RequestURI())
+	}
+
+	// The pending sign-in can't be replayed
+	resp, _ = get(app.URL + "/callback?code=" + code + "&state=" + state)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a replayed callback, got %d", resp.StatusCode)
+	}
+
+	// The tokens about to expire are refreshed
+	atomic.StoreInt32(&casdoor.expiresIn, 10)
+	resp, _ = get(app.URL + "/login?return_to=/private")
+	code, state = casdoor.authorize(t, resp.Header.Get("Location"))
+	_, body = get(app.URL + "/callback?code=" + code + "&state=" + state)
+	if body != "hello alice" {
+		t.Fatalf("Expected the private page, got %s", body)
+	}
+	if atomic.LoadInt32(&casdoor.refreshCount) != 1 {
+		t.Errorf("Expected 1 refresh, got %d", casdoor.refreshCount)
+	}
+
+	// The logout is a POST, so that other sites can't sign the user out
+	resp, _ = get(app.URL + "/logout")
+	if resp.StatusCode != http.StatusMethodNotAllowed || resp.Header.Get("Allow") != http.MethodPost {
+		t.Errorf("Expected 405 for a GET, got %d", resp.StatusCode)
+	}
+
+	// The logout ends both sessions
+	resp, _ = get(app.URL+"/logout", url.Values{})
+	if resp.Request.URL.Path != "/" {
+		t.Errorf("Expected a redirect to /, got %s", resp.Request.URL)
+	}
+	if atomic.LoadInt32(&casdoor.logoutCount) != 1 {
+		t.Errorf("Expected 1 Casdoor logout, got %d", casdoor.logoutCount)
+	}
+	resp, _ = get(app.URL + "/private")
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("Expected a redirect to Casdoor after logout, got %d", resp.StatusCode)
+	}
+}
+
+func TestConcurrentRefresh(t *testing.T) {
+	casdoor := newTestCasdoor(t)
+	defer casdoor.Close()
+
+	client := casdoorsdk.NewClient(casdoor.URL, "client-id", "client-secret", casdoor.certificate, "built-in", "app-built-in")
+	store, err := NewCookieStore(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("Failed to create cookie store: %v", err)
+	}
+	auth := New(client, store, "https://app.example.com/callback")
+
+	recorder := httptest.NewRecorder()
+	session := &Session{AccessToken: "expired", RefreshToken: "refresh-token", Expiry: time.Now().Add(time.Second), CreatedTime: time.Now()}
+	err = store.Save(recorder, httptest.NewRequest("GET", "/", nil), session)
+	if err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+	cookies := recorder.Result().Cookies()
+	loadUser := func() (*casdoorsdk.Claims, *Session, error) {
+		r := httptest.NewRequest("GET", "/", nil)
+		for _, cookie := range cookies {
+			r.AddCookie(cookie)
+		}
+		return auth.LoadUser(httptest.NewRecorder(), r)
+	}
+
+	// The parallel requests of a browser share a single refresh
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			claims, session, err := loadUser()
+			if err != nil || claims == nil || session.RefreshToken != "refresh-token-1" {
+				errs <- fmt.Errorf("unexpected result: %v, %+v, %v", claims, session, err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// and so do the requests sent with the previous cookie afterwards
+	claims, _, err := loadUser()
+	if err != nil || claims == nil {
+		t.Errorf("Expected the refreshed tokens to be reused, got %v, %v", claims, err)
+	}
+	if count := atomic.LoadInt32(&casdoor.refreshCount); count != 1 {
+		t.Errorf("Expected 1 refresh, got %d", count)
+	}
+}
+
+func TestGetReturnTo(t *testing.T) {
+	tests := map[string]string{
+		"/private?tab=1":         "/private?tab=1",
+		"":                       "/",
+		"https://evil.com":       "/",
+		"//evil.com":             "/",
+		"/\\evil.com":            "/",
+		"private":                "/",
+		"javascript:alert(1)//x": "/",
+	}
+	for returnTo, expected := range tests {
+		if got := getReturnTo(returnTo); got != expected {
+			t.Errorf("getReturnTo(%q) = %q, expected %q", returnTo, got, expected)
+		}
+	}
+}
+
+func TestCookieStoreChunks(t *testing.T) {
+	store, err := NewCookieStore(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("Failed to create cookie store: %v", err)
+	}
+
+	session := &Session{AccessToken: strings.Repeat("a", 10000), RefreshToken: "refresh-token"}
+	recorder := httptest.NewRecorder()
+	err = store.Save(recorder, httptest.NewRequest("GET", "/", nil), session)
+	if err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+
+	cookies := recorder.Result().Cookies()
+	if len(cookies) < 3 {
+		t.Fatalf("Expected the session to be split, got %d cookies", len(cookies))
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range cookies {
+		if len(cookie.String()) > 4096 {
+			t.Errorf("Cookie %s is too large: %d bytes", cookie.Name, len(cookie.String()))
+		}
+		req.AddCookie(cookie)
+	}
+
+	loaded, err := store.Load(req)
+	if err != nil {
+		t.Fatalf("Failed to load session: %v", err)
+	}
+	if loaded.AccessToken != session.AccessToken || loaded.RefreshToken != session.RefreshToken {
+		t.Errorf("The loaded session differs from the saved one")
+	}
+
+	// A tampered cookie is rejected
+	otherStore, _ := NewCookieStore([]byte(strings.Repeat("k", 32)))
+	_, err = otherStore.Load(req)
+	if err == nil {
+		t.Errorf("Expected an error for a cookie encrypted with another key")
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// WebauthnCredential is a passkey or security key of a user, an element of
+// User.WebauthnCredentials, which has the layout of webauthn.Credential in Casdoor.
+type WebauthnCredential struct {
+	// Id is the credential ID, see GetId() for its form in the browsers.
+	Id              []byte                  `json:"id"`
+	PublicKey       []byte                  `json:"publicKey"`
+	AttestationType string                  `json:"attestationType"`
+	Transports      []string                `json:"transport"`
+	Flags           WebauthnCredentialFlags `json:"flags"`
+	Authenticator   WebauthnAuthenticator   `json:"authenticator"`
+}
+
+type WebauthnCredentialFlags struct {
+	UserPresent    bool `json:"userPresent"`
+	UserVerified   bool `json:"userVerified"`
+	BackupEligible bool `json:"backupEligible"`
+	BackupState    bool `json:"backupState"`
+}
+
+type WebauthnAuthenticator struct {
+	// Aaguid identifies the model of the authenticator, see GetAaguid().
+	Aaguid       []byte `json:"AAGUID"`
+	SignCount    uint32 `json:"signCount"`
+	CloneWarning bool   `json:"cloneWarning"`
+	// Attachment is "platform" for the authenticators of the devices and "cross-platform"
+	// for the security keys.
+	Attachment string `json:"attachment"`
+}
+
+// knownAaguids are the names of common authenticator models, see WebauthnCredential.GetName().
+var knownAaguids = map[string]string{
+	"ea9b8d66-4d01-1d21-3ce4-b6b48cb575d4": "Google Password Manager",
+	"adce0002-35bc-c60a-648b-0b25f1f05503": "Chrome on Mac",
+	"fbfc3007-154e-4ecc-8c0b-6e020557d7bd": "iCloud Keychain",
+	"08987058-cadc-4b81-b6e1-30de50dcbe96": "Windows Hello",
+	"9ddd1817-af5a-4672-a2b9-3e3dd95000a9": "Windows Hello",
+	"6028b017-b1d4-4c02-b4b3-afcdafc96bb2": "Windows Hello",
+	"bada5566-a7aa-401f-bd96-45619a55120d": "1Password",
+	"d548826e-79b4-db40-a3d8-11116f7e8349": "Bitwarden",
+}
+
+// GetId returns the credential ID in unpadded base64url, as in the credentials of the browsers.
+func (cred *WebauthnCredential) GetId() string {
+	return base64.RawURLEncoding.EncodeToString(cred.Id)
+}
+
+// GetAaguid returns the AAGUID of the authenticator as a UUID, or "" if it has none.
+func (cred *WebauthnCredential) GetAaguid() string {
+	aaguid := cred.Authenticator.Aaguid
+	if len(aaguid) != 16 || bytes.Equal(aaguid, make([]byte, 16)) {
+		return ""
+	}
+
+	s := hex.EncodeToString(aaguid)
+	return fmt.Sprintf("%s-%s-%s-%s-%s", s[:8], s[8:12], s[12:16], s[16:20], s[20:])
+}
+
+// GetName returns a name for the credential to be shown to the user, as Casdoor doesn't name
+// them: the model of the authenticator when it is known, like "iCloud Keychain", and otherwise
+// "Passkey" or "Security key" with the start of the credential ID.
+func (cred *WebauthnCredential) GetName() string {
+	if name, ok := knownAaguids[cred.GetAaguid()]; ok {
+		return name
+	}
+
+	kind := "Passkey"
+	if cred.Authenticator.Attachment == "cross-platform" {
+		kind = "Security key"
+	}
+
+	id := cred.GetId()
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	return fmt.Sprintf("%s %s", kind, id)
+}
+
+// ParseWebauthnCredentials parses User.WebauthnCredentials.
+func ParseWebauthnCredentials(raw json.RawMessage) ([]*WebauthnCredential, error) {
+	var credentials []*WebauthnCredential
+	if len(raw) == 0 {
+		return credentials, nil
+	}
+
+	err := json.Unmarshal(raw, &credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+// GetWebauthnCredentials returns the passkeys and security keys of the user.
+func (c *Client) GetWebauthnCredentials(name string) ([]*WebauthnCredential, error) {
+	user, err := c.GetUser(name)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("GetWebauthnCredentials() error: the user %s doesn't exist", name)
+	}
+
+	return ParseWebauthnCredentials(user.WebauthnCredentials)
+}
+
+// DeleteWebauthnCredential removes the credential of the ID, as returned by
+// WebauthnCredential.GetId(), from the user. The other credentials are kept as they are.
+func (c *Client) DeleteWebauthnCredential(name string, credentialId string) (bool, error) {
+	user, err := c.GetUser(name)
+	if err != nil {
+		return false, err
+	}
+	if user == nil {
+		return false, fmt.Errorf("DeleteWebauthnCredential() error: the user %s doesn't exist", name)
+	}
+
+	var rawCredentials []json.RawMessage
+	if len(user.WebauthnCredentials) != 0 {
+		err = json.Unmarshal(user.WebauthnCredentials, &rawCredentials)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	// the credentials are filtered as raw JSON, so that the fields unknown to the SDK are kept
+	var kept []json.RawMessage
+	for _, rawCredential := range rawCredentials {
+		var credential WebauthnCredential
+		err = json.Unmarshal(rawCredential, &credential)
+		if err != nil {
+			return false, err
+		}
+
+		if credential.GetId() != credentialId {
+			kept = append(kept, rawCredential)
+		}
+	}
+	if len(kept) == len(rawCredentials) {
+		return false, nil
+	}
+
+	if kept == nil {
+		kept = []json.RawMessage{}
+	}
+	user.WebauthnCredentials, err = json.Marshal(kept)
+	if err != nil {
+		return false, err
+	}
+
+	return c.UpdateUserForColumns(user, []string{"webauthnCredentials"})
+}
+
+// WebauthnCeremony is a WebAuthn registration or login started with Casdoor, which keeps its
+// challenge in a Casdoor session. It can be kept in the user's session until the finish step.
+type WebauthnCeremony struct {
+	// Options are the {"publicKey": ...} options of navigator.credentials.create() or
+	// navigator.credentials.get() in the browser.
+	Options json.RawMessage `json:"options"`
+	// Session is the cookie of the Casdoor session, sent back by the finish step.
+	Session string `json:"session"`
+}
+
+func (c *Client) beginWebauthnCeremony(action string, queryMap map[string]string) (*WebauthnCeremony, error) {
+	respBytes, session, err := c.doSessionRequest("GET", action, queryMap, "", nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// the options are returned as is, and the errors as usual
+	_, err = parseSessionResponse(respBytes)
+	if err != nil {
+		return nil, err
+	}
+	if session == "" {
+		return nil, errors.New("Casdoor returned no session for the WebAuthn ceremony")
+	}
+
+	return &WebauthnCeremony{Options: respBytes, Session: session}, nil
+}
+
+// BeginWebauthnRegistration starts the registration of a passkey for the user of the client's
+// access token, see WithAccessToken(). The browser creates the credential with the ceremony's
+// Options, which FinishWebauthnRegistration() then sends to Casdoor.
+//
+// The relying party of the passkeys is Casdoor's origin, so the browser must be on a page of
+// Casdoor's domain or one of its subdomains.
+func (c *Client) BeginWebauthnRegistration() (*WebauthnCeremony, error) {
+	return c.beginWebauthnCeremony("webauthn/signup/begin", nil)
+}
+
+// FinishWebauthnRegistration adds the credential created by the browser, the JSON of its
+// PublicKeyCredential, to the user.
+func (c *Client) FinishWebauthnRegistration(ceremony *WebauthnCeremony, credential []byte) error {
+	respBytes, _, err := c.doSessionRequest("POST", "webauthn/signup/finish", nil, "application/json", bytes.NewReader(credential), ceremony.Session)
+	if err != nil {
+		return err
+	}
+
+	_, err = parseSessionResponse(respBytes)
+	return err
+}
+
+// BeginWebauthnLogin starts the sign-in of the user with a passkey, or of any user with a
+// discoverable passkey when name is empty. The browser signs the challenge of the ceremony's
+// Options, which FinishWebauthnLogin() then sends to Casdoor.
+func (c *Client) BeginWebauthnLogin(owner string, name string) (*WebauthnCeremony, error) {
+	queryMap := map[string]string{}
+	if name != "" {
+		queryMap["owner"] = owner
+		queryMap["name"] = name
+	}
+
+	return c.beginWebauthnCeremony("webauthn/signin/begin", queryMap)
+}
+
+// FinishWebauthnLogin signs the user in with the assertion of the browser, the JSON of its
+// PublicKeyCredential, and returns an authorization code of the application, to be exchanged
+// with GetOAuthToken() like the code of the sign-in page.
+func (c *Client) FinishWebauthnLogin(ceremony *WebauthnCeremony, assertion []byte, redirectUri string, state string) (string, error) {
+	queryMap := map[string]string{
+		"responseType": "code",
+		"clientId":     c.ClientId,
+		"redirectUri":  redirectUri,
+		"state":        state,
+	}
+
+	respBytes, _, err := c.doSessionRequest("POST", "webauthn/signin/finish", queryMap, "application/json", bytes.NewReader(assertion), ceremony.Session)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := parseSessionResponse(respBytes)
+	if err != nil {
+		return "", err
+	}
+
+	code, ok := response.Data.(string)
+	if !ok || code == "" {
+		return "", errors.New("Casdoor returned no authorization code for the WebAuthn login")
+	}
+
+	return code, nil
+}
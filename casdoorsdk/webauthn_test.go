@@ -0,0 +1,173 @@
+// Copyright 2026 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package casdoorsdk
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testWebauthnCredentials are two credentials as stored by Casdoor, the first one of iCloud
+// Keychain with a field unknown to the SDK.
+const testWebauthnCredentials = `[
+	{"id":"AQIDBAUGBwg=","publicKey":"pQECAyY=","attestationType":"none","transport":["internal","hybrid"],
+	 "flags":{"userPresent":true,"userVerified":true,"backupEligible":true,"backupState":true},
+	 "authenticator":{"AAGUID":"+/wwBxVOTsyMC24CBVfXvQ==","signCount":0,"cloneWarning":false,"attachment":"platform"},
+	 "attestation":{"clientDataJSON":"e30="}},
+	{"id":"CQoLDA0ODxA=","publicKey":"pQECAyY=","attestationType":"none","transport":["usb"],
+	 "flags":{"userPresent":true},
+	 "authenticator":{"AAGUID":"AAAAAAAAAAAAAAAAAAAAAA==","signCount":42,"cloneWarning":false,"attachment":"cross-platform"}}
+]`
+
+func TestWebauthnCredentials(t *testing.T) {
+	var updatedUser *User
+	var updatedColumns string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/get-user":
+			user := &User{Owner: "built-in", Name: "alice", WebauthnCredentials: json.RawMessage(testWebauthnCredentials)}
+			_ = json.NewEncoder(w).Encode(Response{Status: "ok", Data: user})
+		case "/api/update-user":
+			updatedColumns = r.URL.Query().Get("columns")
+			_ = json.NewDecoder(r.Body).Decode(&updatedUser)
+			_ = json.NewEncoder(w).Encode(Response{Status: "ok", Data: "Affected"})
+		default:
+			t.Errorf("Unexpected request: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "client-id", "client-secret", "", "built-in", "app-built-in")
+
+	credentials, err := client.GetWebauthnCredentials("alice")
+	if err != nil {
+		t.Fatalf("Failed to get the credentials: %v", err)
+	}
+	if len(credentials) != 2 {
+		t.Fatalf("Expected 2 credentials, got %d", len(credentials))
+	}
+
+	passkey := credentials[0]
+	if passkey.GetId() != "AQIDBAUGBwg" || passkey.GetAaguid() != "fbfc3007-154e-4ecc-8c0b-6e020557d7bd" || passkey.GetName() != "iCloud Keychain" {
+		t.Errorf("Unexpected passkey: %s %s %s", passkey.GetId(), passkey.GetAaguid(), passkey.GetName())
+	}
+	if len(passkey.Transports) != 2 || !passkey.Flags.BackupEligible || len(passkey.PublicKey) != 5 {
+		t.Errorf("Unexpected passkey: %+v", passkey)
+	}
+	securityKey := credentials[1]
+	if securityKey.GetAaguid() != "" || securityKey.GetName() != "Security key CQoLDA0O" || securityKey.Authenticator.SignCount != 42 {
+		t.Errorf("Unexpected security key: %s %+v", securityKey.GetName(), securityKey)
+	}
+
+	// The other credentials are kept untouched
+	affected, err := client.DeleteWebauthnCredential("alice", "CQoLDA0ODxA")
+	if err != nil || !affected {
+		t.Fatalf("Failed to delete the credential: %v, %v", affected, err)
+	}
+	if updatedColumns != "webauthnCredentials" || !strings.Contains(string(updatedUser.WebauthnCredentials), `"attestation":{"clientDataJSON":"e30="}`) {
+		t.Errorf("Unexpected update: %s %s", updatedColumns, updatedUser.WebauthnCredentials)
+	}
+	credentials, err = ParseWebauthnCredentials(updatedUser.WebauthnCredentials)
+	if err != nil || len(credentials) != 1 || credentials[0].GetId() != "AQIDBAUGBwg" {
+		t.Errorf("Unexpected credentials after the deletion: %v, %v", credentials, err)
+	}
+
+	updatedUser = nil
+	affected, err = client.DeleteWebauthnCredential("alice", "unknown")
+	if err != nil || affected || updatedUser != nil {
+		t.Errorf("Expected nothing to be deleted, got %v, %v", affected, err)
+	}
+
+	credentials, err = ParseWebauthnCredentials(json.RawMessage("null"))
+	if err != nil || len(credentials) != 0 {
+		t.Errorf("Expected no credentials, got %v, %v", credentials, err)
+	}
+}
+
+func TestWebauthnCeremonies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		session, _ := r.Cookie("casdoor_session_id")
+		switch r.URL.Path {
+		case "/api/webauthn/signup/begin":
+			if r.Header.Get("Authorization") != "Bearer user-token" {
+				_ = json.NewEncoder(w).Encode(Response{Status: "error", Msg: "Please login first"})
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "casdoor_session_id", Value: "registration"})
+			_, _ = w.Write([]byte(`{"publicKey":{"challenge":"cmVnaXN0cmF0aW9u"}}`))
+		case "/api/webauthn/signup/finish":
+			body, _ := io.ReadAll(r.Body)
+			if session == nil || session.Value != "registration" || string(body) != `{"id":"new"}` {
+				_ = json.NewEncoder(w).Encode(Response{Status: "error", Msg: "registration session not found"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(Response{Status: "ok"})
+		case "/api/webauthn/signin/begin":
+			if r.URL.Query().Get("owner") != "built-in" || r.URL.Query().Get("name") != "alice" {
+				t.Errorf("Unexpected user: %s", r.URL.RawQuery)
+			}
+			http.SetCookie(w, &http.Cookie{Name: "casdoor_session_id", Value: "authentication"})
+			_, _ = w.Write([]byte(`{"publicKey":{"challenge":"YXV0aGVudGljYXRpb24"}}`))
+		case "/api/webauthn/signin/finish":
+			query := r.URL.Query()
+			if session == nil || session.Value != "authentication" || query.Get("responseType") != "code" || query.Get("clientId") != "client-id" {
+				_ = json.NewEncoder(w).Encode(Response{Status: "error", Msg: "authentication session not found"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(Response{Status: "ok", Data: "auth-code"})
+		default:
+			t.Errorf("Unexpected request: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "client-id", "client-secret", "", "built-in", "app-built-in")
+
+	// The registration needs the user's access token
+	_, err := client.BeginWebauthnRegistration()
+	if err == nil || err.Error() != "Please login first" {
+		t.Errorf("Expected an error without the user's token, got %v", err)
+	}
+
+	ceremony, err := client.WithAccessToken("user-token").BeginWebauthnRegistration()
+	if err != nil {
+		t.Fatalf("Failed to begin the registration: %v", err)
+	}
+	if string(ceremony.Options) != `{"publicKey":{"challenge":"cmVnaXN0cmF0aW9u"}}` || ceremony.Session != "casdoor_session_id=registration" {
+		t.Errorf("Unexpected ceremony: %s %s", ceremony.Options, ceremony.Session)
+	}
+	err = client.WithAccessToken("user-token").FinishWebauthnRegistration(ceremony, []byte(`{"id":"new"}`))
+	if err != nil {
+		t.Errorf("Failed to finish the registration: %v", err)
+	}
+
+	ceremony, err = client.BeginWebauthnLogin("built-in", "alice")
+	if err != nil {
+		t.Fatalf("Failed to begin the login: %v", err)
+	}
+	code, err := client.FinishWebauthnLogin(ceremony, []byte(`{"id":"new"}`), "https://app.example.com/callback", "state")
+	if err != nil || code != "auth-code" {
+		t.Errorf("Expected the authorization code, got %q, %v", code, err)
+	}
+
+	_, err = client.FinishWebauthnLogin(&WebauthnCeremony{}, []byte(`{"id":"new"}`), "https://app.example.com/callback", "state")
+	if err == nil {
+		t.Errorf("Expected an error without the ceremony's session")
+	}
+}
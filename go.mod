@@ -1,6 +1,6 @@
 module github.com/casdoor/casdoor-go-sdk
 
-go 1.23
+go 1.23.0
 
 require (
 	github.com/golang-jwt/jwt/v4 v4.5.0
@@ -9,6 +9,7 @@ require (
 
 require (
 	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/google/go-cmp v0.7.0 // indirect
 	golang.org/x/net v0.16.0 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
 	google.golang.org/protobuf v1.31.0 // indirect